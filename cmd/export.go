@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/lavatee/subs/internal/service"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+)
+
+func exportCommand(logger *logrus.Logger) *cli.Command {
+	return &cli.Command{
+		Name:  "export",
+		Usage: "write subscriptions to a CSV, NDJSON or XLSX file",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "output", Aliases: []string{"o"}, Usage: "file to write, - for stdout", Value: "-"},
+			&cli.StringFlag{Name: "format", Usage: "csv, ndjson or xlsx", Value: service.ExportCSV},
+			&cli.StringFlag{Name: "user", Usage: "export only the subscriptions of this user"},
+			&cli.StringFlag{Name: "service", Usage: "export only subscriptions to this service"},
+			&cli.BoolFlag{Name: "include-archived", Usage: "export archived subscriptions too"},
+		},
+		Action: func(c *cli.Context) error {
+			userID, err := parseUserFlag(c, "user")
+			if err != nil {
+				return err
+			}
+			if _, err := service.ExportContentType(c.String("format")); err != nil {
+				return err
+			}
+			return withServices(logger, func(ctx context.Context, services *service.Service) error {
+				export := func(w io.Writer) error {
+					return services.Export.ExportSubscriptions(ctx, w, c.String("format"), userID, c.String("service"), c.Bool("include-archived"))
+				}
+				path := c.String("output")
+				if path == "-" {
+					return export(c.App.Writer)
+				}
+				file, err := os.Create(path)
+				if err != nil {
+					return err
+				}
+				// A failed export must not leave a truncated file behind.
+				if err := export(file); err != nil {
+					file.Close()
+					os.Remove(path)
+					return err
+				}
+				if err := file.Close(); err != nil {
+					os.Remove(path)
+					return err
+				}
+				return nil
+			})
+		},
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/lavatee/subs/internal/model"
+	"github.com/lavatee/subs/internal/service"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+)
+
+func importCommand(logger *logrus.Logger) *cli.Command {
+	return &cli.Command{
+		Name:  "import",
+		Usage: "create subscriptions from a CSV or NDJSON file",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "file", Aliases: []string{"f"}, Usage: "file to read, - for stdin", Required: true},
+			&cli.StringFlag{Name: "format", Usage: "csv or ndjson", Value: service.ImportCSV},
+			&cli.BoolFlag{Name: "dry-run", Usage: "validate every row without writing anything"},
+			&cli.StringFlag{Name: "mapping", Usage: `columns of the fields, as "field=column,field=column"`},
+		},
+		Action: func(c *cli.Context) error {
+			mapping, err := service.ParseImportMapping(c.String("mapping"))
+			if err != nil {
+				return err
+			}
+			var r io.Reader = os.Stdin
+			if path := c.String("file"); path != "-" {
+				file, err := os.Open(path)
+				if err != nil {
+					return err
+				}
+				defer file.Close()
+				r = file
+			}
+			return withServices(logger, func(ctx context.Context, services *service.Service) error {
+				report, err := services.Import.ImportSubscriptions(ctx, r, service.ImportOptions{
+					Format:  c.String("format"),
+					DryRun:  c.Bool("dry-run"),
+					Mapping: mapping,
+				})
+				if err != nil {
+					return err
+				}
+				printImportReport(c.App.Writer, report)
+				return nil
+			})
+		},
+	}
+}
+
+func printImportReport(w io.Writer, report model.ImportReport) {
+	for _, rowErr := range report.Errors {
+		fmt.Fprintf(w, "row %d: %s\n", rowErr.Row, rowErr.Error)
+	}
+	for _, warning := range report.Warnings {
+		fmt.Fprintf(w, "warning: %s\n", warning.Message)
+	}
+	if report.DryRun {
+		fmt.Fprintf(w, "%d rows, %d valid (dry run, nothing imported)\n", report.Total, report.Valid)
+		return
+	}
+	fmt.Fprintf(w, "%d rows, %d valid, %d imported\n", report.Total, report.Valid, report.Imported)
+}
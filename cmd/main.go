@@ -1,22 +1,18 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"os"
-	"os/signal"
-	"path/filepath"
-	"runtime"
-	"syscall"
 
-	"github.com/golang-migrate/migrate/v4"
-	"github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
-	"github.com/lavatee/subs"
-	"github.com/lavatee/subs/internal/endpoint"
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/logging"
 	"github.com/lavatee/subs/internal/repository"
 	"github.com/lavatee/subs/internal/service"
 	_ "github.com/lib/pq"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
+	"github.com/urfave/cli/v2"
 )
 
 // @title Subscription Service API
@@ -27,52 +23,105 @@ import (
 
 func main() {
 	logger := logrus.New()
-	logger.SetFormatter(&logrus.JSONFormatter{PrettyPrint: true})
-	if err := InitConfig(); err != nil {
-		logger.Fatalf("Failed to init config: %s", err.Error())
+	logger.SetFormatter(&logrus.JSONFormatter{})
+	app := &cli.App{
+		Name:  "subs",
+		Usage: "subscription aggregation service",
+		Before: func(c *cli.Context) error {
+			if err := InitConfig(); err != nil {
+				return fmt.Errorf("failed to init config: %w", err)
+			}
+			var logConfig logging.Config
+			if err := viper.UnmarshalKey("log", &logConfig); err != nil {
+				return fmt.Errorf("invalid log config: %w", err)
+			}
+			if err := logging.Configure(logger, logConfig); err != nil {
+				return fmt.Errorf("invalid log config: %w", err)
+			}
+			return nil
+		},
+		Commands: []*cli.Command{
+			serveCommand(logger),
+			migrateCommand(logger),
+			seedCommand(logger),
+			importCommand(logger),
+			exportCommand(logger),
+			reportCommand(logger),
+		},
+		// Without a command the binary serves, as it always has.
+		Action: func(c *cli.Context) error {
+			if c.Args().Present() {
+				return fmt.Errorf("unknown command %q", c.Args().First())
+			}
+			return serve(logger)
+		},
 	}
-	db, err := repository.NewPostgresDB(repository.PostgresConfig{
-		Host:     viper.GetString("db.host"),
-		Port:     viper.GetString("db.port"),
-		User:     viper.GetString("db.user"),
-		Password: viper.GetString("db.password"),
-		DBName:   viper.GetString("db.dbname"),
-		SSLMode:  viper.GetString("db.sslmode"),
-	})
+	if err := app.Run(os.Args); err != nil {
+		logger.Fatalf("%s", err.Error())
+	}
+}
+
+// openServices connects to the database and builds the service layer on it.
+func openServices(logger *logrus.Logger, replicas ...repository.PostgresConfig) (*repository.Cluster, *service.Service, error) {
+	// Readiness waits for the schema the binary was built for, so an instance
+	// started before "migrate up" receives no traffic.
+	migrationVersion, err := repository.LatestMigration()
 	if err != nil {
-		logger.Fatalf("Failed to open Postgres DB: %s", err.Error())
+		return nil, nil, err
 	}
-	driver, err := postgres.WithInstance(db.DB, &postgres.Config{})
+	isolation, err := repository.ParseIsolationLevel(viper.GetString("db.tx.isolation"))
 	if err != nil {
-		logger.Fatalf("Failed to create migrate driver: %s", err.Error())
+		return nil, nil, fmt.Errorf("invalid transaction config: %w", err)
 	}
-	_, filename, _, _ := runtime.Caller(0)
-	dir := filepath.Dir(filename)
-	migrationsPath := "file://" + filepath.Join(dir, "../schema")
-	migrations, err := migrate.NewWithDatabaseInstance(migrationsPath, "postgres", driver)
+	db, err := repository.NewPostgresDB(primaryConfig(), replicas...)
 	if err != nil {
-		logger.Fatalf("Failed to create migrate instance: %s", err.Error())
+		return nil, nil, fmt.Errorf("failed to open Postgres DB: %w", err)
 	}
-	if err = migrations.Up(); err != nil && err != migrate.ErrNoChange {
-		logger.Fatalf("Migrations error: %s", err.Error())
+	repo := repository.NewRepository(db, repository.TxOptions{
+		Isolation:  isolation,
+		MaxRetries: viper.GetInt("db.tx.max_retries"),
+	})
+	services := service.NewService(repo, logger, service.Config{
+		ChangesTokenTTL:      viper.GetDuration("changes.token_ttl"),
+		CalendarHorizon:      viper.GetInt("calendar.horizon_months"),
+		MigrationVersion:     migrationVersion,
+		WebhooksAllowPrivate: viper.GetBool("webhooks.allow_private_networks"),
+	})
+	return db, services, nil
+}
+
+// withServices runs fn with the service layer of a short-lived command and
+// closes the database afterwards.
+func withServices(logger *logrus.Logger, fn func(ctx context.Context, services *service.Service) error) error {
+	db, services, err := openServices(logger)
+	if err != nil {
+		return err
 	}
-	repo := repository.NewRepository(db)
-	services := service.NewService(repo, logger)
-	endp := endpoint.NewEndpoint(services, logger)
-	server := &subs.Server{}
-	go func() {
-		if err := server.Run(viper.GetString("port"), endp.InitRoutes()); err != nil {
-			logger.Fatalf("Failed to run server: %s", err.Error())
-		}
-	}()
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGTERM, syscall.SIGINT)
-	<-quit
-	if err := server.Shutdown(); err != nil {
-		logger.Fatalf("Failed to shutdown server: %s", err.Error())
+	defer db.Close()
+	return fn(context.Background(), services)
+}
+
+// parseUserFlag parses an optional user ID flag; empty means all users.
+func parseUserFlag(c *cli.Context, name string) (uuid.UUID, error) {
+	value := c.String(name)
+	if value == "" {
+		return uuid.Nil, nil
 	}
-	if err := db.Close(); err != nil {
-		logger.Fatalf("Failed to disconnect Postgres DB: %s", err.Error())
+	userID, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid --%s %q", name, value)
+	}
+	return userID, nil
+}
+
+func primaryConfig() repository.PostgresConfig {
+	return repository.PostgresConfig{
+		Host:     viper.GetString("db.host"),
+		Port:     viper.GetString("db.port"),
+		User:     viper.GetString("db.user"),
+		Password: viper.GetString("db.password"),
+		DBName:   viper.GetString("db.dbname"),
+		SSLMode:  viper.GetString("db.sslmode"),
 	}
 }
 
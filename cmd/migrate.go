@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/lavatee/subs/internal/repository"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+)
+
+// migrateLogger prints the migrator's progress through logrus.
+type migrateLogger struct {
+	logger *logrus.Logger
+}
+
+func (l migrateLogger) Printf(format string, v ...interface{}) {
+	l.logger.Infof(format, v...)
+}
+
+func (l migrateLogger) Verbose() bool {
+	return l.logger.IsLevelEnabled(logrus.DebugLevel)
+}
+
+func migrateCommand(logger *logrus.Logger) *cli.Command {
+	return &cli.Command{
+		Name:  "migrate",
+		Usage: "apply or roll back the embedded schema migrations",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "up",
+				Usage: "apply all pending migrations",
+				Action: func(c *cli.Context) error {
+					return runMigrate(logger, func(m *migrate.Migrate) error { return ignoreNoChange(m.Up()) })
+				},
+			},
+			{
+				Name:      "down",
+				Usage:     "roll back the last N migrations (default 1) or all of them",
+				ArgsUsage: "[N|all]",
+				Action: func(c *cli.Context) error {
+					switch arg := c.Args().First(); {
+					case c.NArg() > 1:
+						return fmt.Errorf("unexpected arguments %q", strings.Join(c.Args().Slice(), " "))
+					case arg == "":
+						return runMigrate(logger, func(m *migrate.Migrate) error { return ignoreNoChange(m.Steps(-1)) })
+					case arg == "all":
+						return runMigrate(logger, func(m *migrate.Migrate) error { return ignoreNoChange(m.Down()) })
+					default:
+						steps, err := strconv.Atoi(arg)
+						if err != nil || steps < 1 {
+							return fmt.Errorf("invalid number of migrations %q", arg)
+						}
+						return runMigrate(logger, func(m *migrate.Migrate) error { return ignoreNoChange(m.Steps(-steps)) })
+					}
+				},
+			},
+			{
+				Name:      "goto",
+				Usage:     "migrate up or down to VERSION",
+				ArgsUsage: "VERSION",
+				Action: func(c *cli.Context) error {
+					version, err := strconv.ParseUint(c.Args().First(), 10, 64)
+					if err != nil || c.NArg() != 1 {
+						return fmt.Errorf("invalid version %q", strings.Join(c.Args().Slice(), " "))
+					}
+					return runMigrate(logger, func(m *migrate.Migrate) error { return ignoreNoChange(m.Migrate(uint(version))) })
+				},
+			},
+			{
+				Name:  "status",
+				Usage: "print the applied and the latest version",
+				Action: func(c *cli.Context) error {
+					return runMigrate(logger, func(m *migrate.Migrate) error { return printMigrationStatus(logger, m) })
+				},
+			},
+			{
+				Name: "force",
+				Usage: "record VERSION as applied and clear the dirty flag, " +
+					"after fixing a failed migration by hand; -1 means none",
+				ArgsUsage: "VERSION",
+				// -1 must not be taken for a flag.
+				SkipFlagParsing: true,
+				Action: func(c *cli.Context) error {
+					version, err := strconv.Atoi(c.Args().First())
+					if err != nil || version < -1 || c.NArg() != 1 {
+						return fmt.Errorf("invalid version %q", strings.Join(c.Args().Slice(), " "))
+					}
+					return runMigrate(logger, func(m *migrate.Migrate) error { return m.Force(version) })
+				},
+			},
+		},
+	}
+}
+
+// runMigrate runs fn against the primary database. Arguments are checked by
+// the caller, so an invalid command fails before anything is opened.
+func runMigrate(logger *logrus.Logger, fn func(migrator *migrate.Migrate) error) error {
+	db, err := repository.NewPostgresDB(primaryConfig())
+	if err != nil {
+		return fmt.Errorf("failed to open Postgres DB: %w", err)
+	}
+	defer db.Close()
+	migrator, err := repository.NewMigrator(context.Background(), db.Primary)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+	migrator.Log = migrateLogger{logger: logger}
+	return fn(migrator)
+}
+
+func printMigrationStatus(logger *logrus.Logger, migrator *migrate.Migrate) error {
+	latest, err := repository.LatestMigration()
+	if err != nil {
+		return err
+	}
+	version, dirty, err := migrator.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		logger.WithField("latest", latest).Infof("No migrations applied")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	logger.WithFields(logrus.Fields{
+		"version": version,
+		"latest":  latest,
+		"dirty":   dirty,
+		"pending": version < latest,
+	}).Infof("Schema is at version %d of %d", version, latest)
+	return nil
+}
+
+func ignoreNoChange(err error) error {
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+	return err
+}
+
+// migrateUp applies pending migrations before serving.
+func migrateUp(logger *logrus.Logger, db *repository.Cluster) error {
+	migrator, err := repository.NewMigrator(context.Background(), db.Primary)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+	migrator.Log = migrateLogger{logger: logger}
+	return ignoreNoChange(migrator.Up())
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/service"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+)
+
+const monthLayout = "01-2006"
+
+func reportCommand(logger *logrus.Logger) *cli.Command {
+	return &cli.Command{
+		Name:  "report",
+		Usage: "print the total cost of subscriptions for a user or a period",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "user", Usage: "count only the subscriptions of this user"},
+			&cli.StringFlag{Name: "service", Usage: "count only subscriptions to this service"},
+			&cli.StringFlag{Name: "from", Usage: "first month of the period, MM-YYYY"},
+			&cli.StringFlag{Name: "to", Usage: "last month of the period, MM-YYYY"},
+			&cli.BoolFlag{Name: "include-archived", Usage: "count archived subscriptions too"},
+			&cli.BoolFlag{Name: "monthly", Usage: "also print the spend of every month"},
+		},
+		Action: func(c *cli.Context) error {
+			userID, err := parseUserFlag(c, "user")
+			if err != nil {
+				return err
+			}
+			from, err := parseMonthFlag(c, "from")
+			if err != nil {
+				return err
+			}
+			to, err := parseMonthFlag(c, "to")
+			if err != nil {
+				return err
+			}
+			if !from.IsZero() && !to.IsZero() && from.After(to) {
+				return fmt.Errorf("--from %s is after --to %s", c.String("from"), c.String("to"))
+			}
+			// The monthly breakdown has a row per month, so it needs both ends.
+			if c.Bool("monthly") && (from.IsZero() || to.IsZero()) {
+				return errors.New("--monthly requires --from and --to")
+			}
+			return withServices(logger, func(ctx context.Context, services *service.Service) error {
+				return printReport(ctx, c.App.Writer, services, userID, c.String("service"), from, to, c.Bool("include-archived"), c.Bool("monthly"))
+			})
+		},
+	}
+}
+
+// printReport prints the subscriptions the total is made of, each adding its
+// price once however many months it was active, then the total and, if
+// monthly is set, the spend per month.
+func printReport(ctx context.Context, w io.Writer, services *service.Service, userID uuid.UUID, serviceName string, from, to time.Time, includeArchived, monthly bool) error {
+	total, explanation, err := services.Subscriptions.ExplainTotalCost(ctx, userID, serviceName, from, to, includeArchived)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SERVICE\tUSER\tFROM\tTO\tACTIVE MONTHS\tPRICE")
+	for _, item := range explanation.Items {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%d\n",
+			item.Subscription.ServiceName,
+			item.Subscription.UserID,
+			item.FirstMonth.Format(monthLayout),
+			item.LastMonth.Format(monthLayout),
+			item.ActiveMonths,
+			item.Subtotal,
+		)
+	}
+	fmt.Fprintf(tw, "TOTAL\t\t\t\t\t%d\n", total)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if explanation.Mismatch {
+		fmt.Fprintf(w, "warning: the subscriptions add up to %d, the monthly spend ledger to %d\n", explanation.ItemsTotal, total)
+	}
+	if !monthly {
+		return nil
+	}
+
+	spend, err := services.MonthlySpend.GetMonthlySpend(ctx, userID, serviceName, from, to)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "MONTH\tAMOUNT")
+	for _, month := range spend {
+		fmt.Fprintf(tw, "%s\t%d\n", month.Month.Format(monthLayout), month.Amount)
+	}
+	return tw.Flush()
+}
+
+// parseMonthFlag parses an optional MM-YYYY flag; empty means unbounded.
+func parseMonthFlag(c *cli.Context, name string) (time.Time, error) {
+	value := c.String(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	month, err := time.Parse(monthLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --%s %q, expected MM-YYYY", name, value)
+	}
+	return month, nil
+}
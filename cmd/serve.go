@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/lavatee/subs"
+	"github.com/lavatee/subs/internal/endpoint"
+	"github.com/lavatee/subs/internal/metrics"
+	"github.com/lavatee/subs/internal/repository"
+	"github.com/lavatee/subs/internal/service"
+	"github.com/lavatee/subs/internal/tracing"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"github.com/urfave/cli/v2"
+)
+
+func serveCommand(logger *logrus.Logger) *cli.Command {
+	return &cli.Command{
+		Name:  "serve",
+		Usage: "serve the HTTP API and run the background workers",
+		Action: func(c *cli.Context) error {
+			return serve(logger)
+		},
+	}
+}
+
+// serve runs the API until SIGTERM or SIGINT, or until a server fails, then
+// drains it.
+func serve(logger *logrus.Logger) error {
+	var tracingConfig tracing.Config
+	if err := viper.UnmarshalKey("tracing", &tracingConfig); err != nil {
+		return fmt.Errorf("invalid tracing config: %w", err)
+	}
+	shutdownTracing, err := tracing.Init(context.Background(), tracingConfig)
+	if err != nil {
+		return fmt.Errorf("failed to init tracing: %w", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), viper.GetDuration("shutdown.timeout"))
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Errorf("Failed to flush traces: %s", err.Error())
+		}
+	}()
+	var replicas []repository.PostgresConfig
+	if err := viper.UnmarshalKey("db.replicas", &replicas); err != nil {
+		return fmt.Errorf("invalid replicas config: %w", err)
+	}
+	db, services, err := openServices(logger, replicas...)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			logger.Errorf("Failed to disconnect Postgres DB: %s", err.Error())
+		}
+	}()
+	if viper.GetBool("db.migrate_on_start") {
+		if err := migrateUp(logger, db); err != nil {
+			return fmt.Errorf("migrations error: %w", err)
+		}
+	}
+	if err := db.RegisterMetrics(metrics.Registry); err != nil {
+		return fmt.Errorf("failed to register DB metrics: %w", err)
+	}
+	var sinkConfigs []service.SinkConfig
+	if err := viper.UnmarshalKey("outbox.sinks", &sinkConfigs); err != nil {
+		return fmt.Errorf("invalid outbox sinks config: %w", err)
+	}
+	sinks := make([]service.EventSink, 0, len(sinkConfigs))
+	for _, config := range sinkConfigs {
+		sink, err := service.NewEventSink(config)
+		if err != nil {
+			return fmt.Errorf("failed to create outbox sink: %w", err)
+		}
+		sinks = append(sinks, sink)
+	}
+	adminPort := viper.GetString("metrics.admin_port")
+	endp := endpoint.NewEndpoint(services, logger, endpoint.Config{
+		ServeMetrics: adminPort == "",
+	})
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	if interval := viper.GetDuration("db.replica_health_interval"); interval > 0 {
+		services.Health.RunWorker("replica-health", func() { db.RunHealthChecks(workersCtx, interval) })
+	}
+	if interval := viper.GetDuration("monthly_spend.rebuild_interval"); interval > 0 {
+		services.Health.RunWorker("monthly-spend", func() { services.MonthlySpend.RunRebuilder(workersCtx, interval) })
+	}
+	if interval := viper.GetDuration("archive.interval"); interval > 0 {
+		services.Health.RunWorker("archiver", func() { services.Archive.RunArchiver(workersCtx, interval, viper.GetDuration("archive.horizon")) })
+	}
+	if interval := viper.GetDuration("webhooks.dispatch_interval"); interval > 0 {
+		services.Health.RunWorker("webhook-dispatcher", func() { services.Webhooks.RunDispatcher(workersCtx, interval) })
+	}
+	if interval := viper.GetDuration("webhooks.scan_interval"); interval > 0 {
+		services.Health.RunWorker("webhook-scanner", func() {
+			services.Webhooks.RunLifecycleScanner(workersCtx, interval, viper.GetDuration("webhooks.ending_soon_lead"))
+		})
+	}
+	if interval := viper.GetDuration("metrics.refresh_interval"); interval > 0 {
+		services.Health.RunWorker("metrics", func() { services.Metrics.RunCollector(workersCtx, interval) })
+	}
+	if interval := viper.GetDuration("outbox.dispatch_interval"); interval > 0 {
+		services.Health.RunWorker("outbox-dispatcher", func() {
+			services.Outbox.RunDispatcher(workersCtx, interval, viper.GetDuration("outbox.retention"), sinks)
+		})
+	}
+	if interval := viper.GetDuration("events.poll_interval"); interval > 0 {
+		services.Health.RunWorker("event-hub", func() { services.Events.RunHub(workersCtx, interval, viper.GetInt("events.buffer_size")) })
+	}
+	notifiers := []service.Notifier{service.NewWebhookNotifier(viper.GetString("reminders.webhook_secret"), viper.GetBool("webhooks.allow_private_networks"))}
+	if viper.GetString("reminders.smtp.host") != "" {
+		notifiers = append(notifiers, service.NewSMTPNotifier(service.SMTPConfig{
+			Host:     viper.GetString("reminders.smtp.host"),
+			Port:     viper.GetInt("reminders.smtp.port"),
+			Username: viper.GetString("reminders.smtp.username"),
+			Password: viper.GetString("reminders.smtp.password"),
+			From:     viper.GetString("reminders.smtp.from"),
+		}))
+	}
+	if interval := viper.GetDuration("reminders.scan_interval"); interval > 0 {
+		services.Health.RunWorker("reminders", func() {
+			services.Reminders.RunScheduler(workersCtx, interval, viper.GetIntSlice("reminders.lead_days"), notifiers)
+		})
+	}
+	// A server that fails to run, e.g. because its port is taken, shuts the
+	// others down like a signal would and its error is returned.
+	serverErrs := make(chan error, 2)
+	server := subs.NewServer(viper.GetString("port"), endp.InitRoutes())
+	go func() {
+		if err := server.Run(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErrs <- fmt.Errorf("failed to run server: %w", err)
+		}
+	}()
+	var adminServer *subs.Server
+	if adminPort != "" {
+		adminServer = subs.NewServer(adminPort, endp.InitAdminRoutes())
+		go func() {
+			if err := adminServer.Run(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				serverErrs <- fmt.Errorf("failed to run admin server: %w", err)
+			}
+		}()
+	}
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGTERM, syscall.SIGINT)
+	var runErr error
+	select {
+	case <-quit:
+	case runErr = <-serverErrs:
+	}
+
+	// Readiness fails first, so that load balancers stop routing to this
+	// instance while it still serves; only then does the server drain.
+	services.Health.StartShutdown()
+	logger.Infof("Shutting down")
+	time.Sleep(viper.GetDuration("shutdown.readiness_delay"))
+	ctx, cancel := context.WithTimeout(context.Background(), viper.GetDuration("shutdown.timeout"))
+	defer cancel()
+	// Stopping the workers also ends live event streams, which would
+	// otherwise hold the drain until the timeout.
+	stopWorkers()
+	if err := server.Shutdown(ctx); err != nil {
+		logger.Errorf("Failed to drain server: %s", err.Error())
+	}
+	if err := services.Health.WaitWorkers(ctx); err != nil {
+		logger.Errorf("Failed to wait for workers: %s", err.Error())
+	}
+	if adminServer != nil {
+		if err := adminServer.Shutdown(ctx); err != nil {
+			logger.Errorf("Failed to shutdown admin server: %s", err.Error())
+		}
+	}
+	return runErr
+}
@@ -0,0 +1,221 @@
+package endpoint
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lavatee/subs/internal/logging"
+	"github.com/lavatee/subs/internal/model"
+)
+
+// @Summary Топ сервисов по тратам
+// @Description Сервисы с наибольшей суммарной стоимостью подписок. Стоимость считается так же, как в /subscriptions/total
+// @Tags analytics
+// @Accept json
+// @Produce json
+// @Param user_id query string false "Фильтрация по ID пользователя"
+// @Param service_name query string false "Фильтрация по названию сервиса"
+// @Param start_date query string false "Начальная дата (MM-YYYY)"
+// @Param end_date query string false "Конечная дата (MM-YYYY)"
+// @Param include_archived query bool false "Включить архивные подписки"
+// @Param limit query int false "Количество сервисов, по умолчанию 10, не больше 100"
+// @Success 200 {object} model.TopServicesResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /analytics/top-services [get]
+func (e *Endpoint) GetTopServices(ctx *gin.Context) {
+	filter, ok := e.analyticsFilter(ctx)
+	if !ok {
+		return
+	}
+	limit := 0
+	if value := ctx.Query("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil {
+			logging.FromContext(ctx, e.logger).Warnf("Invalid limit value: %s", err.Error())
+			ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error: "Invalid limit value, expected a number",
+			})
+			return
+		}
+	}
+
+	services, err := e.services.Analytics.GetTopServices(ctx, filter, limit)
+	if err != nil {
+		logging.FromContext(ctx, e.logger).Errorf("Failed to get top services: %s", err.Error())
+		ctx.JSON(errorStatus(err), model.ErrorResponse{
+			Error: fmt.Sprintf("Failed to get top services: %s", err.Error()),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.TopServicesResponse{
+		Services: services,
+	})
+}
+
+// @Summary Средняя цена по сервисам
+// @Description Средняя, минимальная и максимальная цена подписки на каждый сервис
+// @Tags analytics
+// @Accept json
+// @Produce json
+// @Param user_id query string false "Фильтрация по ID пользователя"
+// @Param service_name query string false "Фильтрация по названию сервиса"
+// @Param start_date query string false "Начальная дата (MM-YYYY)"
+// @Param end_date query string false "Конечная дата (MM-YYYY)"
+// @Param include_archived query bool false "Включить архивные подписки"
+// @Success 200 {object} model.AveragePricesResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /analytics/average-prices [get]
+func (e *Endpoint) GetAveragePrices(ctx *gin.Context) {
+	filter, ok := e.analyticsFilter(ctx)
+	if !ok {
+		return
+	}
+
+	prices, err := e.services.Analytics.GetAveragePrices(ctx, filter)
+	if err != nil {
+		logging.FromContext(ctx, e.logger).Errorf("Failed to get average prices: %s", err.Error())
+		ctx.JSON(errorStatus(err), model.ErrorResponse{
+			Error: fmt.Sprintf("Failed to get average prices: %s", err.Error()),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.AveragePricesResponse{
+		Services: prices,
+	})
+}
+
+// @Summary Активные подписки по месяцам
+// @Description Количество активных подписок в каждом месяце периода. По умолчанию последние 12 месяцев
+// @Tags analytics
+// @Accept json
+// @Produce json
+// @Param user_id query string false "Фильтрация по ID пользователя"
+// @Param service_name query string false "Фильтрация по названию сервиса"
+// @Param start_date query string false "Начальная дата (MM-YYYY)"
+// @Param end_date query string false "Конечная дата (MM-YYYY)"
+// @Param include_archived query bool false "Включить архивные подписки"
+// @Success 200 {object} model.ActiveSubscriptionsResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /analytics/active [get]
+func (e *Endpoint) GetActiveSubscriptions(ctx *gin.Context) {
+	filter, ok := e.analyticsFilter(ctx)
+	if !ok {
+		return
+	}
+
+	active, err := e.services.Analytics.GetActiveSubscriptions(ctx, filter)
+	if err != nil {
+		logging.FromContext(ctx, e.logger).Errorf("Failed to get active subscriptions: %s", err.Error())
+		ctx.JSON(errorStatus(err), model.ErrorResponse{
+			Error: fmt.Sprintf("Failed to get active subscriptions: %s", err.Error()),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.ActiveSubscriptionsResponse{
+		Months: active,
+	})
+}
+
+// @Summary Новые и отмененные подписки по месяцам
+// @Description Количество подписок, начавшихся и закончившихся в каждом месяце периода. По умолчанию последние 12 месяцев
+// @Tags analytics
+// @Accept json
+// @Produce json
+// @Param user_id query string false "Фильтрация по ID пользователя"
+// @Param service_name query string false "Фильтрация по названию сервиса"
+// @Param start_date query string false "Начальная дата (MM-YYYY)"
+// @Param end_date query string false "Конечная дата (MM-YYYY)"
+// @Param include_archived query bool false "Включить архивные подписки"
+// @Success 200 {object} model.SubscriptionChurnResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /analytics/churn [get]
+func (e *Endpoint) GetChurn(ctx *gin.Context) {
+	filter, ok := e.analyticsFilter(ctx)
+	if !ok {
+		return
+	}
+
+	churn, err := e.services.Analytics.GetChurn(ctx, filter)
+	if err != nil {
+		logging.FromContext(ctx, e.logger).Errorf("Failed to get churn: %s", err.Error())
+		ctx.JSON(errorStatus(err), model.ErrorResponse{
+			Error: fmt.Sprintf("Failed to get churn: %s", err.Error()),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.SubscriptionChurnResponse{
+		Months: churn,
+	})
+}
+
+// @Summary Траты пользователей
+// @Description Медиана и среднее суммарной стоимости подписок на пользователя
+// @Tags analytics
+// @Accept json
+// @Produce json
+// @Param user_id query string false "Фильтрация по ID пользователя"
+// @Param service_name query string false "Фильтрация по названию сервиса"
+// @Param start_date query string false "Начальная дата (MM-YYYY)"
+// @Param end_date query string false "Конечная дата (MM-YYYY)"
+// @Param include_archived query bool false "Включить архивные подписки"
+// @Success 200 {object} model.UserSpendStatsResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /analytics/user-spend [get]
+func (e *Endpoint) GetUserSpendStats(ctx *gin.Context) {
+	filter, ok := e.analyticsFilter(ctx)
+	if !ok {
+		return
+	}
+
+	stats, err := e.services.Analytics.GetUserSpendStats(ctx, filter)
+	if err != nil {
+		logging.FromContext(ctx, e.logger).Errorf("Failed to get user spend stats: %s", err.Error())
+		ctx.JSON(errorStatus(err), model.ErrorResponse{
+			Error: fmt.Sprintf("Failed to get user spend stats: %s", err.Error()),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.UserSpendStatsResponse{
+		Stats: stats,
+	})
+}
+
+// analyticsFilter parses the filters shared with /subscriptions/total. On
+// failure it writes a 400 response and returns false.
+func (e *Endpoint) analyticsFilter(ctx *gin.Context) (model.AnalyticsFilter, bool) {
+	userID, ok := e.queryUserID(ctx)
+	if !ok {
+		return model.AnalyticsFilter{}, false
+	}
+	startDate, ok := e.queryMonth(ctx, "start_date")
+	if !ok {
+		return model.AnalyticsFilter{}, false
+	}
+	endDate, ok := e.queryMonth(ctx, "end_date")
+	if !ok {
+		return model.AnalyticsFilter{}, false
+	}
+	includeArchived, ok := e.queryBool(ctx, "include_archived")
+	if !ok {
+		return model.AnalyticsFilter{}, false
+	}
+	return model.AnalyticsFilter{
+		UserID:          userID,
+		ServiceName:     ctx.Query("service_name"),
+		StartDate:       startDate,
+		EndDate:         endDate,
+		IncludeArchived: includeArchived,
+	}, true
+}
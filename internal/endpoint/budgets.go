@@ -0,0 +1,214 @@
+package endpoint
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lavatee/subs/internal/logging"
+	"github.com/lavatee/subs/internal/model"
+)
+
+// @Summary Создание бюджета
+// @Description Создание месячного или годового лимита трат пользователя, на все сервисы или на один
+// @Tags budgets
+// @Accept json
+// @Produce json
+// @Param budget body model.CreateBudgetRequest true "Данные о бюджете"
+// @Success 201 {object} model.BudgetResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /budgets [post]
+func (e *Endpoint) CreateBudget(ctx *gin.Context) {
+	var req model.CreateBudgetRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		logging.FromContext(ctx, e.logger).Warnf("Invalid request body: %s", err.Error())
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: "Invalid request body",
+		})
+		return
+	}
+
+	budget, err := e.services.Budgets.CreateBudget(ctx, req)
+	if err != nil {
+		logging.FromContext(ctx, e.logger).Errorf("Failed to create budget: %s", err.Error())
+		ctx.JSON(errorStatus(err), model.ErrorResponse{
+			Error: fmt.Sprintf("Failed to create budget: %s", err.Error()),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, model.BudgetResponse{
+		Budget: budget,
+	})
+}
+
+// @Summary Получение бюджетов
+// @Description Получение бюджетов с фильтрацией по пользователю
+// @Tags budgets
+// @Accept json
+// @Produce json
+// @Param user_id query string false "ID пользователя"
+// @Success 200 {object} model.BudgetListResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /budgets [get]
+func (e *Endpoint) GetBudgets(ctx *gin.Context) {
+	userID, ok := e.queryUserID(ctx)
+	if !ok {
+		return
+	}
+
+	budgets, err := e.services.Budgets.GetBudgets(ctx, userID)
+	if err != nil {
+		logging.FromContext(ctx, e.logger).Errorf("Failed to get budgets: %s", err.Error())
+		ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error: "Failed to get budgets",
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.BudgetListResponse{
+		Budgets: budgets,
+	})
+}
+
+// @Summary Получение бюджета
+// @Description Получение бюджета по ID
+// @Tags budgets
+// @Accept json
+// @Produce json
+// @Param id path string true "ID бюджета"
+// @Success 200 {object} model.BudgetResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /budgets/{id} [get]
+func (e *Endpoint) GetBudget(ctx *gin.Context) {
+	id, ok := e.pathUUID(ctx, "id", "budget")
+	if !ok {
+		return
+	}
+
+	budget, err := e.services.Budgets.GetBudget(ctx, id)
+	if err != nil {
+		logging.FromContext(ctx, e.logger).Errorf("Failed to get budget: %s", err.Error())
+		ctx.JSON(errorStatus(err), model.ErrorResponse{
+			Error: fmt.Sprintf("Failed to get budget: %s", err.Error()),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.BudgetResponse{
+		Budget: budget,
+	})
+}
+
+// @Summary Изменение бюджета
+// @Description Изменение лимита бюджета
+// @Tags budgets
+// @Accept json
+// @Produce json
+// @Param id path string true "ID бюджета"
+// @Param budget body model.UpdateBudgetRequest true "Новый лимит"
+// @Success 200 {object} model.BudgetResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /budgets/{id} [put]
+func (e *Endpoint) UpdateBudget(ctx *gin.Context) {
+	id, ok := e.pathUUID(ctx, "id", "budget")
+	if !ok {
+		return
+	}
+
+	var req model.UpdateBudgetRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		logging.FromContext(ctx, e.logger).Warnf("Invalid request body: %s", err.Error())
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: "Invalid request body",
+		})
+		return
+	}
+
+	budget, err := e.services.Budgets.UpdateBudget(ctx, id, req)
+	if err != nil {
+		logging.FromContext(ctx, e.logger).Errorf("Failed to update budget: %s", err.Error())
+		ctx.JSON(errorStatus(err), model.ErrorResponse{
+			Error: fmt.Sprintf("Failed to update budget: %s", err.Error()),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.BudgetResponse{
+		Budget: budget,
+	})
+}
+
+// @Summary Удаление бюджета
+// @Description Удаление бюджета по ID
+// @Tags budgets
+// @Accept json
+// @Produce json
+// @Param id path string true "ID бюджета"
+// @Success 204
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /budgets/{id} [delete]
+func (e *Endpoint) DeleteBudget(ctx *gin.Context) {
+	id, ok := e.pathUUID(ctx, "id", "budget")
+	if !ok {
+		return
+	}
+
+	if err := e.services.Budgets.DeleteBudget(ctx, id); err != nil {
+		logging.FromContext(ctx, e.logger).Errorf("Failed to delete budget: %s", err.Error())
+		ctx.JSON(errorStatus(err), model.ErrorResponse{
+			Error: fmt.Sprintf("Failed to delete budget: %s", err.Error()),
+		})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// @Summary Расход бюджета
+// @Description Траты за период бюджета, содержащий указанный месяц (по умолчанию текущий). Считаются так же, как суммарная стоимость подписок
+// @Tags budgets
+// @Accept json
+// @Produce json
+// @Param id path string true "ID бюджета"
+// @Param month query string false "Месяц периода (MM-YYYY)"
+// @Success 200 {object} model.BudgetConsumptionResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /budgets/{id}/consumption [get]
+func (e *Endpoint) GetBudgetConsumption(ctx *gin.Context) {
+	id, ok := e.pathUUID(ctx, "id", "budget")
+	if !ok {
+		return
+	}
+	month, ok := e.queryMonth(ctx, "month")
+	if !ok {
+		return
+	}
+	if month.IsZero() {
+		month = time.Now().UTC()
+	}
+
+	consumption, err := e.services.Budgets.GetConsumption(ctx, id, month)
+	if err != nil {
+		logging.FromContext(ctx, e.logger).Errorf("Failed to get budget consumption: %s", err.Error())
+		ctx.JSON(errorStatus(err), model.ErrorResponse{
+			Error: fmt.Sprintf("Failed to get budget consumption: %s", err.Error()),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.BudgetConsumptionResponse{
+		Consumption: consumption,
+	})
+}
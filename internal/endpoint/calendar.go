@@ -0,0 +1,116 @@
+package endpoint
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lavatee/subs/internal/logging"
+	"github.com/lavatee/subs/internal/model"
+)
+
+// @Summary Выпуск ссылки на календарь
+// @Description Создание секретного токена для iCalendar-ленты списаний пользователя. Предыдущий токен перестает действовать
+// @Tags calendar
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Success 201 {object} model.CalendarTokenResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /users/{id}/calendar/token [post]
+func (e *Endpoint) IssueCalendarToken(ctx *gin.Context) {
+	userID, ok := e.pathUUID(ctx, "id", "user")
+	if !ok {
+		return
+	}
+
+	token, err := e.services.Calendar.IssueToken(ctx, userID)
+	if err != nil {
+		logging.FromContext(ctx, e.logger).Errorf("Failed to issue calendar token: %s", err.Error())
+		ctx.JSON(errorStatus(err), model.ErrorResponse{
+			Error: fmt.Sprintf("Failed to issue calendar token: %s", err.Error()),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, model.CalendarTokenResponse{
+		Token:   token,
+		FeedURL: calendarFeedURL(ctx, token),
+	})
+}
+
+// @Summary Отзыв ссылки на календарь
+// @Description Удаление токена календаря, после чего лента перестает открываться
+// @Tags calendar
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Success 204
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /users/{id}/calendar/token [delete]
+func (e *Endpoint) RevokeCalendarToken(ctx *gin.Context) {
+	userID, ok := e.pathUUID(ctx, "id", "user")
+	if !ok {
+		return
+	}
+
+	if err := e.services.Calendar.RevokeToken(ctx, userID); err != nil {
+		logging.FromContext(ctx, e.logger).Errorf("Failed to revoke calendar token: %s", err.Error())
+		ctx.JSON(errorStatus(err), model.ErrorResponse{
+			Error: fmt.Sprintf("Failed to revoke calendar token: %s", err.Error()),
+		})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// @Summary Календарь списаний
+// @Description iCalendar-лента (RFC 5545) с событием на каждую предстоящую дату списания и дату окончания подписок пользователя
+// @Tags calendar
+// @Produce text/calendar
+// @Param id path string true "ID пользователя"
+// @Param token query string true "Токен календаря"
+// @Success 200 {string} string
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /users/{id}/calendar.ics [get]
+func (e *Endpoint) GetCalendar(ctx *gin.Context) {
+	userID, ok := e.pathUUID(ctx, "id", "user")
+	if !ok {
+		return
+	}
+
+	calendar, err := e.services.Calendar.GetCalendar(ctx, userID, ctx.Query("token"))
+	if err != nil {
+		// The error is not echoed: it would tell a wrong token from a missing one.
+		logging.FromContext(ctx, e.logger).Errorf("Failed to get calendar: %s", err.Error())
+		ctx.JSON(errorStatus(err), model.ErrorResponse{
+			Error: "Failed to get calendar",
+		})
+		return
+	}
+
+	ctx.Header("Cache-Control", "private, max-age=3600")
+	ctx.Data(http.StatusOK, "text/calendar; charset=utf-8", calendar)
+}
+
+// calendarFeedURL returns the address of the feed as seen by the client.
+func calendarFeedURL(ctx *gin.Context, token model.CalendarToken) string {
+	scheme := "http"
+	if ctx.Request.TLS != nil || ctx.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	feed := url.URL{
+		Scheme:   scheme,
+		Host:     ctx.Request.Host,
+		Path:     fmt.Sprintf("/api/v1/users/%s/calendar.ics", token.UserID),
+		RawQuery: url.Values{"token": {token.Token}}.Encode(),
+	}
+	return feed.String()
+}
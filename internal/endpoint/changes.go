@@ -0,0 +1,40 @@
+package endpoint
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lavatee/subs/internal/logging"
+	"github.com/lavatee/subs/internal/model"
+)
+
+// @Summary Лента изменений подписок
+// @Description Подписки, созданные, измененные, удаленные или перенесенные в архив (tombstone с deleted=true) после токена синхронизации, и токен для следующего запроса. Без since возвращаются все текущие подписки. Устаревший токен дает 410, после чего нужна полная синхронизация
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param since query string false "Токен синхронизации"
+// @Param user_id query string false "ID пользователя"
+// @Success 200 {object} model.SubscriptionChangesResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 410 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /subscriptions/changes [get]
+func (e *Endpoint) GetSubscriptionChanges(ctx *gin.Context) {
+	userID, ok := e.queryUserID(ctx)
+	if !ok {
+		return
+	}
+
+	changes, err := e.services.Changes.GetChanges(ctx, userID, ctx.Query("since"))
+	if err != nil {
+		logging.FromContext(ctx, e.logger).Errorf("Failed to get subscription changes: %s", err.Error())
+		ctx.JSON(errorStatus(err), model.ErrorResponse{
+			Error: fmt.Sprintf("Failed to get subscription changes: %s", err.Error()),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, changes)
+}
@@ -0,0 +1,69 @@
+package endpoint
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lavatee/subs/internal/logging"
+	"github.com/lavatee/subs/internal/model"
+)
+
+// @Summary Сравнение трат за два периода
+// @Description Суммарная стоимость подписок за период и за период сравнения, разница в рублях и процентах и подписки, из-за которых она возникла: добавленные, удаленные и замененные подпиской с другой ценой. Цена, измененная в самой подписке, меняет сумму обоих периодов и изменением не считается. Без дат сравнивается текущий месяц. Период сравнения задается явно или параметром against: previous — предыдущий период той же длины, year — тот же период год назад
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param user_id query string false "Фильтрация по ID пользователя"
+// @Param service_name query string false "Фильтрация по названию сервиса"
+// @Param start_date query string false "Начальная дата (MM-YYYY)"
+// @Param end_date query string false "Конечная дата (MM-YYYY)"
+// @Param against query string false "previous или year, по умолчанию previous"
+// @Param compare_start_date query string false "Начальная дата периода сравнения (MM-YYYY)"
+// @Param compare_end_date query string false "Конечная дата периода сравнения (MM-YYYY)"
+// @Param include_archived query bool false "Включить архивные подписки"
+// @Success 200 {object} model.SpendComparisonResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /subscriptions/total/compare [get]
+func (e *Endpoint) CompareSpend(ctx *gin.Context) {
+	userID, ok := e.queryUserID(ctx)
+	if !ok {
+		return
+	}
+	req := model.CompareSpendRequest{
+		UserID:      userID,
+		ServiceName: ctx.Query("service_name"),
+		Against:     ctx.Query("against"),
+	}
+	for _, param := range []struct {
+		name  string
+		month *time.Time
+	}{
+		{"start_date", &req.StartDate},
+		{"end_date", &req.EndDate},
+		{"compare_start_date", &req.CompareStartDate},
+		{"compare_end_date", &req.CompareEndDate},
+	} {
+		if *param.month, ok = e.queryMonth(ctx, param.name); !ok {
+			return
+		}
+	}
+	if req.IncludeArchived, ok = e.queryBool(ctx, "include_archived"); !ok {
+		return
+	}
+
+	comparison, err := e.services.Comparison.CompareSpend(ctx, req)
+	if err != nil {
+		logging.FromContext(ctx, e.logger).Errorf("Failed to compare spend: %s", err.Error())
+		ctx.JSON(errorStatus(err), model.ErrorResponse{
+			Error: fmt.Sprintf("Failed to compare spend: %s", err.Error()),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.SpendComparisonResponse{
+		Comparison: comparison,
+	})
+}
@@ -0,0 +1,77 @@
+package endpoint
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lavatee/subs/internal/logging"
+	"github.com/lavatee/subs/internal/model"
+)
+
+// @Summary Дублирующиеся подписки
+// @Description Поиск подписок пользователя на один и тот же сервис (с учетом разного написания названия) с пересекающимися периодами и подсчет лишних трат
+// @Tags duplicates
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Success 200 {object} model.DuplicatesResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /users/{id}/duplicates [get]
+func (e *Endpoint) GetDuplicates(ctx *gin.Context) {
+	userID, ok := e.pathUUID(ctx, "id", "user")
+	if !ok {
+		return
+	}
+
+	duplicates, err := e.services.Duplicates.GetDuplicates(ctx, userID)
+	if err != nil {
+		logging.FromContext(ctx, e.logger).Errorf("Failed to get duplicates: %s", err.Error())
+		ctx.JSON(errorStatus(err), model.ErrorResponse{
+			Error: fmt.Sprintf("Failed to get duplicates: %s", err.Error()),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, duplicates)
+}
+
+// @Summary Объединение дублей
+// @Description Объединение подписок пользователя на один сервис в одну. Остается keep_id или самая дорогая подписка, ее период растягивается на периоды остальных, остальные удаляются
+// @Tags duplicates
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param merge body model.MergeDuplicatesRequest true "Объединяемые подписки"
+// @Success 200 {object} model.MergeDuplicatesResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /users/{id}/duplicates/merge [post]
+func (e *Endpoint) MergeDuplicates(ctx *gin.Context) {
+	userID, ok := e.pathUUID(ctx, "id", "user")
+	if !ok {
+		return
+	}
+
+	var req model.MergeDuplicatesRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		logging.FromContext(ctx, e.logger).Warnf("Invalid request body: %s", err.Error())
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: "Invalid request body",
+		})
+		return
+	}
+
+	merged, err := e.services.Duplicates.MergeDuplicates(ctx, userID, req)
+	if err != nil {
+		logging.FromContext(ctx, e.logger).Errorf("Failed to merge duplicates: %s", err.Error())
+		ctx.JSON(errorStatus(err), model.ErrorResponse{
+			Error: fmt.Sprintf("Failed to merge duplicates: %s", err.Error()),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, merged)
+}
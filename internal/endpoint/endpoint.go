@@ -1,33 +1,55 @@
 package endpoint
 
 import (
+	"net/http"
+
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/lavatee/subs/internal/metrics"
 	"github.com/lavatee/subs/internal/service"
+	"github.com/lavatee/subs/internal/tracing"
 	"github.com/sirupsen/logrus"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
+// Config holds endpoint settings.
+type Config struct {
+	// ServeMetrics mounts /metrics on the API router. Leave it off when
+	// metrics are served on the admin port instead.
+	ServeMetrics bool
+}
+
 type Endpoint struct {
 	services *service.Service
 	logger   *logrus.Logger
+	config   Config
 }
 
-func NewEndpoint(services *service.Service, logger *logrus.Logger) *Endpoint {
+func NewEndpoint(services *service.Service, logger *logrus.Logger, config Config) *Endpoint {
 	return &Endpoint{
 		services: services,
 		logger:   logger,
+		config:   config,
 	}
 }
 
 func (e *Endpoint) InitRoutes() *gin.Engine {
 	router := gin.New()
+	// Handlers pass the gin context on to services, so it has to expose the
+	// request context, which carries the span started by otelgin.
+	router.ContextWithFallback = true
+	router.Use(otelgin.Middleware(tracing.ServiceName, otelgin.WithFilter(func(r *http.Request) bool {
+		return !probePaths[r.URL.Path]
+	})))
+	router.Use(e.logRequests)
+	router.Use(observeRequests)
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE"},
-		AllowHeaders:     []string{"Origin", "Content-Type"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Last-Event-ID", "traceparent", "tracestate", requestIDHeader},
+		ExposeHeaders:    []string{"Content-Length", "Content-Disposition", requestIDHeader},
 		AllowCredentials: true,
 	}))
 	api := router.Group("/api/v1")
@@ -38,7 +60,51 @@ func (e *Endpoint) InitRoutes() *gin.Engine {
 		api.PUT("/subscriptions/:id", e.UpdateSubscription)
 		api.DELETE("/subscriptions/:id", e.DeleteSubscription)
 		api.GET("/subscriptions/total", e.GetTotalCost)
+		api.GET("/subscriptions/total/monthly", e.GetMonthlySpend)
+		api.GET("/subscriptions/total/compare", e.CompareSpend)
+		api.GET("/subscriptions/events", e.StreamSubscriptionEvents)
+		api.GET("/subscriptions/changes", e.GetSubscriptionChanges)
+		api.POST("/subscriptions/import", e.ImportSubscriptions)
+		api.GET("/subscriptions/export", e.ExportSubscriptions)
+
+		api.POST("/webhooks", e.CreateWebhook)
+		api.GET("/webhooks", e.GetWebhooks)
+		api.GET("/webhooks/:id", e.GetWebhook)
+		api.DELETE("/webhooks/:id", e.DeleteWebhook)
+		api.GET("/webhooks/:id/deliveries", e.GetWebhookDeliveries)
+		api.POST("/webhooks/:id/deliveries/:delivery_id/redeliver", e.RedeliverWebhook)
+
+		api.POST("/budgets", e.CreateBudget)
+		api.GET("/budgets", e.GetBudgets)
+		api.GET("/budgets/:id", e.GetBudget)
+		api.PUT("/budgets/:id", e.UpdateBudget)
+		api.DELETE("/budgets/:id", e.DeleteBudget)
+		api.GET("/budgets/:id/consumption", e.GetBudgetConsumption)
+
+		api.GET("/users/:id/contact", e.GetUserContact)
+		api.PUT("/users/:id/contact", e.UpdateUserContact)
+		api.POST("/users/:id/statements", e.AnalyzeStatement)
+		api.GET("/users/:id/proposals", e.GetProposals)
+		api.POST("/users/:id/calendar/token", e.IssueCalendarToken)
+		api.DELETE("/users/:id/calendar/token", e.RevokeCalendarToken)
+		api.GET("/users/:id/calendar.ics", e.GetCalendar)
+		api.GET("/users/:id/duplicates", e.GetDuplicates)
+		api.POST("/users/:id/duplicates/merge", e.MergeDuplicates)
+
+		api.POST("/proposals/:id/accept", e.AcceptProposal)
+		api.POST("/proposals/:id/dismiss", e.DismissProposal)
+
+		api.GET("/analytics/top-services", e.GetTopServices)
+		api.GET("/analytics/average-prices", e.GetAveragePrices)
+		api.GET("/analytics/active", e.GetActiveSubscriptions)
+		api.GET("/analytics/churn", e.GetChurn)
+		api.GET("/analytics/user-spend", e.GetUserSpendStats)
 	}
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	router.GET("/healthz", e.Healthz)
+	router.GET("/readyz", e.Readyz)
+	if e.config.ServeMetrics {
+		router.GET("/metrics", gin.WrapH(metrics.Handler()))
+	}
 	return router
 }
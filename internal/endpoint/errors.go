@@ -0,0 +1,26 @@
+package endpoint
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/lavatee/subs/internal/model"
+)
+
+// errorStatus maps domain errors returned by services to HTTP status codes.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, model.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, model.ErrInvalidInput):
+		return http.StatusBadRequest
+	case errors.Is(err, model.ErrUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, model.ErrExpired):
+		return http.StatusGone
+	case errors.Is(err, model.ErrConflict):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,91 @@
+package endpoint
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lavatee/subs/internal/logging"
+	"github.com/lavatee/subs/internal/model"
+)
+
+const eventStreamHeartbeat = 15 * time.Second
+
+// @Summary Поток событий подписок
+// @Description Server-Sent Events со всеми созданиями, изменениями и удалениями подписок. Переподключение с заголовком Last-Event-ID продолжает поток с пропущенных событий; если они уже вытеснены из буфера, первым приходит событие reset, и клиенту нужно перезагрузить список
+// @Tags subscriptions
+// @Produce text/event-stream
+// @Param user_id query string false "ID пользователя"
+// @Param Last-Event-ID header string false "ID последнего полученного события"
+// @Success 200 {string} string "Поток событий subscription.created, subscription.updated, subscription.deleted"
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 503 {object} model.ErrorResponse
+// @Router /subscriptions/events [get]
+func (e *Endpoint) StreamSubscriptionEvents(ctx *gin.Context) {
+	userID, ok := e.queryUserID(ctx)
+	if !ok {
+		return
+	}
+	var after *model.EventPosition
+	if lastEventID := ctx.GetHeader("Last-Event-ID"); lastEventID != "" {
+		position, err := model.ParseEventPosition(lastEventID)
+		if err != nil {
+			logging.FromContext(ctx, e.logger).Warnf("Invalid Last-Event-ID: %s", err.Error())
+			ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error: "Invalid Last-Event-ID",
+			})
+			return
+		}
+		after = &position
+	}
+
+	sub, err := e.services.Events.Subscribe(ctx, userID, after)
+	if err != nil {
+		logging.FromContext(ctx, e.logger).Errorf("Failed to subscribe to events: %s", err.Error())
+		ctx.JSON(errorStatus(err), model.ErrorResponse{
+			Error: fmt.Sprintf("Failed to subscribe to events: %s", err.Error()),
+		})
+		return
+	}
+	defer sub.Close()
+
+	// The stream outlives the server's write timeout.
+	if err := http.NewResponseController(ctx.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		logging.FromContext(ctx, e.logger).Warnf("Failed to clear write deadline for event stream: %s", err.Error())
+	}
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+
+	if sub.Reset {
+		fmt.Fprintf(ctx.Writer, "id: %s\nevent: reset\ndata: {}\n\n", sub.Position)
+	}
+	for _, event := range sub.Backlog {
+		writeStreamEvent(ctx, event)
+	}
+	ctx.Writer.Flush()
+
+	heartbeat := time.NewTicker(eventStreamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+		case event, ok := <-sub.Events:
+			if !ok {
+				return
+			}
+			writeStreamEvent(ctx, event)
+		case <-heartbeat.C:
+			fmt.Fprint(ctx.Writer, ": heartbeat\n\n")
+		}
+		ctx.Writer.Flush()
+	}
+}
+
+func writeStreamEvent(ctx *gin.Context, event model.StreamEvent) {
+	fmt.Fprintf(ctx.Writer, "id: %s\nevent: %s\ndata: %s\n\n", event.Position, event.Type, event.Payload)
+}
@@ -0,0 +1,87 @@
+package endpoint
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/logging"
+	"github.com/lavatee/subs/internal/model"
+	"github.com/lavatee/subs/internal/service"
+)
+
+// exportBufferSize is how much of an export is held back before the first
+// write, so that early failures can still be answered with an error status.
+const exportBufferSize = 64 << 10
+
+// @Summary Экспорт подписок
+// @Description Выгрузка подписок в CSV, NDJSON или XLSX с теми же фильтрами, что и у списка. Строки передаются по мере чтения из базы. Выгруженный CSV можно загрузить обратно через импорт
+// @Tags subscriptions
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string true "csv, ndjson или xlsx"
+// @Param user_id query string false "Фильтрация по ID пользователя"
+// @Param service_name query string false "Фильтрация по названию сервиса"
+// @Param include_archived query bool false "Включить архивные подписки"
+// @Success 200 {file} file
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /subscriptions/export [get]
+func (e *Endpoint) ExportSubscriptions(ctx *gin.Context) {
+	format := ctx.Query("format")
+	contentType, err := service.ExportContentType(format)
+	if err != nil {
+		logging.FromContext(ctx, e.logger).Warnf("Invalid export format: %s", err.Error())
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	var userUUID uuid.UUID
+	if userID := ctx.Query("user_id"); userID != "" {
+		userUUID, err = uuid.Parse(userID)
+		if err != nil {
+			logging.FromContext(ctx, e.logger).Warnf("Invalid user ID format: %s", err.Error())
+			ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error: "Invalid user ID format",
+			})
+			return
+		}
+	}
+
+	includeArchived, ok := e.queryBool(ctx, "include_archived")
+	if !ok {
+		return
+	}
+
+	// Large exports outlive the server's write timeout.
+	if err := http.NewResponseController(ctx.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		logging.FromContext(ctx, e.logger).Warnf("Failed to clear write deadline for export: %s", err.Error())
+	}
+	ctx.Header("Content-Type", contentType)
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="subscriptions.%s"`, format))
+
+	out := bufio.NewWriterSize(ctx.Writer, exportBufferSize)
+	err = e.services.Export.ExportSubscriptions(ctx, out, format, userUUID, ctx.Query("service_name"), includeArchived)
+	if err == nil {
+		err = out.Flush()
+	}
+	if err != nil {
+		logging.FromContext(ctx, e.logger).Errorf("Failed to export subscriptions: %s", err.Error())
+		if ctx.Writer.Written() {
+			// Part of the file is already sent; cutting the connection short
+			// is the only way left to tell the client it is incomplete.
+			panic(http.ErrAbortHandler)
+		}
+		ctx.Writer.Header().Del("Content-Disposition")
+		ctx.Writer.Header().Del("Content-Type")
+		ctx.JSON(errorStatus(err), model.ErrorResponse{
+			Error: "Failed to export subscriptions",
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package endpoint
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// probePaths are requested every few seconds by the orchestrator, so they
+// are neither traced nor logged above debug level.
+var probePaths = map[string]bool{
+	"/metrics": true,
+	"/healthz": true,
+	"/readyz":  true,
+}
+
+// Healthz is the liveness probe: it only tells that the process serves HTTP.
+func (e *Endpoint) Healthz(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, e.services.Health.Live())
+}
+
+// Readyz is the readiness probe. It answers 503 when the database or the
+// workers are unhealthy and from the moment shutdown begins.
+func (e *Endpoint) Readyz(ctx *gin.Context) {
+	health, ready := e.services.Health.Ready(ctx)
+	if !ready {
+		ctx.JSON(http.StatusServiceUnavailable, health)
+		return
+	}
+	ctx.JSON(http.StatusOK, health)
+}
@@ -0,0 +1,75 @@
+package endpoint
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lavatee/subs/internal/logging"
+	"github.com/lavatee/subs/internal/model"
+	"github.com/lavatee/subs/internal/service"
+)
+
+const importMaxBody = 64 << 20
+
+// @Summary Импорт подписок
+// @Description Массовое создание подписок из CSV (с заголовком) или NDJSON с полями CreateSubscriptionRequest. Ошибочные строки попадают в отчет, остальные сохраняются одной транзакцией. Если импорт выводит траты за пределы бюджета, в отчете возвращаются предупреждения. В режиме dry_run строки только проверяются
+// @Tags subscriptions
+// @Accept text/csv
+// @Accept application/x-ndjson
+// @Produce json
+// @Param format query string false "csv или ndjson, по умолчанию по Content-Type"
+// @Param dry_run query bool false "Только проверить строки"
+// @Param mapping query string false "Соответствие полей колонкам, например service_name=Service,price=Cost"
+// @Param file body string true "Содержимое файла"
+// @Success 200 {object} model.ImportReport
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /subscriptions/import [post]
+func (e *Endpoint) ImportSubscriptions(ctx *gin.Context) {
+	format := ctx.Query("format")
+	if format == "" {
+		format = importFormat(ctx.GetHeader("Content-Type"))
+	}
+	dryRun, ok := e.queryBool(ctx, "dry_run")
+	if !ok {
+		return
+	}
+	mapping, err := service.ParseImportMapping(ctx.Query("mapping"))
+	if err != nil {
+		logging.FromContext(ctx, e.logger).Warnf("Invalid import mapping: %s", err.Error())
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+
+	body := http.MaxBytesReader(ctx.Writer, ctx.Request.Body, importMaxBody)
+	report, err := e.services.Import.ImportSubscriptions(ctx, body, service.ImportOptions{
+		Format:  format,
+		DryRun:  dryRun,
+		Mapping: mapping,
+	})
+	if err != nil {
+		logging.FromContext(ctx, e.logger).Errorf("Failed to import subscriptions: %s", err.Error())
+		ctx.JSON(errorStatus(err), model.ErrorResponse{
+			Error: fmt.Sprintf("Failed to import subscriptions: %s", err.Error()),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
+
+// importFormat picks the import format from a Content-Type header.
+func importFormat(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv":
+		return service.ImportCSV
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		return service.ImportNDJSON
+	}
+	return ""
+}
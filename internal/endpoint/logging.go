@@ -0,0 +1,72 @@
+package endpoint
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/logging"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// validRequestID accepts the request IDs of callers that are safe to echo
+// and log; anything else is replaced with a new one.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:\-]{1,128}$`)
+
+// logRequests assigns the request ID, taken from X-Request-ID when the caller
+// sent a valid one, stores a logger carrying it in the request context and
+// logs the request once it is handled.
+func (e *Endpoint) logRequests(ctx *gin.Context) {
+	started := time.Now()
+	requestID := ctx.GetHeader(requestIDHeader)
+	if !validRequestID.MatchString(requestID) {
+		requestID = uuid.NewString()
+	}
+	ctx.Header(requestIDHeader, requestID)
+
+	fields := logrus.Fields{"request_id": requestID}
+	if span := trace.SpanContextFromContext(ctx.Request.Context()); span.IsValid() {
+		fields["trace_id"] = span.TraceID().String()
+	}
+	if userID := requestUserID(ctx); userID != "" {
+		fields["user_id"] = userID
+	}
+	logger := e.logger.WithFields(fields)
+	ctx.Request = ctx.Request.WithContext(logging.WithLogger(ctx.Request.Context(), logger))
+
+	ctx.Next()
+
+	route := ctx.FullPath()
+	if route == "" {
+		route = unmatchedRoute
+	}
+	entry := logger.WithFields(logrus.Fields{
+		"method":     ctx.Request.Method,
+		"route":      route,
+		"status":     ctx.Writer.Status(),
+		"latency_ms": time.Since(started).Milliseconds(),
+		"client_ip":  ctx.ClientIP(),
+	})
+	switch {
+	case ctx.Writer.Status() >= 500:
+		entry.Error("Request failed")
+	case probePaths[ctx.Request.URL.Path]:
+		entry.Debug("Request handled")
+	default:
+		entry.Info("Request handled")
+	}
+}
+
+// requestUserID returns the user a request is about: the :id of /users
+// routes or the user_id query parameter.
+func requestUserID(ctx *gin.Context) string {
+	if strings.HasPrefix(ctx.FullPath(), "/api/v1/users/:id") {
+		return ctx.Param("id")
+	}
+	return ctx.Query("user_id")
+}
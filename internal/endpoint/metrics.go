@@ -0,0 +1,37 @@
+package endpoint
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lavatee/subs/internal/metrics"
+)
+
+// unmatchedRoute labels requests that matched no route, so that scanners
+// probing random paths cannot blow up the label cardinality.
+const unmatchedRoute = "unmatched"
+
+// observeRequests counts every request and records its latency, labelled
+// with the route pattern rather than the path.
+func observeRequests(ctx *gin.Context) {
+	started := time.Now()
+	ctx.Next()
+	route := ctx.FullPath()
+	if route == "" {
+		route = unmatchedRoute
+	}
+	status := strconv.Itoa(ctx.Writer.Status())
+	metrics.HTTPRequests.WithLabelValues(ctx.Request.Method, route, status).Inc()
+	metrics.HTTPRequestDuration.WithLabelValues(ctx.Request.Method, route, status).Observe(time.Since(started).Seconds())
+}
+
+// InitAdminRoutes returns the router of the admin port, which serves
+// /metrics when it is kept off the API port, and the probes.
+func (e *Endpoint) InitAdminRoutes() *gin.Engine {
+	router := gin.New()
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+	router.GET("/healthz", e.Healthz)
+	router.GET("/readyz", e.Readyz)
+	return router
+}
@@ -0,0 +1,66 @@
+package endpoint
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lavatee/subs/internal/logging"
+	"github.com/lavatee/subs/internal/model"
+)
+
+const maxMonthlySpendMonths = 120
+
+// @Summary Помесячная стоимость подписок
+// @Description Стоимость активных подписок по каждому месяцу выбранного периода с фильтрацией по id пользователя и названию подписки
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param user_id query string false "Фильтрация по ID пользователя"
+// @Param service_name query string false "Фильтрация по названию сервиса"
+// @Param start_date query string true "Начальная дата (MM-YYYY)"
+// @Param end_date query string true "Конечная дата (MM-YYYY)"
+// @Success 200 {object} model.MonthlySpendResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /subscriptions/total/monthly [get]
+func (e *Endpoint) GetMonthlySpend(ctx *gin.Context) {
+	userID, ok := e.queryUserID(ctx)
+	if !ok {
+		return
+	}
+	startDate, ok := e.queryMonth(ctx, "start_date")
+	if !ok {
+		return
+	}
+	endDate, ok := e.queryMonth(ctx, "end_date")
+	if !ok {
+		return
+	}
+
+	if startDate.IsZero() || endDate.IsZero() || startDate.After(endDate) {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: "start_date and end_date are required and start_date must not be after end_date",
+		})
+		return
+	}
+	if startDate.AddDate(0, maxMonthlySpendMonths, 0).Before(endDate) {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: fmt.Sprintf("Period is too long, at most %d months are allowed", maxMonthlySpendMonths),
+		})
+		return
+	}
+
+	months, err := e.services.MonthlySpend.GetMonthlySpend(ctx, userID, ctx.Query("service_name"), startDate, endDate)
+	if err != nil {
+		logging.FromContext(ctx, e.logger).Errorf("Failed to get monthly spend: %s", err.Error())
+		ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error: "Failed to get monthly spend",
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.MonthlySpendResponse{
+		Months: months,
+	})
+}
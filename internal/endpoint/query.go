@@ -0,0 +1,66 @@
+package endpoint
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/logging"
+	"github.com/lavatee/subs/internal/model"
+)
+
+// queryUserID parses the optional user_id query parameter. On failure it
+// writes a 400 response and returns false.
+func (e *Endpoint) queryUserID(ctx *gin.Context) (uuid.UUID, bool) {
+	userID := ctx.Query("user_id")
+	if userID == "" {
+		return uuid.Nil, true
+	}
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		logging.FromContext(ctx, e.logger).Warnf("Invalid user ID format: %s", err.Error())
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: "Invalid user ID format",
+		})
+		return uuid.Nil, false
+	}
+	return userUUID, true
+}
+
+// queryMonth parses an optional MM-YYYY query parameter. On failure it
+// writes a 400 response and returns false.
+func (e *Endpoint) queryMonth(ctx *gin.Context, name string) (time.Time, bool) {
+	value := ctx.Query(name)
+	if value == "" {
+		return time.Time{}, true
+	}
+	month, err := time.Parse("01-2006", value)
+	if err != nil {
+		logging.FromContext(ctx, e.logger).Warnf("Invalid %s format: %s", name, err.Error())
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: "Invalid " + name + " format, expected MM-YYYY",
+		})
+		return time.Time{}, false
+	}
+	return month, true
+}
+
+// queryBool parses an optional boolean query parameter, false when absent.
+// On failure it writes a 400 response and returns false as the second value.
+func (e *Endpoint) queryBool(ctx *gin.Context, name string) (bool, bool) {
+	value := ctx.Query(name)
+	if value == "" {
+		return false, true
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		logging.FromContext(ctx, e.logger).Warnf("Invalid %s value: %s", name, err.Error())
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: "Invalid " + name + " value, expected true or false",
+		})
+		return false, false
+	}
+	return parsed, true
+}
@@ -0,0 +1,81 @@
+package endpoint
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lavatee/subs/internal/logging"
+	"github.com/lavatee/subs/internal/model"
+)
+
+// @Summary Контакты пользователя
+// @Description Получение адресов, на которые отправляются напоминания о продлении и окончании подписок
+// @Tags reminders
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Success 200 {object} model.UserContactResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /users/{id}/contact [get]
+func (e *Endpoint) GetUserContact(ctx *gin.Context) {
+	userID, ok := e.pathUUID(ctx, "id", "user")
+	if !ok {
+		return
+	}
+
+	contact, err := e.services.Reminders.GetContact(ctx, userID)
+	if err != nil {
+		logging.FromContext(ctx, e.logger).Errorf("Failed to get user contact: %s", err.Error())
+		ctx.JSON(errorStatus(err), model.ErrorResponse{
+			Error: fmt.Sprintf("Failed to get user contact: %s", err.Error()),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.UserContactResponse{
+		Contact: contact,
+	})
+}
+
+// @Summary Обновление контактов пользователя
+// @Description Установка email и/или URL вебхука для напоминаний. Пустое поле отключает соответствующий канал. URL вебхука должен быть http или https и, если не разрешены частные сети, указывать на публичный адрес
+// @Tags reminders
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param contact body model.UpdateUserContactRequest true "Контакты пользователя"
+// @Success 200 {object} model.UserContactResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /users/{id}/contact [put]
+func (e *Endpoint) UpdateUserContact(ctx *gin.Context) {
+	userID, ok := e.pathUUID(ctx, "id", "user")
+	if !ok {
+		return
+	}
+
+	var req model.UpdateUserContactRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		logging.FromContext(ctx, e.logger).Warnf("Invalid request body: %s", err.Error())
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: "Invalid request body",
+		})
+		return
+	}
+
+	contact, err := e.services.Reminders.UpdateContact(ctx, userID, req)
+	if err != nil {
+		logging.FromContext(ctx, e.logger).Errorf("Failed to update user contact: %s", err.Error())
+		ctx.JSON(errorStatus(err), model.ErrorResponse{
+			Error: fmt.Sprintf("Failed to update user contact: %s", err.Error()),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.UserContactResponse{
+		Contact: contact,
+	})
+}
@@ -0,0 +1,170 @@
+package endpoint
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lavatee/subs/internal/logging"
+	"github.com/lavatee/subs/internal/model"
+	"github.com/lavatee/subs/internal/service"
+)
+
+const statementMaxBody = 16 << 20
+
+// @Summary Анализ банковской выписки
+// @Description Поиск регулярных ежемесячных списаний в выписке (CSV с заголовком, QIF или OFX) и сохранение их как предложений подписок. Списания по уже добавленным сервисам и ранее отклоненные предложения пропускаются. Строки, которые не удалось разобрать (например, итоговые), пропускаются и перечисляются в skipped
+// @Tags proposals
+// @Accept text/csv
+// @Accept application/qif
+// @Accept application/x-ofx
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param format query string false "csv, qif или ofx, по умолчанию по Content-Type"
+// @Param file body string true "Содержимое выписки"
+// @Success 200 {object} model.StatementAnalysisResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /users/{id}/statements [post]
+func (e *Endpoint) AnalyzeStatement(ctx *gin.Context) {
+	userID, ok := e.pathUUID(ctx, "id", "user")
+	if !ok {
+		return
+	}
+	format := ctx.Query("format")
+	if format == "" {
+		format = statementFormat(ctx.GetHeader("Content-Type"))
+	}
+
+	body := http.MaxBytesReader(ctx.Writer, ctx.Request.Body, statementMaxBody)
+	response, err := e.services.Statements.AnalyzeStatement(ctx, userID, body, format)
+	if err != nil {
+		logging.FromContext(ctx, e.logger).Errorf("Failed to analyze statement: %s", err.Error())
+		ctx.JSON(errorStatus(err), model.ErrorResponse{
+			Error: fmt.Sprintf("Failed to analyze statement: %s", err.Error()),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// @Summary Предложения подписок
+// @Description Получение подписок, найденных в выписках пользователя
+// @Tags proposals
+// @Accept json
+// @Produce json
+// @Param id path string true "ID пользователя"
+// @Param status query string false "pending, accepted или dismissed"
+// @Success 200 {object} model.ProposalListResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /users/{id}/proposals [get]
+func (e *Endpoint) GetProposals(ctx *gin.Context) {
+	userID, ok := e.pathUUID(ctx, "id", "user")
+	if !ok {
+		return
+	}
+
+	proposals, err := e.services.Statements.GetProposals(ctx, userID, ctx.Query("status"))
+	if err != nil {
+		logging.FromContext(ctx, e.logger).Errorf("Failed to get proposals: %s", err.Error())
+		ctx.JSON(errorStatus(err), model.ErrorResponse{
+			Error: fmt.Sprintf("Failed to get proposals: %s", err.Error()),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.ProposalListResponse{
+		Proposals: proposals,
+	})
+}
+
+// @Summary Принятие предложения
+// @Description Создание подписки из предложения. Поля тела запроса необязательны и заменяют предложенные значения
+// @Tags proposals
+// @Accept json
+// @Produce json
+// @Param id path string true "ID предложения"
+// @Param overrides body model.AcceptProposalRequest false "Изменения предложенной подписки"
+// @Success 201 {object} model.AcceptProposalResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /proposals/{id}/accept [post]
+func (e *Endpoint) AcceptProposal(ctx *gin.Context) {
+	id, ok := e.pathUUID(ctx, "id", "proposal")
+	if !ok {
+		return
+	}
+
+	var req model.AcceptProposalRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.BindJSON(&req); err != nil {
+			logging.FromContext(ctx, e.logger).Warnf("Invalid request body: %s", err.Error())
+			ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error: "Invalid request body",
+			})
+			return
+		}
+	}
+
+	response, err := e.services.Statements.AcceptProposal(ctx, id, req)
+	if err != nil {
+		logging.FromContext(ctx, e.logger).Errorf("Failed to accept proposal: %s", err.Error())
+		ctx.JSON(errorStatus(err), model.ErrorResponse{
+			Error: fmt.Sprintf("Failed to accept proposal: %s", err.Error()),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response)
+}
+
+// @Summary Отклонение предложения
+// @Description Отклоненное предложение не появляется снова при повторной загрузке выписки
+// @Tags proposals
+// @Accept json
+// @Produce json
+// @Param id path string true "ID предложения"
+// @Success 200 {object} model.ProposalResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /proposals/{id}/dismiss [post]
+func (e *Endpoint) DismissProposal(ctx *gin.Context) {
+	id, ok := e.pathUUID(ctx, "id", "proposal")
+	if !ok {
+		return
+	}
+
+	proposal, err := e.services.Statements.DismissProposal(ctx, id)
+	if err != nil {
+		logging.FromContext(ctx, e.logger).Errorf("Failed to dismiss proposal: %s", err.Error())
+		ctx.JSON(errorStatus(err), model.ErrorResponse{
+			Error: fmt.Sprintf("Failed to dismiss proposal: %s", err.Error()),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.ProposalResponse{
+		Proposal: proposal,
+	})
+}
+
+// statementFormat picks the statement format from a Content-Type header.
+func statementFormat(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv":
+		return service.StatementCSV
+	case "application/qif", "application/x-qif":
+		return service.StatementQIF
+	case "application/x-ofx", "application/ofx":
+		return service.StatementOFX
+	}
+	return ""
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/logging"
 	"github.com/lavatee/subs/internal/model"
 )
 
@@ -17,6 +18,7 @@ import (
 // @Produce json
 // @Param user_id query string false "Фильтрация по ID пользователя"
 // @Param service_name query string false "Фильтрация по названию сервиса"
+// @Param include_archived query bool false "Включить архивные подписки"
 // @Success 200 {object} model.SubscriptionListResponse
 // @Failure 400 {object} model.ErrorResponse
 // @Failure 500 {object} model.ErrorResponse
@@ -31,7 +33,7 @@ func (e *Endpoint) GetUserSubscriptions(ctx *gin.Context) {
 	if userID != "" {
 		userUUID, err = uuid.Parse(userID)
 		if err != nil {
-			e.logger.Warnf("Invalid user ID format: %s", err.Error())
+			logging.FromContext(ctx, e.logger).Warnf("Invalid user ID format: %s", err.Error())
 			ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
 				Error: "Invalid user ID format",
 			})
@@ -39,9 +41,14 @@ func (e *Endpoint) GetUserSubscriptions(ctx *gin.Context) {
 		}
 	}
 
-	subscriptions, err := e.services.Subscriptions.GetUserSubscriptions(ctx, userUUID, serviceName)
+	includeArchived, ok := e.queryBool(ctx, "include_archived")
+	if !ok {
+		return
+	}
+
+	subscriptions, err := e.services.Subscriptions.GetUserSubscriptions(ctx, userUUID, serviceName, includeArchived)
 	if err != nil {
-		e.logger.Errorf("Failed to get subscriptions: %s", err.Error())
+		logging.FromContext(ctx, e.logger).Errorf("Failed to get subscriptions: %s", err.Error())
 		ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
 			Error: "Failed to get subscriptions",
 		})
@@ -54,7 +61,7 @@ func (e *Endpoint) GetUserSubscriptions(ctx *gin.Context) {
 }
 
 // @Summary Создание подписки
-// @Description Создание новой подписки пользователя
+// @Description Создание новой подписки пользователя. Если подписка выводит траты за пределы бюджета, в ответе возвращаются предупреждения
 // @Tags subscriptions
 // @Accept json
 // @Produce json
@@ -66,24 +73,25 @@ func (e *Endpoint) GetUserSubscriptions(ctx *gin.Context) {
 func (e *Endpoint) CreateSubscription(ctx *gin.Context) {
 	var req model.CreateSubscriptionRequest
 	if err := ctx.BindJSON(&req); err != nil {
-		e.logger.Warnf("Invalid request body: %s", err.Error())
+		logging.FromContext(ctx, e.logger).Warnf("Invalid request body: %s", err.Error())
 		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
 			Error: "Invalid request body",
 		})
 		return
 	}
 
-	subscription, err := e.services.Subscriptions.CreateSubscription(ctx, req)
+	subscription, warnings, err := e.services.Subscriptions.CreateSubscription(ctx, req)
 	if err != nil {
-		e.logger.Errorf("Failed to create subscription: %s", err.Error())
-		ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
-			Error: "Failed to create subscription",
+		logging.FromContext(ctx, e.logger).Errorf("Failed to create subscription: %s", err.Error())
+		ctx.JSON(errorStatus(err), model.ErrorResponse{
+			Error: fmt.Sprintf("Failed to create subscription: %s", err.Error()),
 		})
 		return
 	}
 
 	ctx.JSON(http.StatusCreated, model.SubscriptionResponse{
 		Subscription: subscription,
+		Warnings:     warnings,
 	})
 }
 
@@ -101,7 +109,7 @@ func (e *Endpoint) CreateSubscription(ctx *gin.Context) {
 func (e *Endpoint) GetSubscription(ctx *gin.Context) {
 	id, err := uuid.Parse(ctx.Param("id"))
 	if err != nil {
-		e.logger.Warnf("Invalid subscription ID format: %s", err.Error())
+		logging.FromContext(ctx, e.logger).Warnf("Invalid subscription ID format: %s", err.Error())
 		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
 			Error: "Invalid subscription ID format",
 		})
@@ -110,7 +118,7 @@ func (e *Endpoint) GetSubscription(ctx *gin.Context) {
 
 	subscription, err := e.services.Subscriptions.GetSubscription(ctx, id)
 	if err != nil {
-		e.logger.Errorf("Failed to get subscription: %s", err.Error())
+		logging.FromContext(ctx, e.logger).Errorf("Failed to get subscription: %s", err.Error())
 		ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
 			Error: fmt.Sprintf("Failed to get subscription: %s", err.Error()),
 		})
@@ -137,7 +145,7 @@ func (e *Endpoint) GetSubscription(ctx *gin.Context) {
 func (e *Endpoint) UpdateSubscription(ctx *gin.Context) {
 	id, err := uuid.Parse(ctx.Param("id"))
 	if err != nil {
-		e.logger.Warnf("Invalid subscription ID format: %s", err.Error())
+		logging.FromContext(ctx, e.logger).Warnf("Invalid subscription ID format: %s", err.Error())
 		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
 			Error: "Invalid subscription ID format",
 		})
@@ -146,17 +154,17 @@ func (e *Endpoint) UpdateSubscription(ctx *gin.Context) {
 
 	var req model.UpdateSubscriptionRequest
 	if err := ctx.BindJSON(&req); err != nil {
-		e.logger.Warnf("Invalid request body: %s", err.Error())
+		logging.FromContext(ctx, e.logger).Warnf("Invalid request body: %s", err.Error())
 		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
 			Error: "Invalid request body",
 		})
 		return
 	}
 
-	subscription, err := e.services.Subscriptions.UpdateSubscription(ctx, id, req)
+	subscription, warnings, err := e.services.Subscriptions.UpdateSubscription(ctx, id, req)
 	if err != nil {
-		e.logger.Errorf("Failed to update subscription: %s", err.Error())
-		ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
+		logging.FromContext(ctx, e.logger).Errorf("Failed to update subscription: %s", err.Error())
+		ctx.JSON(errorStatus(err), model.ErrorResponse{
 			Error: fmt.Sprintf("Failed to update subscription: %s", err.Error()),
 		})
 		return
@@ -164,6 +172,7 @@ func (e *Endpoint) UpdateSubscription(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, model.SubscriptionResponse{
 		Subscription: subscription,
+		Warnings:     warnings,
 	})
 }
 
@@ -181,7 +190,7 @@ func (e *Endpoint) UpdateSubscription(ctx *gin.Context) {
 func (e *Endpoint) DeleteSubscription(ctx *gin.Context) {
 	id, err := uuid.Parse(ctx.Param("id"))
 	if err != nil {
-		e.logger.Warnf("Invalid subscription ID format: %s", err.Error())
+		logging.FromContext(ctx, e.logger).Warnf("Invalid subscription ID format: %s", err.Error())
 		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
 			Error: "Invalid subscription ID format",
 		})
@@ -190,7 +199,7 @@ func (e *Endpoint) DeleteSubscription(ctx *gin.Context) {
 
 	err = e.services.Subscriptions.DeleteSubscription(ctx, id)
 	if err != nil {
-		e.logger.Errorf("Failed to delete subscription: %s", err.Error())
+		logging.FromContext(ctx, e.logger).Errorf("Failed to delete subscription: %s", err.Error())
 		ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
 			Error: fmt.Sprintf("Failed to delete subscription: %s", err.Error()),
 		})
@@ -209,6 +218,8 @@ func (e *Endpoint) DeleteSubscription(ctx *gin.Context) {
 // @Param service_name query string false "Фильтрация по названию сервиса"
 // @Param start_date query string false "Начальная дата (MM-YYYY)"
 // @Param end_date query string false "Конечная дата (MM-YYYY)"
+// @Param include_archived query bool false "Включить архивные подписки"
+// @Param explain query bool false "Вернуть подписки, из которых сложилась сумма, и признак расхождения с ней"
 // @Success 200 {object} model.TotalCostResponse
 // @Failure 400 {object} model.ErrorResponse
 // @Failure 500 {object} model.ErrorResponse
@@ -225,7 +236,7 @@ func (e *Endpoint) GetTotalCost(ctx *gin.Context) {
 	if userID != "" {
 		userUUID, err = uuid.Parse(userID)
 		if err != nil {
-			e.logger.Warnf("Invalid user ID format: %s", err.Error())
+			logging.FromContext(ctx, e.logger).Warnf("Invalid user ID format: %s", err.Error())
 			ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
 				Error: "Invalid user ID format",
 			})
@@ -237,7 +248,7 @@ func (e *Endpoint) GetTotalCost(ctx *gin.Context) {
 	if startDateStr != "" {
 		startDate, err = time.Parse("01-2006", startDateStr)
 		if err != nil {
-			e.logger.Warnf("Invalid start date format: %s", err.Error())
+			logging.FromContext(ctx, e.logger).Warnf("Invalid start date format: %s", err.Error())
 			ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
 				Error: "Invalid start date format, expected MM-YYYY",
 			})
@@ -248,7 +259,7 @@ func (e *Endpoint) GetTotalCost(ctx *gin.Context) {
 	if endDateStr != "" {
 		endDate, err = time.Parse("01-2006", endDateStr)
 		if err != nil {
-			e.logger.Warnf("Invalid end date format: %s", err.Error())
+			logging.FromContext(ctx, e.logger).Warnf("Invalid end date format: %s", err.Error())
 			ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
 				Error: "Invalid end date format, expected MM-YYYY",
 			})
@@ -256,9 +267,36 @@ func (e *Endpoint) GetTotalCost(ctx *gin.Context) {
 		}
 	}
 
-	total, err := e.services.Subscriptions.GetTotalCost(ctx, userUUID, serviceName, startDate, endDate)
+	includeArchived, ok := e.queryBool(ctx, "include_archived")
+	if !ok {
+		return
+	}
+
+	explain, ok := e.queryBool(ctx, "explain")
+	if !ok {
+		return
+	}
+
+	if explain {
+		total, explanation, err := e.services.Subscriptions.ExplainTotalCost(ctx, userUUID, serviceName, startDate, endDate, includeArchived)
+		if err != nil {
+			logging.FromContext(ctx, e.logger).Errorf("Failed to explain total cost: %s", err.Error())
+			ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
+				Error: "Failed to calculate total cost",
+			})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, model.TotalCostResponse{
+			TotalCost:   total,
+			Explanation: &explanation,
+		})
+		return
+	}
+
+	total, err := e.services.Subscriptions.GetTotalCost(ctx, userUUID, serviceName, startDate, endDate, includeArchived)
 	if err != nil {
-		e.logger.Errorf("Failed to calculate total cost: %s", err.Error())
+		logging.FromContext(ctx, e.logger).Errorf("Failed to calculate total cost: %s", err.Error())
 		ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
 			Error: "Failed to calculate total cost",
 		})
@@ -0,0 +1,208 @@
+package endpoint
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/logging"
+	"github.com/lavatee/subs/internal/model"
+)
+
+// @Summary Регистрация вебхука
+// @Description Регистрация URL для уведомлений о событиях подписок. Секрет для проверки подписи X-Webhook-Signature возвращается только в этом ответе
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param webhook body model.CreateWebhookRequest true "Данные о вебхуке"
+// @Success 201 {object} model.WebhookResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /webhooks [post]
+func (e *Endpoint) CreateWebhook(ctx *gin.Context) {
+	var req model.CreateWebhookRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		logging.FromContext(ctx, e.logger).Warnf("Invalid request body: %s", err.Error())
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: "Invalid request body",
+		})
+		return
+	}
+
+	hook, err := e.services.Webhooks.CreateWebhook(ctx, req)
+	if err != nil {
+		logging.FromContext(ctx, e.logger).Errorf("Failed to create webhook: %s", err.Error())
+		ctx.JSON(errorStatus(err), model.ErrorResponse{
+			Error: fmt.Sprintf("Failed to create webhook: %s", err.Error()),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, model.WebhookResponse{
+		Webhook: hook,
+	})
+}
+
+// @Summary Получение вебхуков
+// @Description Получение всех зарегистрированных вебхуков
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Success 200 {object} model.WebhookListResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /webhooks [get]
+func (e *Endpoint) GetWebhooks(ctx *gin.Context) {
+	hooks, err := e.services.Webhooks.GetWebhooks(ctx)
+	if err != nil {
+		logging.FromContext(ctx, e.logger).Errorf("Failed to get webhooks: %s", err.Error())
+		ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error: "Failed to get webhooks",
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.WebhookListResponse{
+		Webhooks: hooks,
+	})
+}
+
+// @Summary Получение вебхука
+// @Description Получение вебхука по ID
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "ID вебхука"
+// @Success 200 {object} model.WebhookResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /webhooks/{id} [get]
+func (e *Endpoint) GetWebhook(ctx *gin.Context) {
+	id, ok := e.pathUUID(ctx, "id", "webhook")
+	if !ok {
+		return
+	}
+
+	hook, err := e.services.Webhooks.GetWebhook(ctx, id)
+	if err != nil {
+		logging.FromContext(ctx, e.logger).Errorf("Failed to get webhook: %s", err.Error())
+		ctx.JSON(errorStatus(err), model.ErrorResponse{
+			Error: fmt.Sprintf("Failed to get webhook: %s", err.Error()),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.WebhookResponse{
+		Webhook: hook,
+	})
+}
+
+// @Summary Удаление вебхука
+// @Description Удаление вебхука и журнала его доставок
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "ID вебхука"
+// @Success 204
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /webhooks/{id} [delete]
+func (e *Endpoint) DeleteWebhook(ctx *gin.Context) {
+	id, ok := e.pathUUID(ctx, "id", "webhook")
+	if !ok {
+		return
+	}
+
+	if err := e.services.Webhooks.DeleteWebhook(ctx, id); err != nil {
+		logging.FromContext(ctx, e.logger).Errorf("Failed to delete webhook: %s", err.Error())
+		ctx.JSON(errorStatus(err), model.ErrorResponse{
+			Error: fmt.Sprintf("Failed to delete webhook: %s", err.Error()),
+		})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// @Summary Журнал доставок вебхука
+// @Description Последние доставки вебхука со статусом, числом попыток и последней ошибкой
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "ID вебхука"
+// @Success 200 {object} model.WebhookDeliveryListResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /webhooks/{id}/deliveries [get]
+func (e *Endpoint) GetWebhookDeliveries(ctx *gin.Context) {
+	id, ok := e.pathUUID(ctx, "id", "webhook")
+	if !ok {
+		return
+	}
+
+	deliveries, err := e.services.Webhooks.GetDeliveries(ctx, id)
+	if err != nil {
+		logging.FromContext(ctx, e.logger).Errorf("Failed to get webhook deliveries: %s", err.Error())
+		ctx.JSON(errorStatus(err), model.ErrorResponse{
+			Error: fmt.Sprintf("Failed to get webhook deliveries: %s", err.Error()),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.WebhookDeliveryListResponse{
+		Deliveries: deliveries,
+	})
+}
+
+// @Summary Повторная доставка
+// @Description Повторная отправка доставки вебхука с обнулением счетчика попыток
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "ID вебхука"
+// @Param delivery_id path string true "ID доставки"
+// @Success 202 {object} model.WebhookDeliveryResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 404 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router /webhooks/{id}/deliveries/{delivery_id}/redeliver [post]
+func (e *Endpoint) RedeliverWebhook(ctx *gin.Context) {
+	id, ok := e.pathUUID(ctx, "id", "webhook")
+	if !ok {
+		return
+	}
+	deliveryID, ok := e.pathUUID(ctx, "delivery_id", "delivery")
+	if !ok {
+		return
+	}
+
+	delivery, err := e.services.Webhooks.Redeliver(ctx, id, deliveryID)
+	if err != nil {
+		logging.FromContext(ctx, e.logger).Errorf("Failed to redeliver webhook: %s", err.Error())
+		ctx.JSON(errorStatus(err), model.ErrorResponse{
+			Error: fmt.Sprintf("Failed to redeliver webhook: %s", err.Error()),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, model.WebhookDeliveryResponse{
+		Delivery: delivery,
+	})
+}
+
+// pathUUID parses a UUID path parameter. On failure it writes a 400 response
+// and returns false.
+func (e *Endpoint) pathUUID(ctx *gin.Context, param, entity string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(ctx.Param(param))
+	if err != nil {
+		logging.FromContext(ctx, e.logger).Warnf("Invalid %s ID format: %s", entity, err.Error())
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: fmt.Sprintf("Invalid %s ID format", entity),
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
@@ -0,0 +1,63 @@
+// Package logging configures logrus and carries a request-scoped logger in
+// context.Context, so that every log line of a request shares its fields.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+type Config struct {
+	// Format is FormatJSON or FormatText.
+	Format string `mapstructure:"format"`
+	// Level is a logrus level name such as "debug" or "info".
+	Level string `mapstructure:"level"`
+}
+
+// Configure applies config to logger.
+func Configure(logger *logrus.Logger, config Config) error {
+	switch config.Format {
+	case FormatJSON:
+		logger.SetFormatter(&logrus.JSONFormatter{})
+	case FormatText:
+		logger.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+	default:
+		return fmt.Errorf("unknown log format %q", config.Format)
+	}
+	level, err := logrus.ParseLevel(config.Level)
+	if err != nil {
+		return err
+	}
+	logger.SetLevel(level)
+	return nil
+}
+
+type loggerKey struct{}
+
+// discard is the fallback of FromContext when none is given.
+var discard = &logrus.Logger{Out: io.Discard, Formatter: &logrus.TextFormatter{}, Hooks: logrus.LevelHooks{}, Level: logrus.PanicLevel}
+
+// WithLogger returns a copy of ctx carrying logger.
+func WithLogger(ctx context.Context, logger *logrus.Entry) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the logger stored in ctx by WithLogger or, outside of
+// a request, fallback. A nil fallback discards the log lines.
+func FromContext(ctx context.Context, fallback *logrus.Logger) *logrus.Entry {
+	if logger, ok := ctx.Value(loggerKey{}).(*logrus.Entry); ok {
+		return logger
+	}
+	if fallback == nil {
+		fallback = discard
+	}
+	return logrus.NewEntry(fallback)
+}
@@ -0,0 +1,57 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestConfigure(t *testing.T) {
+	logger := logrus.New()
+	if err := Configure(logger, Config{Format: FormatText, Level: "debug"}); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	if logger.GetLevel() != logrus.DebugLevel {
+		t.Fatalf("level: got %s", logger.GetLevel())
+	}
+	if err := Configure(logger, Config{Format: "yaml", Level: "info"}); err == nil {
+		t.Fatal("expected an error for an unknown format")
+	}
+	if err := Configure(logger, Config{Format: FormatJSON, Level: "loud"}); err == nil {
+		t.Fatal("expected an error for an unknown level")
+	}
+}
+
+func TestFromContextCarriesFields(t *testing.T) {
+	var out bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&out)
+	logger.SetFormatter(&logrus.JSONFormatter{})
+
+	ctx := WithLogger(context.Background(), logger.WithField("request_id", "abc"))
+	FromContext(ctx, nil).Info("handled")
+
+	var line map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &line); err != nil {
+		t.Fatalf("log line: %v", err)
+	}
+	if line["request_id"] != "abc" {
+		t.Fatalf("request_id: got %v", line["request_id"])
+	}
+}
+
+func TestFromContextFallback(t *testing.T) {
+	var out bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&out)
+
+	FromContext(context.Background(), logger).Info("worker")
+	if out.Len() == 0 {
+		t.Fatal("fallback logger not used")
+	}
+	// Without a fallback the line is dropped rather than panicking.
+	FromContext(context.Background(), nil).Error("dropped")
+}
@@ -0,0 +1,78 @@
+// Package metrics holds the Prometheus collectors of the service. They are
+// registered on Registry, which the /metrics endpoint serves.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "subs"
+
+// Registry holds every collector of the service, plus the Go runtime and
+// process collectors.
+var Registry = prometheus.NewRegistry()
+
+var (
+	HTTPRequests = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = promauto.With(Registry).NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	DBQueryDuration = promauto.With(Registry).NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Database query latency by repository method and outcome.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "status"})
+
+	ActiveSubscriptions = promauto.With(Registry).NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_subscriptions",
+		Help:      "Subscriptions active in the current month.",
+	})
+
+	ActiveUsers = promauto.With(Registry).NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_users",
+		Help:      "Users with at least one subscription active in the current month.",
+	})
+
+	MonthlyRecurringSpend = promauto.With(Registry).NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "monthly_recurring_spend",
+		Help:      "Sum of the monthly prices of the subscriptions active in the current month.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler serves Registry in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// Status is the status label of an operation that returned err.
+func Status(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
@@ -0,0 +1,79 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AnalyticsFilter narrows analytics to the subscriptions matched by the
+// filters of the total cost: a user, a service and the subscriptions active
+// within [StartDate, EndDate]. Zero values do not filter.
+type AnalyticsFilter struct {
+	UserID          uuid.UUID
+	ServiceName     string
+	StartDate       time.Time
+	EndDate         time.Time
+	IncludeArchived bool
+}
+
+type ServiceSpend struct {
+	ServiceName   string `json:"service_name" db:"service_name"`
+	Spend         int    `json:"spend" db:"spend"`
+	Subscriptions int    `json:"subscriptions" db:"subscriptions"`
+	Users         int    `json:"users" db:"users"`
+}
+
+type ServicePrice struct {
+	ServiceName   string  `json:"service_name" db:"service_name"`
+	AveragePrice  float64 `json:"average_price" db:"average_price"`
+	MinPrice      int     `json:"min_price" db:"min_price"`
+	MaxPrice      int     `json:"max_price" db:"max_price"`
+	Subscriptions int     `json:"subscriptions" db:"subscriptions"`
+}
+
+type ActiveSubscriptions struct {
+	Month  time.Time `json:"month" db:"month"`
+	Active int       `json:"active" db:"active"`
+}
+
+type SubscriptionChurn struct {
+	Month     time.Time `json:"month" db:"month"`
+	New       int       `json:"new" db:"new"`
+	Cancelled int       `json:"cancelled" db:"cancelled"`
+}
+
+// UserSpendStats summarizes the spend of each user, computed like the total
+// cost of their subscriptions.
+type UserSpendStats struct {
+	Users   int     `json:"users" db:"users"`
+	Median  float64 `json:"median" db:"median"`
+	Average float64 `json:"average" db:"average"`
+}
+
+// ActiveTotals describes the subscriptions active in one month.
+type ActiveTotals struct {
+	Subscriptions int `db:"subscriptions"`
+	Users         int `db:"users"`
+	MonthlySpend  int `db:"monthly_spend"`
+}
+
+type TopServicesResponse struct {
+	Services []ServiceSpend `json:"services"`
+}
+
+type AveragePricesResponse struct {
+	Services []ServicePrice `json:"services"`
+}
+
+type ActiveSubscriptionsResponse struct {
+	Months []ActiveSubscriptions `json:"months"`
+}
+
+type SubscriptionChurnResponse struct {
+	Months []SubscriptionChurn `json:"months"`
+}
+
+type UserSpendStatsResponse struct {
+	Stats UserSpendStats `json:"stats"`
+}
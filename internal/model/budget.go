@@ -0,0 +1,70 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	BudgetMonthly = "monthly"
+	BudgetYearly  = "yearly"
+)
+
+// Budget limits a user's spend per period, on one service or, when
+// ServiceName is empty, on all of them.
+type Budget struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	UserID      uuid.UUID `json:"user_id" db:"user_id"`
+	ServiceName string    `json:"service_name,omitempty" db:"service_name"`
+	Period      string    `json:"period" db:"period"`
+	Amount      int       `json:"amount" db:"amount"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// BudgetConsumption is a budget's spend over one period, computed the same
+// way as the total cost of subscriptions.
+type BudgetConsumption struct {
+	Budget      Budget    `json:"budget"`
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+	Spent       int       `json:"spent"`
+	Remaining   int       `json:"remaining"`
+	Percent     float64   `json:"percent"`
+	Exceeded    bool      `json:"exceeded"`
+}
+
+func NewBudgetConsumption(budget Budget, periodStart, periodEnd time.Time, spent int) BudgetConsumption {
+	return BudgetConsumption{
+		Budget:      budget,
+		PeriodStart: periodStart,
+		PeriodEnd:   periodEnd,
+		Spent:       spent,
+		Remaining:   max(budget.Amount-spent, 0),
+		Percent:     float64(spent) * 100 / float64(budget.Amount),
+		Exceeded:    spent > budget.Amount,
+	}
+}
+
+type CreateBudgetRequest struct {
+	UserID      uuid.UUID `json:"user_id" binding:"required"`
+	ServiceName string    `json:"service_name,omitempty"`
+	Period      string    `json:"period" binding:"required,oneof=monthly yearly"`
+	Amount      int       `json:"amount" binding:"required,min=1"`
+}
+
+type UpdateBudgetRequest struct {
+	Amount int `json:"amount" binding:"required,min=1"`
+}
+
+type BudgetResponse struct {
+	Budget Budget `json:"budget"`
+}
+
+type BudgetListResponse struct {
+	Budgets []Budget `json:"budgets"`
+}
+
+type BudgetConsumptionResponse struct {
+	Consumption BudgetConsumption `json:"consumption"`
+}
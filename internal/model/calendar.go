@@ -0,0 +1,22 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CalendarToken grants access to a user's calendar feed. Token is only known
+// when it is issued.
+type CalendarToken struct {
+	UserID    uuid.UUID `json:"user_id" db:"user_id"`
+	Token     string    `json:"token,omitempty" db:"-"`
+	TokenHash string    `json:"-" db:"token_hash"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type CalendarTokenResponse struct {
+	Token CalendarToken `json:"token"`
+	// FeedURL is the feed address with the token, to be added to a calendar app.
+	FeedURL string `json:"feed_url"`
+}
@@ -0,0 +1,70 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// CompareAgainstPrevious compares with the period of the same length
+	// right before; CompareAgainstYear with the same period a year earlier.
+	CompareAgainstPrevious = "previous"
+	CompareAgainstYear     = "year"
+)
+
+const (
+	SpendChangeAdded        = "added"
+	SpendChangeRemoved      = "removed"
+	SpendChangePriceChanged = "price_changed"
+)
+
+// CompareSpendRequest selects the subscriptions like the total cost and the
+// two periods to compare. The previous period is CompareStartDate to
+// CompareEndDate when set, otherwise it is derived from Against.
+type CompareSpendRequest struct {
+	UserID           uuid.UUID
+	ServiceName      string
+	IncludeArchived  bool
+	StartDate        time.Time
+	EndDate          time.Time
+	Against          string
+	CompareStartDate time.Time
+	CompareEndDate   time.Time
+}
+
+type SpendPeriod struct {
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+	Total     int       `json:"total"`
+}
+
+// SpendChange is a subscription counted in only one of the periods. A
+// subscription of a user and service replaced by one with another price is
+// reported once, as a price change; with several candidates, the one ending
+// closest to the start of the new subscription is taken as replaced. Prices
+// updated in place are not changes, as there is no price history.
+type SpendChange struct {
+	Kind                   string     `json:"kind"`
+	ServiceName            string     `json:"service_name"`
+	UserID                 uuid.UUID  `json:"user_id"`
+	PreviousSubscriptionID *uuid.UUID `json:"previous_subscription_id,omitempty"`
+	CurrentSubscriptionID  *uuid.UUID `json:"current_subscription_id,omitempty"`
+	PreviousPrice          int        `json:"previous_price"`
+	CurrentPrice           int        `json:"current_price"`
+	Delta                  int        `json:"delta"`
+}
+
+type SpendComparison struct {
+	Current  SpendPeriod `json:"current"`
+	Previous SpendPeriod `json:"previous"`
+	Delta    int         `json:"delta"`
+	// DeltaPercent is the delta relative to the previous total, omitted when
+	// that total is zero.
+	DeltaPercent *float64      `json:"delta_percent,omitempty"`
+	Changes      []SpendChange `json:"changes"`
+}
+
+type SpendComparisonResponse struct {
+	Comparison SpendComparison `json:"comparison"`
+}
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DuplicateGroup is a set of a user's subscriptions to the same service whose
+// periods overlap.
+type DuplicateGroup struct {
+	ServiceName   string         `json:"service_name"`
+	Subscriptions []Subscription `json:"subscriptions"`
+	OverlapStart  time.Time      `json:"overlap_start"`
+	// OverlapEnd is omitted while the overlap is ongoing.
+	OverlapEnd    *time.Time `json:"overlap_end,omitempty"`
+	OverlapMonths int        `json:"overlap_months"`
+	// WastedSpend is what was paid beyond the most expensive subscription in
+	// every overlapping month, up to the current month for ongoing overlaps.
+	WastedSpend int `json:"wasted_spend"`
+	// MonthlyWaste is what the overlap costs per month from the current month
+	// on, zero once it is over.
+	MonthlyWaste int `json:"monthly_waste"`
+}
+
+type DuplicatesResponse struct {
+	Duplicates  []DuplicateGroup `json:"duplicates"`
+	WastedSpend int              `json:"wasted_spend"`
+}
+
+// MergeDuplicatesRequest lists the subscriptions to collapse into KeepID, by
+// default the most expensive of them.
+type MergeDuplicatesRequest struct {
+	SubscriptionIDs []uuid.UUID `json:"subscription_ids" binding:"required,min=2"`
+	KeepID          *uuid.UUID  `json:"keep_id,omitempty"`
+}
+
+type MergeDuplicatesResponse struct {
+	Subscription Subscription `json:"subscription"`
+	Removed      []uuid.UUID  `json:"removed"`
+}
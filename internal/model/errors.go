@@ -0,0 +1,16 @@
+package model
+
+import "errors"
+
+var (
+	// ErrNotFound is wrapped by repositories when the requested entity does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidInput is wrapped by services when a request is well-formed but not acceptable.
+	ErrInvalidInput = errors.New("invalid input")
+	// ErrUnavailable is wrapped by services when a feature is disabled or not ready yet.
+	ErrUnavailable = errors.New("unavailable")
+	// ErrExpired is wrapped by services when a client token is too old to be honoured.
+	ErrExpired = errors.New("expired")
+	// ErrConflict is wrapped by services when the entity's state does not allow the change.
+	ErrConflict = errors.New("conflict")
+)
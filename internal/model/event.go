@@ -0,0 +1,52 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	EventSubscriptionCreated    = "subscription.created"
+	EventSubscriptionUpdated    = "subscription.updated"
+	EventSubscriptionDeleted    = "subscription.deleted"
+	EventSubscriptionArchived   = "subscription.archived"
+	EventSubscriptionEndingSoon = "subscription.ending_soon"
+	EventSubscriptionEnded      = "subscription.ended"
+	EventBudgetExceeded         = "budget.exceeded"
+)
+
+// Event is the envelope every subscription lifecycle notification is sent in.
+type Event struct {
+	ID           uuid.UUID     `json:"id"`
+	Type         string        `json:"type"`
+	CreatedAt    time.Time     `json:"created_at"`
+	Subscription *Subscription `json:"subscription,omitempty"`
+	// Budget is set on budget.exceeded, whose Subscription is the one whose
+	// change pushed spend over the limit.
+	Budget *BudgetConsumption `json:"budget,omitempty"`
+}
+
+func NewSubscriptionEvent(eventType string, sub Subscription) Event {
+	return Event{
+		ID:           uuid.New(),
+		Type:         eventType,
+		CreatedAt:    time.Now().UTC(),
+		Subscription: &sub,
+	}
+}
+
+func NewBudgetExceededEvent(consumption BudgetConsumption, sub Subscription) Event {
+	event := NewSubscriptionEvent(EventBudgetExceeded, sub)
+	event.Budget = &consumption
+	return event
+}
+
+// StreamEvent is an outbox event as sent to live stream clients.
+type StreamEvent struct {
+	Position EventPosition
+	Type     string
+	UserID   uuid.UUID
+	Payload  json.RawMessage
+}
@@ -0,0 +1,23 @@
+package model
+
+const (
+	HealthOK      = "ok"
+	HealthFailing = "failing"
+)
+
+// MigrationVersion is the state of the schema recorded by the migrator.
+type MigrationVersion struct {
+	Version uint `db:"version"`
+	Dirty   bool `db:"dirty"`
+}
+
+type HealthCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type HealthResponse struct {
+	Status string        `json:"status"`
+	Checks []HealthCheck `json:"checks,omitempty"`
+}
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxEvent is an Event stored in the outbox.
+type OutboxEvent struct {
+	Sequence    int64           `json:"sequence" db:"id"`
+	EventID     uuid.UUID       `json:"event_id" db:"event_id"`
+	AggregateID uuid.UUID       `json:"aggregate_id" db:"aggregate_id"`
+	Type        string          `json:"type" db:"event_type"`
+	Payload     json.RawMessage `json:"payload" db:"payload" swaggertype:"object"`
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+	// TxID is the writing transaction, see EventPosition.
+	TxID uint64 `json:"-" db:"txid"`
+}
+
+func (e OutboxEvent) Position() EventPosition {
+	return EventPosition{TxID: e.TxID, Sequence: e.Sequence}
+}
+
+// EventPosition orders outbox events for consumers that resume from the last
+// event they have seen. Sequences alone may commit out of order; positions
+// of committed events are never preceded by ones committed later.
+type EventPosition struct {
+	TxID     uint64
+	Sequence int64
+}
+
+func (p EventPosition) Less(other EventPosition) bool {
+	if p.TxID != other.TxID {
+		return p.TxID < other.TxID
+	}
+	return p.Sequence < other.Sequence
+}
+
+func (p EventPosition) IsZero() bool {
+	return p == EventPosition{}
+}
+
+func (p EventPosition) String() string {
+	return fmt.Sprintf("%d-%d", p.TxID, p.Sequence)
+}
+
+func ParseEventPosition(value string) (EventPosition, error) {
+	txID, sequence, ok := strings.Cut(value, "-")
+	if ok {
+		p := EventPosition{}
+		var txErr, seqErr error
+		p.TxID, txErr = strconv.ParseUint(txID, 10, 64)
+		p.Sequence, seqErr = strconv.ParseInt(sequence, 10, 64)
+		if txErr == nil && seqErr == nil && p.Sequence >= 0 {
+			return p, nil
+		}
+	}
+	return EventPosition{}, fmt.Errorf("%w: invalid event position %q", ErrInvalidInput, value)
+}
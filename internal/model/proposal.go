@@ -0,0 +1,78 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ProposalPending   = "pending"
+	ProposalAccepted  = "accepted"
+	ProposalDismissed = "dismissed"
+)
+
+// Transaction is one line of an uploaded bank statement. Charges have a
+// negative Amount.
+type Transaction struct {
+	Date        time.Time `json:"date"`
+	Description string    `json:"description"`
+	Amount      float64   `json:"amount"`
+}
+
+// SubscriptionProposal is a recurring charge found in a bank statement that
+// may be a subscription the user has not entered yet.
+type SubscriptionProposal struct {
+	ID             uuid.UUID  `json:"id" db:"id"`
+	UserID         uuid.UUID  `json:"user_id" db:"user_id"`
+	Merchant       string     `json:"merchant" db:"merchant"`
+	ServiceName    string     `json:"service_name" db:"service_name"`
+	Price          int        `json:"price" db:"price"`
+	StartDate      time.Time  `json:"start_date" db:"start_date"`
+	EndDate        *time.Time `json:"end_date,omitempty" db:"end_date"`
+	LastChargeDate time.Time  `json:"last_charge_date" db:"last_charge_date"`
+	Charges        int        `json:"charges" db:"charges"`
+	// Confidence is the share of intervals between charges that look monthly.
+	Confidence     float64    `json:"confidence" db:"confidence"`
+	Status         string     `json:"status" db:"status"`
+	SubscriptionID *uuid.UUID `json:"subscription_id,omitempty" db:"subscription_id"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+}
+
+// AcceptProposalRequest optionally overrides the proposed values. Dates use
+// the MM-YYYY format; an empty EndDate clears the proposed one.
+type AcceptProposalRequest struct {
+	ServiceName string  `json:"service_name,omitempty"`
+	Price       int     `json:"price,omitempty" binding:"min=0"`
+	StartDate   string  `json:"start_date,omitempty"`
+	EndDate     *string `json:"end_date,omitempty"`
+}
+
+type StatementRowError struct {
+	// Row is the 1-based line of the row, or of the start of the record, in
+	// the uploaded statement.
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+type StatementAnalysisResponse struct {
+	Transactions int                    `json:"transactions"`
+	Proposals    []SubscriptionProposal `json:"proposals"`
+	// Skipped lists the rows that could not be read, such as summary and
+	// footer rows; they are left out of the analysis.
+	Skipped []StatementRowError `json:"skipped"`
+}
+
+type ProposalResponse struct {
+	Proposal SubscriptionProposal `json:"proposal"`
+}
+
+type ProposalListResponse struct {
+	Proposals []SubscriptionProposal `json:"proposals"`
+}
+
+type AcceptProposalResponse struct {
+	Proposal     SubscriptionProposal `json:"proposal"`
+	Subscription Subscription         `json:"subscription"`
+	Warnings     []BudgetWarning      `json:"warnings,omitempty"`
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ReminderRenewal = "renewal"
+	ReminderExpiry  = "expiry"
+)
+
+// Reminder tells a user that a subscription renews or ends on DueDate,
+// LeadDays days ahead.
+type Reminder struct {
+	Kind         string       `json:"kind"`
+	DueDate      time.Time    `json:"due_date"`
+	LeadDays     int          `json:"lead_days"`
+	Subscription Subscription `json:"subscription"`
+}
+
+// UserContact holds where a user's reminders are sent. Empty fields disable
+// the corresponding channel.
+type UserContact struct {
+	UserID     uuid.UUID `json:"user_id" db:"user_id"`
+	Email      string    `json:"email" db:"email"`
+	WebhookURL string    `json:"webhook_url" db:"webhook_url"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type UpdateUserContactRequest struct {
+	Email      string `json:"email,omitempty" binding:"omitempty,email"`
+	WebhookURL string `json:"webhook_url,omitempty" binding:"omitempty,url"`
+}
+
+type UserContactResponse struct {
+	Contact UserContact `json:"contact"`
+}
@@ -33,6 +33,13 @@ type UpdateSubscriptionRequest struct {
 
 type SubscriptionResponse struct {
 	Subscription Subscription `json:"subscription"`
+	// Warnings lists the budgets this change pushed over their limit.
+	Warnings []BudgetWarning `json:"warnings,omitempty"`
+}
+
+type BudgetWarning struct {
+	Message     string            `json:"message"`
+	Consumption BudgetConsumption `json:"consumption"`
 }
 
 type SubscriptionListResponse struct {
@@ -41,8 +48,77 @@ type SubscriptionListResponse struct {
 
 type TotalCostResponse struct {
 	TotalCost int `json:"total_cost"`
+	// Explanation is only returned when requested.
+	Explanation *TotalCostExplanation `json:"explanation,omitempty"`
+}
+
+// TotalCostRule describes how the total cost is computed.
+const TotalCostRule = "Each subscription active in at least one month of the window adds its monthly price once."
+
+// TotalCostExplanation lists the subscriptions a total cost is made of.
+// ItemsTotal is the sum of their subtotals; Mismatch is set when it differs
+// from the total cost, which is read from the monthly spend ledger.
+type TotalCostExplanation struct {
+	Rule       string          `json:"rule"`
+	Items      []TotalCostItem `json:"items"`
+	ItemsTotal int             `json:"items_total"`
+	Mismatch   bool            `json:"mismatch"`
+}
+
+// TotalCostItem is one subscription's share of a total cost. FirstMonth to
+// LastMonth are the months of the window it is active in; without an end,
+// the window and open-ended subscriptions reach up to the current month.
+// ActiveMonths counts them for information only: by TotalCostRule the
+// subtotal is the price, however many months that is.
+type TotalCostItem struct {
+	Subscription Subscription `json:"subscription"`
+	FirstMonth   time.Time    `json:"first_month"`
+	LastMonth    time.Time    `json:"last_month"`
+	ActiveMonths int          `json:"active_months"`
+	Price        int          `json:"price"`
+	Subtotal     int          `json:"subtotal"`
+}
+
+type MonthlySpend struct {
+	Month  time.Time `json:"month" db:"month"`
+	Amount int       `json:"amount" db:"amount"`
+}
+
+type MonthlySpendResponse struct {
+	Months []MonthlySpend `json:"months"`
 }
 
 type ErrorResponse struct {
 	Error string `json:"error"`
 }
+
+// SubscriptionChange is the latest state of a subscription in a change feed
+// page, or a tombstone when it was deleted or archived.
+type SubscriptionChange struct {
+	ID           uuid.UUID     `json:"id"`
+	Deleted      bool          `json:"deleted"`
+	Subscription *Subscription `json:"subscription,omitempty"`
+}
+
+type SubscriptionChangesResponse struct {
+	Changes []SubscriptionChange `json:"changes"`
+	// Next is the sync token to pass as since on the next call.
+	Next    string `json:"next"`
+	HasMore bool   `json:"has_more"`
+}
+
+type ImportRowError struct {
+	// Row is the 1-based line of the row in the uploaded file.
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+type ImportReport struct {
+	DryRun   bool             `json:"dry_run"`
+	Total    int              `json:"total"`
+	Valid    int              `json:"valid"`
+	Imported int              `json:"imported"`
+	Errors   []ImportRowError `json:"errors"`
+	// Warnings lists the budgets the import pushed over their limit.
+	Warnings []BudgetWarning `json:"warnings,omitempty"`
+}
@@ -0,0 +1,76 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// WebhookEvents lists the event types a webhook can subscribe to.
+var WebhookEvents = []string{
+	EventSubscriptionCreated,
+	EventSubscriptionUpdated,
+	EventSubscriptionDeleted,
+	EventSubscriptionArchived,
+	EventSubscriptionEndingSoon,
+	EventSubscriptionEnded,
+	EventBudgetExceeded,
+}
+
+type Webhook struct {
+	ID     uuid.UUID `json:"id" db:"id"`
+	URL    string    `json:"url" db:"url"`
+	Events []string  `json:"events" db:"events"`
+	// Secret signs deliveries. It is only returned when the webhook is created.
+	Secret    string    `json:"secret,omitempty" db:"secret"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type WebhookDelivery struct {
+	ID             uuid.UUID       `json:"id" db:"id"`
+	WebhookID      uuid.UUID       `json:"webhook_id" db:"webhook_id"`
+	Event          string          `json:"event" db:"event"`
+	Payload        json.RawMessage `json:"payload" db:"payload" swaggertype:"object"`
+	Status         string          `json:"status" db:"status"`
+	Attempts       int             `json:"attempts" db:"attempts"`
+	LastError      *string         `json:"last_error,omitempty" db:"last_error"`
+	ResponseStatus *int            `json:"response_status,omitempty" db:"response_status"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at" db:"next_attempt_at"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty" db:"delivered_at"`
+}
+
+// PendingDelivery is a delivery claimed for sending together with its target.
+type PendingDelivery struct {
+	WebhookDelivery
+	URL    string `db:"url"`
+	Secret string `db:"secret"`
+}
+
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" binding:"required,url"`
+	Events []string `json:"events" binding:"required,min=1"`
+}
+
+type WebhookResponse struct {
+	Webhook Webhook `json:"webhook"`
+}
+
+type WebhookListResponse struct {
+	Webhooks []Webhook `json:"webhooks"`
+}
+
+type WebhookDeliveryResponse struct {
+	Delivery WebhookDelivery `json:"delivery"`
+}
+
+type WebhookDeliveryListResponse struct {
+	Deliveries []WebhookDelivery `json:"deliveries"`
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lavatee/subs/internal/model"
+)
+
+// AnalyticsPostgres aggregates subscriptions in SQL. It only reads, so it
+// is served by replicas.
+type AnalyticsPostgres struct {
+	reader DBTX
+}
+
+func NewAnalyticsPostgres(reader DBTX) *AnalyticsPostgres {
+	return &AnalyticsPostgres{
+		reader: reader,
+	}
+}
+
+// analyticsArgs are the arguments of totalCostWhere for filter.
+func analyticsArgs(filter model.AnalyticsFilter) []interface{} {
+	return totalCostArgs(filter.UserID, filter.ServiceName, filter.StartDate, filter.EndDate)
+}
+
+// GetTopServices returns the limit services with the highest spend.
+func (r *AnalyticsPostgres) GetTopServices(ctx context.Context, filter model.AnalyticsFilter, limit int) ([]model.ServiceSpend, error) {
+	query := fmt.Sprintf(`SELECT service_name, SUM(price) AS spend, COUNT(*) AS subscriptions, COUNT(DISTINCT user_id) AS users
+	FROM %s
+	WHERE %s
+	GROUP BY service_name
+	ORDER BY spend DESC, service_name
+	LIMIT $5`, subscriptionsSource(filter.IncludeArchived), totalCostWhere)
+
+	var services []model.ServiceSpend
+	if err := r.reader.SelectContext(ctx, &services, query, append(analyticsArgs(filter), limit)...); err != nil {
+		return nil, fmt.Errorf("failed to get top services: %w", err)
+	}
+	return services, nil
+}
+
+func (r *AnalyticsPostgres) GetAveragePrices(ctx context.Context, filter model.AnalyticsFilter) ([]model.ServicePrice, error) {
+	query := fmt.Sprintf(`SELECT service_name, ROUND(AVG(price), 2)::float8 AS average_price,
+		MIN(price) AS min_price, MAX(price) AS max_price, COUNT(*) AS subscriptions
+	FROM %s
+	WHERE %s
+	GROUP BY service_name
+	ORDER BY service_name`, subscriptionsSource(filter.IncludeArchived), totalCostWhere)
+
+	var prices []model.ServicePrice
+	if err := r.reader.SelectContext(ctx, &prices, query, analyticsArgs(filter)...); err != nil {
+		return nil, fmt.Errorf("failed to get average prices: %w", err)
+	}
+	return prices, nil
+}
+
+// GetActiveSubscriptions counts, for every month of the filter's window, the
+// subscriptions active in it. The window must be set.
+func (r *AnalyticsPostgres) GetActiveSubscriptions(ctx context.Context, filter model.AnalyticsFilter) ([]model.ActiveSubscriptions, error) {
+	query := fmt.Sprintf(`SELECT months.month, COUNT(subs.id) AS active
+	FROM generate_series(date_trunc('month', $3::timestamp), date_trunc('month', $4::timestamp), interval '1 month') AS months(month)
+	LEFT JOIN (SELECT id, start_date, end_date FROM %s WHERE %s) AS subs
+		ON subs.start_date <= months.month AND (subs.end_date IS NULL OR subs.end_date >= months.month)
+	GROUP BY months.month
+	ORDER BY months.month`, subscriptionsSource(filter.IncludeArchived), totalCostWhere)
+
+	var active []model.ActiveSubscriptions
+	if err := r.reader.SelectContext(ctx, &active, query, analyticsArgs(filter)...); err != nil {
+		return nil, fmt.Errorf("failed to get active subscriptions: %w", err)
+	}
+	return active, nil
+}
+
+// GetChurn counts, for every month of the filter's window, the subscriptions
+// starting in it and those whose last month it is. The window must be set.
+func (r *AnalyticsPostgres) GetChurn(ctx context.Context, filter model.AnalyticsFilter) ([]model.SubscriptionChurn, error) {
+	query := fmt.Sprintf(`WITH subs AS (
+		SELECT start_date, end_date FROM %s WHERE %s
+	)
+	SELECT months.month,
+		(SELECT COUNT(*) FROM subs WHERE date_trunc('month', subs.start_date) = months.month) AS new,
+		(SELECT COUNT(*) FROM subs WHERE date_trunc('month', subs.end_date) = months.month) AS cancelled
+	FROM generate_series(date_trunc('month', $3::timestamp), date_trunc('month', $4::timestamp), interval '1 month') AS months(month)
+	ORDER BY months.month`, subscriptionsSource(filter.IncludeArchived), totalCostWhere)
+
+	var churn []model.SubscriptionChurn
+	if err := r.reader.SelectContext(ctx, &churn, query, analyticsArgs(filter)...); err != nil {
+		return nil, fmt.Errorf("failed to get churn: %w", err)
+	}
+	return churn, nil
+}
+
+// GetUserSpendStats returns the median and average of the users' spend.
+func (r *AnalyticsPostgres) GetUserSpendStats(ctx context.Context, filter model.AnalyticsFilter) (model.UserSpendStats, error) {
+	query := fmt.Sprintf(`SELECT COUNT(*) AS users,
+		COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY spend), 0) AS median,
+		COALESCE(ROUND(AVG(spend), 2)::float8, 0) AS average
+	FROM (
+		SELECT user_id, SUM(price) AS spend
+		FROM %s
+		WHERE %s
+		GROUP BY user_id
+	) AS user_spend`, subscriptionsSource(filter.IncludeArchived), totalCostWhere)
+
+	var stats model.UserSpendStats
+	if err := r.reader.GetContext(ctx, &stats, query, analyticsArgs(filter)...); err != nil {
+		return model.UserSpendStats{}, fmt.Errorf("failed to get user spend stats: %w", err)
+	}
+	return stats, nil
+}
+
+// GetActiveTotals counts the live subscriptions active in month, their users
+// and the sum of their prices.
+func (r *AnalyticsPostgres) GetActiveTotals(ctx context.Context, month time.Time) (model.ActiveTotals, error) {
+	query := fmt.Sprintf(`SELECT COUNT(*) AS subscriptions, COUNT(DISTINCT user_id) AS users,
+		COALESCE(SUM(price), 0) AS monthly_spend
+	FROM %s
+	WHERE start_date <= $1 AND (end_date IS NULL OR end_date >= $1)`, subscriptionsTable)
+
+	var totals model.ActiveTotals
+	if err := r.reader.GetContext(ctx, &totals, query, month); err != nil {
+		return model.ActiveTotals{}, fmt.Errorf("failed to get active totals: %w", err)
+	}
+	return totals, nil
+}
@@ -0,0 +1,95 @@
+package repository_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/model"
+	"github.com/lavatee/subs/internal/repository"
+)
+
+func TestAnalyticsPostgres(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	truncate(t, db, "subscriptions", "monthly_spend")
+
+	repo := repository.NewRepository(&repository.Cluster{Primary: db}, repository.TxOptions{})
+	alice, bob := uuid.New(), uuid.New()
+	february := monthOf(2025, time.February)
+	subs := []model.Subscription{
+		{ServiceName: "Netflix", Price: 800, UserID: alice, StartDate: monthOf(2025, time.January)},
+		{ServiceName: "Netflix", Price: 600, UserID: bob, StartDate: monthOf(2025, time.January), EndDate: &february},
+		{ServiceName: "Spotify", Price: 200, UserID: alice, StartDate: monthOf(2025, time.February)},
+		{ServiceName: "Okko", Price: 100, UserID: bob, StartDate: monthOf(2025, time.March)},
+	}
+	for _, sub := range subs {
+		sub.ID = uuid.New()
+		sub.CreatedAt = time.Now().UTC()
+		if err := repo.Subscriptions.CreateSubscription(ctx, sub); err != nil {
+			t.Fatalf("CreateSubscription: %v", err)
+		}
+	}
+	window := model.AnalyticsFilter{StartDate: monthOf(2025, time.January), EndDate: monthOf(2025, time.April)}
+
+	top, err := repo.Analytics.GetTopServices(ctx, window, 2)
+	if err != nil {
+		t.Fatalf("GetTopServices: %v", err)
+	}
+	if got := fmt.Sprint(top); got != "[{Netflix 1400 2 2} {Spotify 200 1 1}]" {
+		t.Fatalf("GetTopServices: got %s", got)
+	}
+
+	prices, err := repo.Analytics.GetAveragePrices(ctx, model.AnalyticsFilter{ServiceName: "Netflix"})
+	if err != nil {
+		t.Fatalf("GetAveragePrices: %v", err)
+	}
+	if len(prices) != 1 || prices[0].AveragePrice != 700 || prices[0].MinPrice != 600 || prices[0].MaxPrice != 800 {
+		t.Fatalf("GetAveragePrices: got %+v", prices)
+	}
+
+	active, err := repo.Analytics.GetActiveSubscriptions(ctx, window)
+	if err != nil {
+		t.Fatalf("GetActiveSubscriptions: %v", err)
+	}
+	var counts []int
+	for _, month := range active {
+		counts = append(counts, month.Active)
+	}
+	if got := fmt.Sprint(counts); got != "[2 3 3 3]" {
+		t.Fatalf("GetActiveSubscriptions: got %s", got)
+	}
+
+	churn, err := repo.Analytics.GetChurn(ctx, window)
+	if err != nil {
+		t.Fatalf("GetChurn: %v", err)
+	}
+	var flows []string
+	for _, month := range churn {
+		flows = append(flows, fmt.Sprintf("+%d-%d", month.New, month.Cancelled))
+	}
+	if got := fmt.Sprint(flows); got != "[+2-0 +1-1 +1-0 +0-0]" {
+		t.Fatalf("GetChurn: got %s", got)
+	}
+
+	stats, err := repo.Analytics.GetUserSpendStats(ctx, window)
+	if err != nil {
+		t.Fatalf("GetUserSpendStats: %v", err)
+	}
+	if stats.Users != 2 || stats.Median != 850 || stats.Average != 850 {
+		t.Fatalf("GetUserSpendStats: got %+v", stats)
+	}
+	if stats, err := repo.Analytics.GetUserSpendStats(ctx, model.AnalyticsFilter{UserID: uuid.New()}); err != nil || stats.Users != 0 {
+		t.Fatalf("GetUserSpendStats of an unknown user: %+v, %v", stats, err)
+	}
+
+	totals, err := repo.Analytics.GetActiveTotals(ctx, monthOf(2025, time.March))
+	if err != nil {
+		t.Fatalf("GetActiveTotals: %v", err)
+	}
+	if totals != (model.ActiveTotals{Subscriptions: 3, Users: 2, MonthlySpend: 1100}) {
+		t.Fatalf("GetActiveTotals: got %+v", totals)
+	}
+}
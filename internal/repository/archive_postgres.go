@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lavatee/subs/internal/model"
+)
+
+type ArchivePostgres struct {
+	db DBTX
+}
+
+func NewArchivePostgres(db DBTX) *ArchivePostgres {
+	return &ArchivePostgres{
+		db: db,
+	}
+}
+
+// ArchiveEnded moves up to limit subscriptions whose end_date is before
+// endedBefore into the archive table and returns them. Rows locked by
+// concurrent writers are skipped until the next run.
+func (r *ArchivePostgres) ArchiveEnded(ctx context.Context, endedBefore time.Time, limit int) ([]model.Subscription, error) {
+	query := fmt.Sprintf(`WITH moved AS (
+		DELETE FROM %s
+		WHERE id IN (
+			SELECT id FROM %s
+			WHERE end_date < $1
+			ORDER BY end_date
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, service_name, price, user_id, start_date, end_date, created_at
+	)
+	INSERT INTO %s (id, service_name, price, user_id, start_date, end_date, created_at)
+	SELECT id, service_name, price, user_id, start_date, end_date, created_at FROM moved
+	RETURNING id, service_name, price, user_id, start_date, end_date, created_at`, subscriptionsTable, subscriptionsTable, subscriptionsArchiveTable)
+
+	var moved []model.Subscription
+	if err := r.db.SelectContext(ctx, &moved, query, endedBefore, limit); err != nil {
+		return nil, fmt.Errorf("failed to archive subscriptions: %w", err)
+	}
+	return moved, nil
+}
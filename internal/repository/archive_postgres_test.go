@@ -0,0 +1,77 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/model"
+	"github.com/lavatee/subs/internal/repository"
+)
+
+func TestArchivePostgres(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	truncate(t, db, "subscriptions", "subscriptions_archive", "monthly_spend")
+
+	repo := repository.NewRepository(&repository.Cluster{Primary: db}, repository.TxOptions{})
+	user := uuid.New()
+	longAgo := monthOf(2020, time.June)
+	recently := monthOf(2025, time.June)
+	subs := []model.Subscription{
+		{ID: uuid.New(), ServiceName: "Netflix", Price: 100, UserID: user, StartDate: monthOf(2020, time.January), EndDate: &longAgo},
+		{ID: uuid.New(), ServiceName: "Netflix", Price: 20, UserID: user, StartDate: monthOf(2025, time.January), EndDate: &recently},
+		{ID: uuid.New(), ServiceName: "Spotify", Price: 3, UserID: user, StartDate: monthOf(2020, time.January)},
+	}
+	for _, sub := range subs {
+		sub.CreatedAt = time.Now().UTC()
+		if err := repo.Subscriptions.CreateSubscription(ctx, sub); err != nil {
+			t.Fatalf("CreateSubscription: %v", err)
+		}
+	}
+
+	archived, err := repo.Archive.ArchiveEnded(ctx, monthOf(2024, time.January), 10)
+	if err != nil {
+		t.Fatalf("ArchiveEnded: %v", err)
+	}
+	if len(archived) != 1 || archived[0].ID != subs[0].ID {
+		t.Fatalf("want the long-ended subscription archived, got %+v", archived)
+	}
+
+	if _, err := repo.Subscriptions.GetSubscription(ctx, subs[0].ID); err == nil {
+		t.Fatal("archived subscription is still in the live table")
+	}
+
+	for _, tt := range []struct {
+		includeArchived bool
+		wantCount       int
+		wantTotal       int
+	}{
+		{includeArchived: false, wantCount: 2, wantTotal: 23},
+		{includeArchived: true, wantCount: 3, wantTotal: 123},
+	} {
+		list, err := repo.Subscriptions.GetUserSubscriptions(ctx, user, "", tt.includeArchived)
+		if err != nil {
+			t.Fatalf("GetUserSubscriptions: %v", err)
+		}
+		if len(list) != tt.wantCount {
+			t.Fatalf("include_archived=%v: want %d subscriptions, got %d", tt.includeArchived, tt.wantCount, len(list))
+		}
+		total, err := repo.Subscriptions.GetTotalCost(ctx, user, "", time.Time{}, time.Time{}, tt.includeArchived)
+		if err != nil {
+			t.Fatalf("GetTotalCost: %v", err)
+		}
+		if total != tt.wantTotal {
+			t.Fatalf("include_archived=%v: want total %d, got %d", tt.includeArchived, tt.wantTotal, total)
+		}
+	}
+
+	archived, err = repo.Archive.ArchiveEnded(ctx, monthOf(2024, time.January), 10)
+	if err != nil {
+		t.Fatalf("ArchiveEnded: %v", err)
+	}
+	if len(archived) != 0 {
+		t.Fatalf("second run archived %d subscriptions, want 0", len(archived))
+	}
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/model"
+)
+
+type BudgetsPostgres struct {
+	db DBTX
+}
+
+func NewBudgetsPostgres(db DBTX) *BudgetsPostgres {
+	return &BudgetsPostgres{
+		db: db,
+	}
+}
+
+func (r *BudgetsPostgres) CreateBudget(ctx context.Context, budget model.Budget) error {
+	query := fmt.Sprintf(`INSERT INTO %s (id, user_id, service_name, period, amount, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6)`, budgetsTable)
+	_, err := r.db.ExecContext(ctx, query, budget.ID, budget.UserID, budget.ServiceName, budget.Period, budget.Amount, budget.CreatedAt)
+	return err
+}
+
+// GetBudgets returns the budgets of userID, or all budgets for uuid.Nil.
+func (r *BudgetsPostgres) GetBudgets(ctx context.Context, userID uuid.UUID) ([]model.Budget, error) {
+	var userIDArg interface{} = userID
+	if userID == uuid.Nil {
+		userIDArg = nil
+	}
+
+	query := fmt.Sprintf(`SELECT id, user_id, service_name, period, amount, created_at
+	FROM %s
+	WHERE ($1::uuid IS NULL OR user_id = $1)
+	ORDER BY created_at, id`, budgetsTable)
+
+	var budgets []model.Budget
+	if err := r.db.SelectContext(ctx, &budgets, query, userIDArg); err != nil {
+		return nil, fmt.Errorf("failed to get budgets: %w", err)
+	}
+	return budgets, nil
+}
+
+func (r *BudgetsPostgres) GetBudget(ctx context.Context, id uuid.UUID) (model.Budget, error) {
+	query := fmt.Sprintf(`SELECT id, user_id, service_name, period, amount, created_at FROM %s WHERE id = $1`, budgetsTable)
+	var budget model.Budget
+	if err := r.db.GetContext(ctx, &budget, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Budget{}, fmt.Errorf("budget %s: %w", id, model.ErrNotFound)
+		}
+		return model.Budget{}, err
+	}
+	return budget, nil
+}
+
+func (r *BudgetsPostgres) UpdateBudget(ctx context.Context, budget model.Budget) error {
+	query := fmt.Sprintf(`UPDATE %s SET amount = $2 WHERE id = $1`, budgetsTable)
+	result, err := r.db.ExecContext(ctx, query, budget.ID, budget.Amount)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("budget %s: %w", budget.ID, model.ErrNotFound)
+	}
+	return nil
+}
+
+func (r *BudgetsPostgres) DeleteBudget(ctx context.Context, id uuid.UUID) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id = $1`, budgetsTable)
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("budget %s: %w", id, model.ErrNotFound)
+	}
+	return nil
+}
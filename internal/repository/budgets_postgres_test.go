@@ -0,0 +1,58 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/model"
+	"github.com/lavatee/subs/internal/repository"
+)
+
+func TestBudgetsPostgres(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	truncate(t, db, "budgets")
+
+	repo := repository.NewBudgetsPostgres(db)
+	userA, userB := uuid.New(), uuid.New()
+	budgets := []model.Budget{
+		{ID: uuid.New(), UserID: userA, Period: model.BudgetMonthly, Amount: 1000, CreatedAt: time.Now().UTC()},
+		{ID: uuid.New(), UserID: userA, ServiceName: "Netflix", Period: model.BudgetYearly, Amount: 5000, CreatedAt: time.Now().UTC().Add(time.Second)},
+		{ID: uuid.New(), UserID: userB, Period: model.BudgetMonthly, Amount: 300, CreatedAt: time.Now().UTC()},
+	}
+	for _, budget := range budgets {
+		if err := repo.CreateBudget(ctx, budget); err != nil {
+			t.Fatalf("CreateBudget: %v", err)
+		}
+	}
+
+	got, err := repo.GetBudgets(ctx, userA)
+	if err != nil || len(got) != 2 || got[0].ID != budgets[0].ID || got[1].ServiceName != "Netflix" {
+		t.Fatalf("GetBudgets(userA): got %+v, %v", got, err)
+	}
+	if all, err := repo.GetBudgets(ctx, uuid.Nil); err != nil || len(all) != 3 {
+		t.Fatalf("GetBudgets(all): got %d, %v", len(all), err)
+	}
+
+	updated := budgets[0]
+	updated.Amount = 1500
+	if err := repo.UpdateBudget(ctx, updated); err != nil {
+		t.Fatalf("UpdateBudget: %v", err)
+	}
+	if budget, err := repo.GetBudget(ctx, updated.ID); err != nil || budget.Amount != 1500 {
+		t.Fatalf("GetBudget after update: got %+v, %v", budget, err)
+	}
+
+	if err := repo.DeleteBudget(ctx, updated.ID); err != nil {
+		t.Fatalf("DeleteBudget: %v", err)
+	}
+	if _, err := repo.GetBudget(ctx, updated.ID); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("GetBudget after delete: want ErrNotFound, got %v", err)
+	}
+	if err := repo.DeleteBudget(ctx, updated.ID); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("DeleteBudget of a missing id: want ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/model"
+)
+
+type CalendarTokensPostgres struct {
+	db DBTX
+}
+
+func NewCalendarTokensPostgres(db DBTX) *CalendarTokensPostgres {
+	return &CalendarTokensPostgres{
+		db: db,
+	}
+}
+
+// UpsertCalendarToken stores the user's token, replacing any previous one.
+func (r *CalendarTokensPostgres) UpsertCalendarToken(ctx context.Context, token model.CalendarToken) error {
+	query := fmt.Sprintf(`INSERT INTO %s (user_id, token_hash, created_at)
+	VALUES ($1, $2, $3)
+	ON CONFLICT (user_id) DO UPDATE
+	SET token_hash = EXCLUDED.token_hash, created_at = EXCLUDED.created_at`, calendarTokensTable)
+	_, err := r.db.ExecContext(ctx, query, token.UserID, token.TokenHash, token.CreatedAt)
+	return err
+}
+
+func (r *CalendarTokensPostgres) GetCalendarToken(ctx context.Context, userID uuid.UUID) (model.CalendarToken, error) {
+	query := fmt.Sprintf(`SELECT user_id, token_hash, created_at FROM %s WHERE user_id = $1`, calendarTokensTable)
+	var token model.CalendarToken
+	if err := r.db.GetContext(ctx, &token, query, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.CalendarToken{}, fmt.Errorf("calendar token of user %s: %w", userID, model.ErrNotFound)
+		}
+		return model.CalendarToken{}, err
+	}
+	return token, nil
+}
+
+func (r *CalendarTokensPostgres) DeleteCalendarToken(ctx context.Context, userID uuid.UUID) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE user_id = $1`, calendarTokensTable)
+	result, err := r.db.ExecContext(ctx, query, userID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("calendar token of user %s: %w", userID, model.ErrNotFound)
+	}
+	return nil
+}
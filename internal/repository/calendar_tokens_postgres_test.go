@@ -0,0 +1,41 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/model"
+	"github.com/lavatee/subs/internal/repository"
+)
+
+func TestCalendarTokensPostgres(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	truncate(t, db, "calendar_tokens")
+
+	repo := repository.NewCalendarTokensPostgres(db)
+	userID := uuid.New()
+	if _, err := repo.GetCalendarToken(ctx, userID); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("GetCalendarToken before issuing: want ErrNotFound, got %v", err)
+	}
+
+	for _, hash := range []string{"first", "second"} {
+		if err := repo.UpsertCalendarToken(ctx, model.CalendarToken{UserID: userID, TokenHash: hash, CreatedAt: time.Now().UTC()}); err != nil {
+			t.Fatalf("UpsertCalendarToken(%s): %v", hash, err)
+		}
+	}
+	token, err := repo.GetCalendarToken(ctx, userID)
+	if err != nil || token.TokenHash != "second" {
+		t.Fatalf("a reissued token should replace the old one: %+v, %v", token, err)
+	}
+
+	if err := repo.DeleteCalendarToken(ctx, userID); err != nil {
+		t.Fatalf("DeleteCalendarToken: %v", err)
+	}
+	if err := repo.DeleteCalendarToken(ctx, userID); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("DeleteCalendarToken twice: want ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+const replicaPingTimeout = 2 * time.Second
+
+// Cluster is a primary database plus optional read replicas. Writes and
+// transactions always use the primary; Reader spreads read-only queries over
+// healthy replicas and falls back to the primary when none is available.
+// Replicas may lag, so reads that must see a just-committed write belong in
+// a transaction.
+type Cluster struct {
+	Primary  *sqlx.DB
+	replicas []*replica
+	next     atomic.Uint64
+}
+
+type replica struct {
+	db      *sqlx.DB
+	healthy atomic.Bool
+}
+
+// Reader returns a DBTX that routes queries to a replica.
+func (c *Cluster) Reader() DBTX {
+	if len(c.replicas) == 0 {
+		return c.Primary
+	}
+	return &replicaRouter{cluster: c}
+}
+
+// HealthyReplicas returns how many replicas currently receive reads.
+func (c *Cluster) HealthyReplicas() int {
+	healthy := 0
+	for _, r := range c.replicas {
+		if r.healthy.Load() {
+			healthy++
+		}
+	}
+	return healthy
+}
+
+// RunHealthChecks pings every replica each interval until ctx is cancelled,
+// taking failing replicas out of rotation and putting recovered ones back.
+func (c *Cluster) RunHealthChecks(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.checkReplicas(ctx)
+		}
+	}
+}
+
+func (c *Cluster) checkReplicas(ctx context.Context) {
+	for _, r := range c.replicas {
+		pingCtx, cancel := context.WithTimeout(ctx, replicaPingTimeout)
+		r.healthy.Store(r.db.PingContext(pingCtx) == nil)
+		cancel()
+	}
+}
+
+// RegisterMetrics registers the connection pool stats of the primary and of
+// every replica, labelled db_name="primary" and "replica-N", and the number of
+// healthy replicas.
+func (c *Cluster) RegisterMetrics(reg prometheus.Registerer) error {
+	cs := []prometheus.Collector{
+		collectors.NewDBStatsCollector(c.Primary.DB, "primary"),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: "subs",
+			Name:      "db_healthy_replicas",
+			Help:      "Replicas currently receiving reads.",
+		}, func() float64 { return float64(c.HealthyReplicas()) }),
+	}
+	for i, r := range c.replicas {
+		cs = append(cs, collectors.NewDBStatsCollector(r.db.DB, fmt.Sprintf("replica-%d", i+1)))
+	}
+	for _, collector := range cs {
+		if err := reg.Register(collector); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Cluster) Close() error {
+	var errs []error
+	for _, r := range c.replicas {
+		errs = append(errs, r.db.Close())
+	}
+	errs = append(errs, c.Primary.Close())
+	return errors.Join(errs...)
+}
+
+// pickReplica returns the next healthy replica in round-robin order.
+func (c *Cluster) pickReplica() *replica {
+	n := uint64(len(c.replicas))
+	start := c.next.Add(1)
+	for i := uint64(0); i < n; i++ {
+		r := c.replicas[(start+i)%n]
+		if r.healthy.Load() {
+			return r
+		}
+	}
+	return nil
+}
+
+// replicaRouter runs each query on a healthy replica. When the replica turns
+// out to be unreachable, it is marked unhealthy and the query is retried on
+// the primary.
+type replicaRouter struct {
+	cluster *Cluster
+}
+
+func (r *replicaRouter) read(ctx context.Context, fn func(db *sqlx.DB) error) error {
+	rep := r.cluster.pickReplica()
+	if rep == nil {
+		return fn(r.cluster.Primary)
+	}
+	err := fn(rep.db)
+	if err == nil || ctx.Err() != nil || !isConnectionError(err) {
+		return err
+	}
+	rep.healthy.Store(false)
+	return fn(r.cluster.Primary)
+}
+
+func (r *replicaRouter) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	var rows *sql.Rows
+	err := r.read(ctx, func(db *sqlx.DB) (err error) {
+		rows, err = db.QueryContext(ctx, query, args...)
+		return err
+	})
+	return rows, err
+}
+
+func (r *replicaRouter) QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
+	var rows *sqlx.Rows
+	err := r.read(ctx, func(db *sqlx.DB) (err error) {
+		rows, err = db.QueryxContext(ctx, query, args...)
+		return err
+	})
+	return rows, err
+}
+
+func (r *replicaRouter) QueryRowxContext(ctx context.Context, query string, args ...interface{}) *sqlx.Row {
+	var row *sqlx.Row
+	r.read(ctx, func(db *sqlx.DB) error {
+		row = db.QueryRowxContext(ctx, query, args...)
+		return row.Err()
+	})
+	return row
+}
+
+func (r *replicaRouter) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return r.read(ctx, func(db *sqlx.DB) error {
+		return db.GetContext(ctx, dest, query, args...)
+	})
+}
+
+func (r *replicaRouter) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return r.read(ctx, func(db *sqlx.DB) error {
+		return db.SelectContext(ctx, dest, query, args...)
+	})
+}
+
+// Writes never go to a replica.
+func (r *replicaRouter) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return r.cluster.Primary.ExecContext(ctx, query, args...)
+}
+
+func (r *replicaRouter) NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
+	return r.cluster.Primary.NamedExecContext(ctx, query, arg)
+}
+
+func (r *replicaRouter) DriverName() string {
+	return r.cluster.Primary.DriverName()
+}
+
+func (r *replicaRouter) Rebind(query string) string {
+	return r.cluster.Primary.Rebind(query)
+}
+
+func (r *replicaRouter) BindNamed(query string, arg interface{}) (string, []interface{}, error) {
+	return r.cluster.Primary.BindNamed(query, arg)
+}
+
+func isConnectionError(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		// Class 08 is connection exceptions, class 57 covers a server that
+		// is shutting down or still starting up.
+		class := pqErr.Code.Class()
+		return class == "08" || class == "57"
+	}
+	return false
+}
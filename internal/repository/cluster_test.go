@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func newTestCluster(t *testing.T, replicas int) *Cluster {
+	t.Helper()
+	open := func() *sqlx.DB {
+		// sqlx.Open does not connect, so no server is needed.
+		db, err := sqlx.Open("postgres", "host=127.0.0.1 port=1 sslmode=disable")
+		if err != nil {
+			t.Fatalf("sqlx.Open: %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+		return db
+	}
+	cluster := &Cluster{Primary: open()}
+	for i := 0; i < replicas; i++ {
+		r := &replica{db: open()}
+		r.healthy.Store(true)
+		cluster.replicas = append(cluster.replicas, r)
+	}
+	return cluster
+}
+
+func TestClusterReaderWithoutReplicasIsPrimary(t *testing.T) {
+	cluster := newTestCluster(t, 0)
+	if cluster.Reader() != DBTX(cluster.Primary) {
+		t.Fatal("Reader without replicas must be the primary")
+	}
+}
+
+func TestPickReplicaSkipsUnhealthy(t *testing.T) {
+	cluster := newTestCluster(t, 3)
+	cluster.replicas[1].healthy.Store(false)
+
+	seen := map[*replica]int{}
+	for i := 0; i < 10; i++ {
+		seen[cluster.pickReplica()]++
+	}
+	if seen[cluster.replicas[1]] != 0 {
+		t.Fatal("unhealthy replica received reads")
+	}
+	if seen[cluster.replicas[0]] == 0 || seen[cluster.replicas[2]] == 0 {
+		t.Fatalf("reads are not spread over healthy replicas: %v", seen)
+	}
+
+	for _, r := range cluster.replicas {
+		r.healthy.Store(false)
+	}
+	if r := cluster.pickReplica(); r != nil {
+		t.Fatal("pickReplica returned a replica although none is healthy")
+	}
+}
+
+func TestReplicaRouterFallsBackToPrimary(t *testing.T) {
+	tests := []struct {
+		name          string
+		replicaErr    error
+		wantPrimary   bool
+		wantUnhealthy bool
+	}{
+		{name: "connection refused", replicaErr: &net.OpError{Op: "dial", Err: errors.New("connection refused")}, wantPrimary: true, wantUnhealthy: true},
+		{name: "server shutting down", replicaErr: &pq.Error{Code: "57P01"}, wantPrimary: true, wantUnhealthy: true},
+		{name: "query error", replicaErr: &pq.Error{Code: "42601"}, wantPrimary: false, wantUnhealthy: false},
+		{name: "success", replicaErr: nil, wantPrimary: false, wantUnhealthy: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := newTestCluster(t, 1)
+			router := &replicaRouter{cluster: cluster}
+
+			usedPrimary := false
+			err := router.read(context.Background(), func(db *sqlx.DB) error {
+				if db == cluster.Primary {
+					usedPrimary = true
+					return nil
+				}
+				return tt.replicaErr
+			})
+			if tt.wantPrimary && err != nil {
+				t.Fatalf("read after fallback: %v", err)
+			}
+			if !tt.wantPrimary && !errors.Is(err, tt.replicaErr) {
+				t.Fatalf("want replica error %v, got %v", tt.replicaErr, err)
+			}
+			if usedPrimary != tt.wantPrimary {
+				t.Fatalf("used primary: want %v, got %v", tt.wantPrimary, usedPrimary)
+			}
+			if healthy := cluster.replicas[0].healthy.Load(); healthy == tt.wantUnhealthy {
+				t.Fatalf("replica healthy: want %v, got %v", !tt.wantUnhealthy, healthy)
+			}
+		})
+	}
+}
+
+func TestRegisterMetricsCoversEveryPool(t *testing.T) {
+	cluster := newTestCluster(t, 2)
+	cluster.replicas[0].healthy.Store(false)
+	reg := prometheus.NewRegistry()
+	if err := cluster.RegisterMetrics(reg); err != nil {
+		t.Fatalf("RegisterMetrics: %v", err)
+	}
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+	pools := map[string]bool{}
+	var healthy float64
+	for _, family := range families {
+		switch family.GetName() {
+		case "go_sql_open_connections":
+			for _, m := range family.GetMetric() {
+				for _, label := range m.GetLabel() {
+					pools[label.GetValue()] = true
+				}
+			}
+		case "subs_db_healthy_replicas":
+			healthy = family.GetMetric()[0].GetGauge().GetValue()
+		}
+	}
+	if len(pools) != 3 || !pools["primary"] || !pools["replica-1"] || !pools["replica-2"] {
+		t.Fatalf("pools: got %v", pools)
+	}
+	if healthy != 1 {
+		t.Fatalf("healthy replicas: got %v, want 1", healthy)
+	}
+}
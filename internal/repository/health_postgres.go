@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lavatee/subs/internal/model"
+)
+
+// HealthPostgres answers readiness checks against the primary.
+type HealthPostgres struct {
+	db DBTX
+}
+
+func NewHealthPostgres(db DBTX) *HealthPostgres {
+	return &HealthPostgres{
+		db: db,
+	}
+}
+
+func (r *HealthPostgres) Ping(ctx context.Context) error {
+	var one int
+	return r.db.GetContext(ctx, &one, "SELECT 1")
+}
+
+// GetMigrationVersion returns the schema version the migrator last applied.
+func (r *HealthPostgres) GetMigrationVersion(ctx context.Context) (model.MigrationVersion, error) {
+	query := fmt.Sprintf(`SELECT version, dirty FROM %s LIMIT 1`, schemaMigrationsTable)
+	var version model.MigrationVersion
+	if err := r.db.GetContext(ctx, &version, query); err != nil {
+		return model.MigrationVersion{}, fmt.Errorf("failed to get migration version: %w", err)
+	}
+	return version, nil
+}
@@ -0,0 +1,25 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lavatee/subs/internal/repository"
+)
+
+func TestHealthPostgres(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	repo := repository.NewHealthPostgres(db)
+	if err := repo.Ping(ctx); err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+	version, err := repo.GetMigrationVersion(ctx)
+	if err != nil {
+		t.Fatalf("GetMigrationVersion: %v", err)
+	}
+	if version.Dirty || version.Version == 0 {
+		t.Fatalf("GetMigrationVersion after migrating: got %+v", version)
+	}
+}
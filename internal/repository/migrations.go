@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jmoiron/sqlx"
+	"github.com/lavatee/subs/schema"
+)
+
+// NewMigrator returns a migrator applying the embedded migrations to db. It
+// holds one connection of db until it is closed; closing it leaves db open.
+func NewMigrator(ctx context.Context, db *sqlx.DB) (*migrate.Migrate, error) {
+	src, err := iofs.New(schema.FS, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open embedded migrations: %w", err)
+	}
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get migration connection: %w", err)
+	}
+	driver, err := postgres.WithConnection(ctx, conn, &postgres.Config{})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create migrate driver: %w", err)
+	}
+	migrator, err := migrate.NewWithInstance("iofs", src, "postgres", driver)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migrator: %w", err)
+	}
+	return migrator, nil
+}
+
+// LatestMigration returns the highest version among the embedded migrations,
+// which the schema has once every migration is applied.
+func LatestMigration() (uint, error) {
+	src, err := iofs.New(schema.FS, ".")
+	if err != nil {
+		return 0, fmt.Errorf("failed to open embedded migrations: %w", err)
+	}
+	defer src.Close()
+	version, err := src.First()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read embedded migrations: %w", err)
+	}
+	for {
+		next, err := src.Next(version)
+		if errors.Is(err, fs.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to read embedded migrations: %w", err)
+		}
+		version = next
+	}
+}
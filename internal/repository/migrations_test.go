@@ -0,0 +1,56 @@
+package repository_test
+
+import (
+	"io/fs"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/lavatee/subs/internal/repository"
+	"github.com/lavatee/subs/schema"
+)
+
+var migrationName = regexp.MustCompile(`^(\d+)_\w+\.(up|down)\.sql$`)
+
+func TestEmbeddedMigrations(t *testing.T) {
+	files, err := fs.ReadDir(schema.FS, ".")
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	seen := map[string]bool{}
+	var highest uint64
+	for _, file := range files {
+		match := migrationName.FindStringSubmatch(file.Name())
+		if match == nil {
+			t.Fatalf("unexpected file %s among migrations", file.Name())
+		}
+		version, _ := strconv.ParseUint(match[1], 10, 64)
+		highest = max(highest, version)
+		content, err := fs.ReadFile(schema.FS, file.Name())
+		if err != nil {
+			t.Fatalf("ReadFile(%s): %v", file.Name(), err)
+		}
+		if strings.TrimSpace(string(content)) == "" {
+			t.Fatalf("%s is empty", file.Name())
+		}
+		seen[file.Name()] = true
+	}
+	for name := range seen {
+		pair := strings.Replace(name, ".up.", ".down.", 1)
+		if strings.HasSuffix(name, ".down.sql") {
+			pair = strings.Replace(name, ".down.", ".up.", 1)
+		}
+		if !seen[pair] {
+			t.Fatalf("%s has no matching %s", name, pair)
+		}
+	}
+
+	latest, err := repository.LatestMigration()
+	if err != nil {
+		t.Fatalf("LatestMigration: %v", err)
+	}
+	if uint64(latest) != highest {
+		t.Fatalf("LatestMigration: got %d, want %d", latest, highest)
+	}
+}
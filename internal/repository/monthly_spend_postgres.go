@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lavatee/subs/internal/model"
+)
+
+// MonthlySpendPostgres reads the monthly_spend ledger, which triggers on the
+// subscriptions table keep up to date. Subscription dates are month-granular,
+// so the ledger answers the same questions as a scan of subscriptions as long
+// as the queried window is month-aligned too.
+type MonthlySpendPostgres struct {
+	db     DBTX
+	reader DBTX
+}
+
+func NewMonthlySpendPostgres(db, reader DBTX) *MonthlySpendPostgres {
+	return &MonthlySpendPostgres{
+		db:     db,
+		reader: reader,
+	}
+}
+
+func (r *MonthlySpendPostgres) GetMonthlySpend(ctx context.Context, userID uuid.UUID, serviceName string, startDate, endDate time.Time) ([]model.MonthlySpend, error) {
+	var userIDArg interface{} = userID
+	if userID == uuid.Nil {
+		userIDArg = nil
+	}
+
+	var serviceNameArg interface{} = serviceName
+	if serviceName == "" {
+		serviceNameArg = nil
+	}
+
+	// Spend in a month is what started up to and including it minus what
+	// ended before it.
+	query := fmt.Sprintf(`WITH ledger AS (
+		SELECT month, SUM(started_amount) AS started_amount, SUM(ended_amount) AS ended_amount
+		FROM %s
+		WHERE ($1::uuid IS NULL OR user_id = $1)
+		AND ($2::text IS NULL OR service_name = $2)
+		AND month <= $4
+		GROUP BY month
+	)
+	SELECT months.month,
+	COALESCE((SELECT SUM(started_amount) FROM ledger WHERE ledger.month <= months.month), 0)
+	- COALESCE((SELECT SUM(ended_amount) FROM ledger WHERE ledger.month < months.month), 0) AS amount
+	FROM generate_series(date_trunc('month', $3::timestamp), date_trunc('month', $4::timestamp), interval '1 month') AS months(month)
+	ORDER BY months.month`, monthlySpendTable)
+
+	var spend []model.MonthlySpend
+	if err := r.reader.SelectContext(ctx, &spend, query, userIDArg, serviceNameArg, startDate, endDate); err != nil {
+		return nil, fmt.Errorf("failed to get monthly spend: %w", err)
+	}
+
+	return spend, nil
+}
+
+func (r *MonthlySpendPostgres) RebuildMonthlySpend(ctx context.Context) error {
+	db, ok := r.db.(*sqlx.DB)
+	if !ok {
+		return rebuildMonthlySpend(ctx, r.db)
+	}
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	if err := rebuildMonthlySpend(ctx, tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func rebuildMonthlySpend(ctx context.Context, db DBTX) error {
+	// Writers to subscriptions wait until the ledger is consistent again.
+	queries := []string{
+		fmt.Sprintf(`LOCK TABLE %s IN SHARE MODE`, subscriptionsTable),
+		fmt.Sprintf(`LOCK TABLE %s IN EXCLUSIVE MODE`, monthlySpendTable),
+		fmt.Sprintf(`DELETE FROM %s`, monthlySpendTable),
+		fmt.Sprintf(`INSERT INTO %s (user_id, service_name, month, started_amount, ended_amount)
+		SELECT user_id, service_name, month, SUM(started_amount), SUM(ended_amount)
+		FROM (
+			SELECT user_id, service_name, date_trunc('month', start_date) AS month, price AS started_amount, 0 AS ended_amount
+			FROM %s
+			UNION ALL
+			SELECT user_id, service_name, date_trunc('month', end_date), 0, price
+			FROM %s
+			WHERE end_date IS NOT NULL
+		) AS ledger
+		GROUP BY user_id, service_name, month`, monthlySpendTable, subscriptionsTable, subscriptionsTable),
+	}
+	for _, query := range queries {
+		if _, err := db.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("failed to rebuild monthly spend: %w", err)
+		}
+	}
+	return nil
+}
+
+// monthlySpendCovers reports whether a total over [startDate, endDate] can be
+// answered from the ledger. Zero dates mean an open bound.
+func monthlySpendCovers(startDate, endDate time.Time) bool {
+	if !isMonthStart(startDate) || !isMonthStart(endDate) {
+		return false
+	}
+	return startDate.IsZero() || endDate.IsZero() || !startDate.After(endDate)
+}
+
+func isMonthStart(t time.Time) bool {
+	if t.IsZero() {
+		return true
+	}
+	return t.Equal(time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location()))
+}
+
+// totalFromMonthlySpend is GetTotalCost computed from the ledger: everything
+// that started by endDate minus everything that ended before startDate.
+func totalFromMonthlySpend(ctx context.Context, db DBTX, userIDArg, serviceNameArg, startDateArg, endDateArg interface{}) (int, error) {
+	query := fmt.Sprintf(`SELECT
+	COALESCE(SUM(started_amount) FILTER (WHERE $4::timestamp IS NULL OR month <= $4), 0)
+	- COALESCE(SUM(ended_amount) FILTER (WHERE $3::timestamp IS NOT NULL AND month < $3), 0)
+	FROM %s
+	WHERE ($1::uuid IS NULL OR user_id = $1)
+	AND ($2::text IS NULL OR service_name = $2)`, monthlySpendTable)
+
+	var total int
+	if err := db.GetContext(ctx, &total, query, userIDArg, serviceNameArg, startDateArg, endDateArg); err != nil {
+		return 0, fmt.Errorf("failed to calculate total cost: %w", err)
+	}
+
+	return total, nil
+}
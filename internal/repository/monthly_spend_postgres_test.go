@@ -0,0 +1,95 @@
+package repository_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/model"
+	"github.com/lavatee/subs/internal/repository"
+)
+
+func monthOf(year int, m time.Month) time.Time {
+	return time.Date(year, m, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func TestMonthlySpendPostgres(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	truncate(t, db, "subscriptions", "monthly_spend")
+
+	repo := repository.NewRepository(&repository.Cluster{Primary: db}, repository.TxOptions{})
+	user := uuid.New()
+	march := monthOf(2025, time.March)
+	subs := []model.Subscription{
+		{ID: uuid.New(), ServiceName: "Netflix", Price: 100, UserID: user, StartDate: monthOf(2025, time.January), EndDate: &march},
+		{ID: uuid.New(), ServiceName: "Spotify", Price: 20, UserID: user, StartDate: march},
+	}
+	for _, sub := range subs {
+		sub.CreatedAt = time.Now().UTC()
+		if err := repo.Subscriptions.CreateSubscription(ctx, sub); err != nil {
+			t.Fatalf("CreateSubscription: %v", err)
+		}
+	}
+
+	assertSpend := func(t *testing.T, want string) {
+		t.Helper()
+		spend, err := repo.MonthlySpend.GetMonthlySpend(ctx, user, "", monthOf(2024, time.December), monthOf(2025, time.May))
+		if err != nil {
+			t.Fatalf("GetMonthlySpend: %v", err)
+		}
+		var amounts []int
+		for _, month := range spend {
+			amounts = append(amounts, month.Amount)
+		}
+		if got := fmt.Sprint(amounts); got != want {
+			t.Fatalf("want %s, got %s", want, got)
+		}
+	}
+
+	t.Run("follows inserts", func(t *testing.T) {
+		assertSpend(t, "[0 100 100 120 20 20]")
+	})
+
+	t.Run("follows updates and deletes", func(t *testing.T) {
+		updated := subs[1]
+		updated.Price = 30
+		updated.StartDate = monthOf(2025, time.April)
+		if err := repo.Subscriptions.UpdateSubscription(ctx, updated); err != nil {
+			t.Fatalf("UpdateSubscription: %v", err)
+		}
+		assertSpend(t, "[0 100 100 100 30 30]")
+
+		if err := repo.Subscriptions.DeleteSubscription(ctx, subs[0].ID); err != nil {
+			t.Fatalf("DeleteSubscription: %v", err)
+		}
+		assertSpend(t, "[0 0 0 0 30 30]")
+	})
+
+	t.Run("rebuild repairs drift", func(t *testing.T) {
+		if _, err := db.Exec("UPDATE monthly_spend SET started_amount = started_amount + 1000"); err != nil {
+			t.Fatalf("Failed to corrupt ledger: %v", err)
+		}
+		if err := repo.MonthlySpend.RebuildMonthlySpend(ctx); err != nil {
+			t.Fatalf("RebuildMonthlySpend: %v", err)
+		}
+		assertSpend(t, "[0 0 0 0 30 30]")
+	})
+
+	t.Run("unaligned windows fall back to a scan", func(t *testing.T) {
+		if _, err := db.Exec("UPDATE monthly_spend SET started_amount = started_amount + 1000"); err != nil {
+			t.Fatalf("Failed to corrupt ledger: %v", err)
+		}
+		defer repo.MonthlySpend.RebuildMonthlySpend(ctx)
+
+		total, err := repo.Subscriptions.GetTotalCost(ctx, user, "", monthOf(2025, time.April).Add(time.Hour), time.Time{}, false)
+		if err != nil {
+			t.Fatalf("GetTotalCost: %v", err)
+		}
+		if total != 30 {
+			t.Fatalf("want 30, got %d", total)
+		}
+	})
+}
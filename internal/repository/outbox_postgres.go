@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/model"
+	"github.com/lib/pq"
+)
+
+type OutboxPostgres struct {
+	db DBTX
+}
+
+func NewOutboxPostgres(db DBTX) *OutboxPostgres {
+	return &OutboxPostgres{
+		db: db,
+	}
+}
+
+func (r *OutboxPostgres) AddEvent(ctx context.Context, eventID, aggregateID uuid.UUID, eventType string, payload []byte) error {
+	query := fmt.Sprintf(`INSERT INTO %s (event_id, aggregate_id, event_type, payload)
+	VALUES ($1, $2, $3, $4)`, outboxTable)
+	_, err := r.db.ExecContext(ctx, query, eventID, aggregateID, eventType, payload)
+	return err
+}
+
+// AddEvents stores events in one COPY.
+func (r *OutboxPostgres) AddEvents(ctx context.Context, events []model.OutboxEvent) error {
+	rows := make([][]interface{}, 0, len(events))
+	for _, event := range events {
+		rows = append(rows, []interface{}{event.EventID, event.AggregateID, event.Type, string(event.Payload)})
+	}
+	return copyIn(ctx, r.db, outboxTable, []string{"event_id", "aggregate_id", "event_type", "payload"}, rows)
+}
+
+// AcquireDispatcherLease makes holder the only dispatcher for lease and
+// reports whether it is. The holder renews its own lease; others get it only
+// once it has expired.
+func (r *OutboxPostgres) AcquireDispatcherLease(ctx context.Context, holder uuid.UUID, lease time.Duration) (bool, error) {
+	query := fmt.Sprintf(`INSERT INTO %s (id, holder, expires_at)
+	VALUES (TRUE, $1, now() + make_interval(secs => $2))
+	ON CONFLICT (id) DO UPDATE SET holder = EXCLUDED.holder, expires_at = EXCLUDED.expires_at
+	WHERE %s.holder = EXCLUDED.holder OR %s.expires_at < now()
+	RETURNING holder`, outboxDispatcherTable, outboxDispatcherTable, outboxDispatcherTable)
+
+	var got uuid.UUID
+	err := r.db.GetContext(ctx, &got, query, holder, lease.Seconds())
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire outbox dispatcher lease: %w", err)
+	}
+	return true, nil
+}
+
+// ReleaseDispatcherLease gives up the lease of holder, if it has it.
+func (r *OutboxPostgres) ReleaseDispatcherLease(ctx context.Context, holder uuid.UUID) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE holder = $1`, outboxDispatcherTable)
+	if _, err := r.db.ExecContext(ctx, query, holder); err != nil {
+		return fmt.Errorf("failed to release outbox dispatcher lease: %w", err)
+	}
+	return nil
+}
+
+// GetPendingEvents returns up to limit undispatched events in sequence order.
+func (r *OutboxPostgres) GetPendingEvents(ctx context.Context, limit int) ([]model.OutboxEvent, error) {
+	query := fmt.Sprintf(`SELECT id, event_id, aggregate_id, event_type, payload, created_at
+	FROM %s
+	WHERE dispatched_at IS NULL
+	AND txid < pg_snapshot_xmin(pg_current_snapshot())
+	ORDER BY id
+	LIMIT $1`, outboxTable)
+
+	var events []model.OutboxEvent
+	if err := r.db.SelectContext(ctx, &events, query, limit); err != nil {
+		return nil, fmt.Errorf("failed to get outbox events: %w", err)
+	}
+	return events, nil
+}
+
+func (r *OutboxPostgres) MarkDispatched(ctx context.Context, sequences []int64) error {
+	if len(sequences) == 0 {
+		return nil
+	}
+	query := fmt.Sprintf(`UPDATE %s SET dispatched_at = now() WHERE id = ANY($1)`, outboxTable)
+	_, err := r.db.ExecContext(ctx, query, pq.Int64Array(sequences))
+	return err
+}
+
+// DeleteDispatchedBefore trims the outbox and returns how many events were
+// removed. The position of the last removed event is kept for
+// GetTrimmedPosition.
+func (r *OutboxPostgres) DeleteDispatchedBefore(ctx context.Context, before time.Time) (int, error) {
+	query := fmt.Sprintf(`WITH deleted AS (
+		DELETE FROM %s WHERE dispatched_at IS NOT NULL AND created_at < $1
+		RETURNING txid, id
+	), trimmed AS (
+		INSERT INTO %s (id, txid, sequence)
+		SELECT TRUE, txid, id FROM deleted
+		ORDER BY txid DESC, id DESC
+		LIMIT 1
+		ON CONFLICT (id) DO UPDATE SET txid = EXCLUDED.txid, sequence = EXCLUDED.sequence
+		WHERE (%s.txid, %s.sequence) < (EXCLUDED.txid, EXCLUDED.sequence)
+	)
+	SELECT count(*) FROM deleted`, outboxTable, outboxTrimmedTable, outboxTrimmedTable, outboxTrimmedTable)
+
+	var rows int
+	if err := r.db.GetContext(ctx, &rows, query, before); err != nil {
+		return 0, fmt.Errorf("failed to trim outbox: %w", err)
+	}
+	return rows, nil
+}
+
+// GetTrimmedPosition returns the position of the last event trimmed from the
+// outbox, or the zero position if none was.
+func (r *OutboxPostgres) GetTrimmedPosition(ctx context.Context) (model.EventPosition, error) {
+	query := fmt.Sprintf(`SELECT txid, sequence FROM %s`, outboxTrimmedTable)
+	var position struct {
+		TxID     uint64 `db:"txid"`
+		Sequence int64  `db:"sequence"`
+	}
+	err := r.db.GetContext(ctx, &position, query)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.EventPosition{}, nil
+	}
+	if err != nil {
+		return model.EventPosition{}, fmt.Errorf("failed to get trimmed outbox position: %w", err)
+	}
+	return model.EventPosition{TxID: position.TxID, Sequence: position.Sequence}, nil
+}
+
+// GetEventsAfter returns up to limit events following after in position
+// order. Events of transactions that may still be followed by earlier
+// positions are left for a later call.
+func (r *OutboxPostgres) GetEventsAfter(ctx context.Context, after model.EventPosition, limit int) ([]model.OutboxEvent, error) {
+	query := fmt.Sprintf(`SELECT id, event_id, aggregate_id, event_type, payload, created_at, txid
+	FROM %s
+	WHERE (txid, id) > ($1::text::xid8, $2)
+	AND txid < pg_snapshot_xmin(pg_current_snapshot())
+	ORDER BY txid, id
+	LIMIT $3`, outboxTable)
+
+	var events []model.OutboxEvent
+	if err := r.db.SelectContext(ctx, &events, query, strconv.FormatUint(after.TxID, 10), after.Sequence, limit); err != nil {
+		return nil, fmt.Errorf("failed to get outbox events: %w", err)
+	}
+	return events, nil
+}
+
+// GetLastEvents returns the limit most recent events readable by
+// GetEventsAfter, oldest first.
+func (r *OutboxPostgres) GetLastEvents(ctx context.Context, limit int) ([]model.OutboxEvent, error) {
+	query := fmt.Sprintf(`SELECT * FROM (
+		SELECT id, event_id, aggregate_id, event_type, payload, created_at, txid
+		FROM %s
+		WHERE txid < pg_snapshot_xmin(pg_current_snapshot())
+		ORDER BY txid DESC, id DESC
+		LIMIT $1
+	) AS last_events
+	ORDER BY txid, id`, outboxTable)
+
+	var events []model.OutboxEvent
+	if err := r.db.SelectContext(ctx, &events, query, limit); err != nil {
+		return nil, fmt.Errorf("failed to get outbox events: %w", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,112 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/model"
+	"github.com/lavatee/subs/internal/repository"
+)
+
+func TestOutboxPostgres(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	truncate(t, db, "outbox", "outbox_dispatcher", "outbox_trimmed")
+
+	repo := repository.NewRepository(&repository.Cluster{Primary: db}, repository.TxOptions{})
+	aggregate := uuid.New()
+	for _, eventType := range []string{"subscription.created", "subscription.updated"} {
+		if err := repo.Outbox.AddEvent(ctx, uuid.New(), aggregate, eventType, []byte(`{}`)); err != nil {
+			t.Fatalf("AddEvent: %v", err)
+		}
+	}
+
+	dispatcher, other := uuid.New(), uuid.New()
+	if leased, err := repo.Outbox.AcquireDispatcherLease(ctx, dispatcher, time.Minute); err != nil || !leased {
+		t.Fatalf("AcquireDispatcherLease: %v, %v", leased, err)
+	}
+	if leased, err := repo.Outbox.AcquireDispatcherLease(ctx, other, time.Minute); err != nil || leased {
+		t.Fatalf("second dispatcher got the lease: %v, %v", leased, err)
+	}
+	if leased, err := repo.Outbox.AcquireDispatcherLease(ctx, dispatcher, time.Minute); err != nil || !leased {
+		t.Fatalf("renewing the lease: %v, %v", leased, err)
+	}
+
+	events, err := repo.Outbox.GetPendingEvents(ctx, 10)
+	if err != nil {
+		t.Fatalf("GetPendingEvents: %v", err)
+	}
+	if len(events) != 2 || events[0].Type != "subscription.created" || events[0].Sequence >= events[1].Sequence {
+		t.Fatalf("GetPendingEvents returned %+v", events)
+	}
+	if err := repo.Outbox.MarkDispatched(ctx, []int64{events[0].Sequence}); err != nil {
+		t.Fatalf("MarkDispatched: %v", err)
+	}
+
+	if err := repo.Outbox.ReleaseDispatcherLease(ctx, dispatcher); err != nil {
+		t.Fatalf("ReleaseDispatcherLease: %v", err)
+	}
+	if leased, err := repo.Outbox.AcquireDispatcherLease(ctx, other, time.Minute); err != nil || !leased {
+		t.Fatalf("lease after release: %v, %v", leased, err)
+	}
+
+	events, err = repo.Outbox.GetPendingEvents(ctx, 10)
+	if err != nil {
+		t.Fatalf("GetPendingEvents: %v", err)
+	}
+	if len(events) != 1 || events[0].Type != "subscription.updated" {
+		t.Fatalf("want only the undispatched event, got %+v", events)
+	}
+
+	if trimmed, err := repo.Outbox.GetTrimmedPosition(ctx); err != nil || !trimmed.IsZero() {
+		t.Fatalf("GetTrimmedPosition before trimming: %v, %v", trimmed, err)
+	}
+	deleted, err := repo.Outbox.DeleteDispatchedBefore(ctx, time.Now().UTC().Add(time.Hour))
+	if err != nil || deleted != 1 {
+		t.Fatalf("DeleteDispatchedBefore: want 1, got %d, %v", deleted, err)
+	}
+	trimmed, err := repo.Outbox.GetTrimmedPosition(ctx)
+	if err != nil || trimmed.IsZero() || trimmed.Sequence >= events[0].Sequence {
+		t.Fatalf("GetTrimmedPosition after trimming: %v, %v", trimmed, err)
+	}
+}
+
+func TestOutboxPostgresPositions(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	truncate(t, db, "outbox")
+
+	repo := repository.NewOutboxPostgres(db)
+	if err := repo.AddEvent(ctx, uuid.New(), uuid.New(), "subscription.created", []byte(`{}`)); err != nil {
+		t.Fatalf("AddEvent: %v", err)
+	}
+	batch := []model.OutboxEvent{
+		{EventID: uuid.New(), AggregateID: uuid.New(), Type: "subscription.created", Payload: []byte(`{"n":1}`)},
+		{EventID: uuid.New(), AggregateID: uuid.New(), Type: "subscription.created", Payload: []byte(`{"n":2}`)},
+	}
+	if err := repo.AddEvents(ctx, batch); err != nil {
+		t.Fatalf("AddEvents: %v", err)
+	}
+
+	last, err := repo.GetLastEvents(ctx, 2)
+	if err != nil || len(last) != 2 || !last[0].Position().Less(last[1].Position()) {
+		t.Fatalf("GetLastEvents: got %+v, %v", last, err)
+	}
+
+	all, err := repo.GetEventsAfter(ctx, model.EventPosition{}, 10)
+	if err != nil || len(all) != 3 {
+		t.Fatalf("GetEventsAfter(zero): got %d, %v", len(all), err)
+	}
+	if all[2].Position() != last[1].Position() {
+		t.Fatalf("newest event: GetEventsAfter has %s, GetLastEvents has %s", all[2].Position(), last[1].Position())
+	}
+	rest, err := repo.GetEventsAfter(ctx, all[0].Position(), 10)
+	if err != nil || len(rest) != 2 || rest[0].Sequence != all[1].Sequence {
+		t.Fatalf("GetEventsAfter(first): got %+v, %v", rest, err)
+	}
+	if string(rest[1].Payload) != `{"n": 2}` || rest[1].EventID != batch[1].EventID {
+		t.Fatalf("event stored by AddEvents: got %+v", rest[1])
+	}
+}
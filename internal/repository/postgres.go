@@ -1,13 +1,30 @@
 package repository
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 const (
-	subscriptionsTable = "subscriptions"
+	subscriptionsTable        = "subscriptions"
+	monthlySpendTable         = "monthly_spend"
+	subscriptionsArchiveTable = "subscriptions_archive"
+	webhooksTable             = "webhooks"
+	webhookDeliveriesTable    = "webhook_deliveries"
+	webhookScansTable         = "webhook_scans"
+	outboxTable               = "outbox"
+	outboxDispatcherTable     = "outbox_dispatcher"
+	outboxTrimmedTable        = "outbox_trimmed"
+	userContactsTable         = "user_contacts"
+	remindersSentTable        = "reminders_sent"
+	budgetsTable              = "budgets"
+	proposalsTable            = "subscription_proposals"
+	calendarTokensTable       = "calendar_tokens"
+	schemaMigrationsTable     = "schema_migrations"
 )
 
 type PostgresConfig struct {
@@ -19,13 +36,80 @@ type PostgresConfig struct {
 	SSLMode  string
 }
 
-func NewPostgresDB(config PostgresConfig) (*sqlx.DB, error) {
-	db, err := sqlx.Open("postgres", fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s", config.Host, config.Port, config.User, config.Password, config.DBName, config.SSLMode))
+// DBTX is implemented by both *sqlx.DB and *sqlx.Tx, so Postgres repositories
+// work the same way inside and outside a transaction.
+type DBTX interface {
+	sqlx.ExtContext
+	NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error)
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+}
+
+// NewPostgresDB opens the primary and one pool per replica. The primary must be
+// reachable; replicas that are down start out of rotation until a health
+// check sees them again.
+func NewPostgresDB(primary PostgresConfig, replicas ...PostgresConfig) (*Cluster, error) {
+	db, err := openPostgres(primary)
 	if err != nil {
 		return nil, err
 	}
 	if err := db.Ping(); err != nil {
+		db.Close()
 		return nil, err
 	}
-	return db, nil
+	cluster := &Cluster{Primary: db}
+	for _, config := range replicas {
+		replicaDB, err := openPostgres(config)
+		if err != nil {
+			cluster.Close()
+			return nil, err
+		}
+		r := &replica{db: replicaDB}
+		r.healthy.Store(replicaDB.Ping() == nil)
+		cluster.replicas = append(cluster.replicas, r)
+	}
+	return cluster, nil
+}
+
+// copyIn bulk-loads rows into table with COPY. COPY runs on a single
+// connection, so outside a transaction it opens one of its own.
+func copyIn(ctx context.Context, db DBTX, table string, columns []string, rows [][]interface{}) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	if sqlDB, ok := db.(*sqlx.DB); ok {
+		tx, err := sqlDB.BeginTxx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		if err := copyIn(ctx, tx, table, columns, rows); err != nil {
+			tx.Rollback()
+			return err
+		}
+		return tx.Commit()
+	}
+	tx, ok := db.(*sqlx.Tx)
+	if !ok {
+		return fmt.Errorf("COPY into %s needs a database or transaction, got %T", table, db)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn(table, columns...))
+	if err != nil {
+		return fmt.Errorf("failed to start COPY into %s: %w", table, err)
+	}
+	for _, row := range rows {
+		if _, err := stmt.ExecContext(ctx, row...); err != nil {
+			stmt.Close()
+			return fmt.Errorf("failed to COPY into %s: %w", table, err)
+		}
+	}
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		stmt.Close()
+		return fmt.Errorf("failed to COPY into %s: %w", table, err)
+	}
+	return stmt.Close()
+}
+
+func openPostgres(config PostgresConfig) (*sqlx.DB, error) {
+	return sqlx.Open("postgres", fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s", config.Host, config.Port, config.User, config.Password, config.DBName, config.SSLMode))
 }
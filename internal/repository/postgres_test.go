@@ -0,0 +1,46 @@
+package repository_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/jmoiron/sqlx"
+	"github.com/lavatee/subs/internal/repository"
+	_ "github.com/lib/pq"
+)
+
+// testDB connects to the database named by SUBS_TEST_POSTGRES_DSN and
+// applies the schema. Tests that need Postgres are skipped without it.
+func testDB(t *testing.T) *sqlx.DB {
+	t.Helper()
+	dsn := os.Getenv("SUBS_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("SUBS_TEST_POSTGRES_DSN is not set")
+	}
+	db, err := sqlx.Connect("postgres", dsn)
+	if err != nil {
+		t.Fatalf("Failed to connect to Postgres: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrations, err := repository.NewMigrator(context.Background(), db)
+	if err != nil {
+		t.Fatalf("Failed to create migrator: %v", err)
+	}
+	defer migrations.Close()
+	if err := migrations.Up(); err != nil && err != migrate.ErrNoChange {
+		t.Fatalf("Migrations error: %v", err)
+	}
+	return db
+}
+
+func truncate(t *testing.T, db *sqlx.DB, tables ...string) {
+	t.Helper()
+	for _, table := range tables {
+		if _, err := db.Exec("TRUNCATE " + table); err != nil {
+			t.Fatalf("Failed to truncate %s: %v", table, err)
+		}
+	}
+}
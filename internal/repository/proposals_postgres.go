@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/model"
+)
+
+const proposalColumns = `id, user_id, merchant, service_name, price, start_date, end_date,
+	last_charge_date, charges, confidence, status, subscription_id, created_at`
+
+type ProposalsPostgres struct {
+	db DBTX
+}
+
+func NewProposalsPostgres(db DBTX) *ProposalsPostgres {
+	return &ProposalsPostgres{
+		db: db,
+	}
+}
+
+// UpsertProposal stores a pending proposal, or refreshes the one already
+// found for the same user, merchant and price. It reports false and leaves
+// the row alone when that proposal was accepted or dismissed.
+func (r *ProposalsPostgres) UpsertProposal(ctx context.Context, proposal model.SubscriptionProposal) (model.SubscriptionProposal, bool, error) {
+	query := fmt.Sprintf(`INSERT INTO %[1]s (id, user_id, merchant, service_name, price, start_date, end_date,
+		last_charge_date, charges, confidence, status, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	ON CONFLICT (user_id, merchant, price) DO UPDATE SET
+		start_date = LEAST(%[1]s.start_date, EXCLUDED.start_date),
+		end_date = CASE WHEN EXCLUDED.last_charge_date >= %[1]s.last_charge_date
+			THEN EXCLUDED.end_date ELSE %[1]s.end_date END,
+		last_charge_date = GREATEST(%[1]s.last_charge_date, EXCLUDED.last_charge_date),
+		charges = GREATEST(%[1]s.charges, EXCLUDED.charges),
+		confidence = EXCLUDED.confidence
+	WHERE %[1]s.status = '%[2]s'
+	RETURNING %[3]s`, proposalsTable, model.ProposalPending, proposalColumns)
+
+	var stored model.SubscriptionProposal
+	err := r.db.GetContext(ctx, &stored, query, proposal.ID, proposal.UserID, proposal.Merchant, proposal.ServiceName,
+		proposal.Price, proposal.StartDate, proposal.EndDate, proposal.LastChargeDate, proposal.Charges,
+		proposal.Confidence, model.ProposalPending, proposal.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.SubscriptionProposal{}, false, nil
+		}
+		return model.SubscriptionProposal{}, false, fmt.Errorf("failed to store proposal: %w", err)
+	}
+	return stored, true, nil
+}
+
+// GetProposals returns the proposals of userID, optionally only those in status.
+func (r *ProposalsPostgres) GetProposals(ctx context.Context, userID uuid.UUID, status string) ([]model.SubscriptionProposal, error) {
+	query := fmt.Sprintf(`SELECT %s
+	FROM %s
+	WHERE user_id = $1 AND ($2 = '' OR status = $2)
+	ORDER BY service_name, price, id`, proposalColumns, proposalsTable)
+
+	var proposals []model.SubscriptionProposal
+	if err := r.db.SelectContext(ctx, &proposals, query, userID, status); err != nil {
+		return nil, fmt.Errorf("failed to get proposals: %w", err)
+	}
+	return proposals, nil
+}
+
+func (r *ProposalsPostgres) GetProposal(ctx context.Context, id uuid.UUID) (model.SubscriptionProposal, error) {
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE id = $1`, proposalColumns, proposalsTable)
+	var proposal model.SubscriptionProposal
+	if err := r.db.GetContext(ctx, &proposal, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.SubscriptionProposal{}, fmt.Errorf("proposal %s: %w", id, model.ErrNotFound)
+		}
+		return model.SubscriptionProposal{}, err
+	}
+	return proposal, nil
+}
+
+// ResolveProposal moves a pending proposal to status. It fails with
+// model.ErrConflict when the proposal is no longer pending.
+func (r *ProposalsPostgres) ResolveProposal(ctx context.Context, id uuid.UUID, status string, subscriptionID *uuid.UUID) error {
+	query := fmt.Sprintf(`UPDATE %s SET status = $2, subscription_id = $3
+	WHERE id = $1 AND status = '%s'`, proposalsTable, model.ProposalPending)
+	result, err := r.db.ExecContext(ctx, query, id, status, subscriptionID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("proposal %s is not pending: %w", id, model.ErrConflict)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/model"
+	"github.com/lavatee/subs/internal/repository"
+)
+
+func TestProposalsPostgres(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	truncate(t, db, "subscription_proposals")
+
+	repo := repository.NewProposalsPostgres(db)
+	userID := uuid.New()
+	proposal := model.SubscriptionProposal{
+		ID:             uuid.New(),
+		UserID:         userID,
+		Merchant:       "netflix",
+		ServiceName:    "Netflix",
+		Price:          799,
+		StartDate:      time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+		LastChargeDate: time.Date(2025, 5, 5, 0, 0, 0, 0, time.UTC),
+		Charges:        3,
+		Confidence:     1,
+		CreatedAt:      time.Now().UTC(),
+	}
+	stored, ok, err := repo.UpsertProposal(ctx, proposal)
+	if err != nil || !ok || stored.Status != model.ProposalPending {
+		t.Fatalf("UpsertProposal: %+v, %v, %v", stored, ok, err)
+	}
+
+	// A later statement extends the same proposal.
+	later := proposal
+	later.ID = uuid.New()
+	later.StartDate = time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	later.LastChargeDate = time.Date(2025, 7, 5, 0, 0, 0, 0, time.UTC)
+	later.Charges = 4
+	refreshed, ok, err := repo.UpsertProposal(ctx, later)
+	if err != nil || !ok {
+		t.Fatalf("UpsertProposal again: %v, %v", ok, err)
+	}
+	if refreshed.ID != proposal.ID || !refreshed.StartDate.Equal(proposal.StartDate) || !refreshed.LastChargeDate.Equal(later.LastChargeDate) || refreshed.Charges != 4 {
+		t.Fatalf("proposal was not merged: %+v", refreshed)
+	}
+
+	pending, err := repo.GetProposals(ctx, userID, model.ProposalPending)
+	if err != nil || len(pending) != 1 {
+		t.Fatalf("GetProposals(pending): %d, %v", len(pending), err)
+	}
+
+	if err := repo.ResolveProposal(ctx, proposal.ID, model.ProposalDismissed, nil); err != nil {
+		t.Fatalf("ResolveProposal: %v", err)
+	}
+	if err := repo.ResolveProposal(ctx, proposal.ID, model.ProposalAccepted, nil); !errors.Is(err, model.ErrConflict) {
+		t.Fatalf("resolving twice: want ErrConflict, got %v", err)
+	}
+	if _, ok, err := repo.UpsertProposal(ctx, later); err != nil || ok {
+		t.Fatalf("a dismissed proposal came back: %v, %v", ok, err)
+	}
+	if _, err := repo.GetProposal(ctx, uuid.New()); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("GetProposal of a missing id: want ErrNotFound, got %v", err)
+	}
+	all, err := repo.GetProposals(ctx, userID, "")
+	if err != nil || len(all) != 1 || all[0].Status != model.ProposalDismissed {
+		t.Fatalf("GetProposals: %+v, %v", all, err)
+	}
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/model"
+)
+
+type RemindersPostgres struct {
+	db DBTX
+}
+
+func NewRemindersPostgres(db DBTX) *RemindersPostgres {
+	return &RemindersPostgres{
+		db: db,
+	}
+}
+
+func (r *RemindersPostgres) GetContact(ctx context.Context, userID uuid.UUID) (model.UserContact, error) {
+	query := fmt.Sprintf(`SELECT user_id, email, webhook_url, updated_at FROM %s WHERE user_id = $1`, userContactsTable)
+	var contact model.UserContact
+	if err := r.db.GetContext(ctx, &contact, query, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.UserContact{}, fmt.Errorf("contact of user %s: %w", userID, model.ErrNotFound)
+		}
+		return model.UserContact{}, err
+	}
+	return contact, nil
+}
+
+func (r *RemindersPostgres) UpsertContact(ctx context.Context, contact model.UserContact) error {
+	query := fmt.Sprintf(`INSERT INTO %s (user_id, email, webhook_url, updated_at)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (user_id) DO UPDATE
+	SET email = EXCLUDED.email, webhook_url = EXCLUDED.webhook_url, updated_at = EXCLUDED.updated_at`, userContactsTable)
+	_, err := r.db.ExecContext(ctx, query, contact.UserID, contact.Email, contact.WebhookURL, contact.UpdatedAt)
+	return err
+}
+
+// ClaimReminder records that reminder is being sent through channel. It
+// returns false when it has been claimed before.
+func (r *RemindersPostgres) ClaimReminder(ctx context.Context, reminder model.Reminder, channel string) (bool, error) {
+	query := fmt.Sprintf(`INSERT INTO %s (subscription_id, kind, due_date, lead_days, channel)
+	VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT DO NOTHING`, remindersSentTable)
+	result, err := r.db.ExecContext(ctx, query, reminder.Subscription.ID, reminder.Kind, reminder.DueDate, reminder.LeadDays, channel)
+	if err != nil {
+		return false, err
+	}
+	claimed, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return claimed == 1, nil
+}
+
+// ReleaseReminder drops a claim whose reminder could not be sent, so that the
+// next scan tries again.
+func (r *RemindersPostgres) ReleaseReminder(ctx context.Context, reminder model.Reminder, channel string) error {
+	query := fmt.Sprintf(`DELETE FROM %s
+	WHERE subscription_id = $1 AND kind = $2 AND due_date = $3 AND lead_days = $4 AND channel = $5`, remindersSentTable)
+	_, err := r.db.ExecContext(ctx, query, reminder.Subscription.ID, reminder.Kind, reminder.DueDate, reminder.LeadDays, channel)
+	return err
+}
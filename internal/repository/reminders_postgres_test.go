@@ -0,0 +1,56 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/model"
+	"github.com/lavatee/subs/internal/repository"
+)
+
+func TestRemindersPostgres(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	truncate(t, db, "user_contacts", "reminders_sent")
+
+	repo := repository.NewRemindersPostgres(db)
+	userID := uuid.New()
+	if _, err := repo.GetContact(ctx, userID); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("GetContact of an unknown user: want ErrNotFound, got %v", err)
+	}
+	for _, email := range []string{"old@example.com", "new@example.com"} {
+		contact := model.UserContact{UserID: userID, Email: email, UpdatedAt: time.Now().UTC()}
+		if err := repo.UpsertContact(ctx, contact); err != nil {
+			t.Fatalf("UpsertContact: %v", err)
+		}
+	}
+	contact, err := repo.GetContact(ctx, userID)
+	if err != nil || contact.Email != "new@example.com" || contact.WebhookURL != "" {
+		t.Fatalf("GetContact: got %+v, %v", contact, err)
+	}
+
+	reminder := model.Reminder{
+		Kind:         model.ReminderRenewal,
+		DueDate:      time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC),
+		LeadDays:     3,
+		Subscription: model.Subscription{ID: uuid.New()},
+	}
+	for i, want := range []bool{true, false} {
+		claimed, err := repo.ClaimReminder(ctx, reminder, "email")
+		if err != nil || claimed != want {
+			t.Fatalf("ClaimReminder call %d: want %v, got %v, %v", i+1, want, claimed, err)
+		}
+	}
+	if claimed, err := repo.ClaimReminder(ctx, reminder, "webhook"); err != nil || !claimed {
+		t.Fatalf("ClaimReminder on another channel: got %v, %v", claimed, err)
+	}
+	if err := repo.ReleaseReminder(ctx, reminder, "email"); err != nil {
+		t.Fatalf("ReleaseReminder: %v", err)
+	}
+	if claimed, err := repo.ClaimReminder(ctx, reminder, "email"); err != nil || !claimed {
+		t.Fatalf("ClaimReminder after release: got %v, %v", claimed, err)
+	}
+}
@@ -7,23 +7,154 @@ import (
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"github.com/lavatee/subs/internal/model"
+	"go.opentelemetry.io/otel"
 )
 
+var tracer = otel.Tracer("github.com/lavatee/subs/internal/repository")
+
 type Subscriptions interface {
 	CreateSubscription(ctx context.Context, sub model.Subscription) error
-	GetUserSubscriptions(ctx context.Context, userID uuid.UUID, serviceName string) ([]model.Subscription, error)
+	CreateSubscriptions(ctx context.Context, subs []model.Subscription) error
+	GetUserSubscriptions(ctx context.Context, userID uuid.UUID, serviceName string, includeArchived bool) ([]model.Subscription, error)
+	StreamUserSubscriptions(ctx context.Context, userID uuid.UUID, serviceName string, includeArchived bool, fn func(sub model.Subscription) error) error
 	GetSubscription(ctx context.Context, id uuid.UUID) (model.Subscription, error)
 	UpdateSubscription(ctx context.Context, sub model.Subscription) error
 	DeleteSubscription(ctx context.Context, id uuid.UUID) error
-	GetTotalCost(ctx context.Context, userID uuid.UUID, serviceName string, startDate, endDate time.Time) (int, error)
+	GetTotalCost(ctx context.Context, userID uuid.UUID, serviceName string, startDate, endDate time.Time, includeArchived bool) (int, error)
+	GetTotalCostSubscriptions(ctx context.Context, userID uuid.UUID, serviceName string, startDate, endDate time.Time, includeArchived bool) ([]model.Subscription, error)
+	GetEndingBetween(ctx context.Context, from, to time.Time) ([]model.Subscription, error)
+	GetRenewingOn(ctx context.Context, renewal time.Time) ([]model.Subscription, error)
+}
+
+type MonthlySpend interface {
+	GetMonthlySpend(ctx context.Context, userID uuid.UUID, serviceName string, startDate, endDate time.Time) ([]model.MonthlySpend, error)
+	RebuildMonthlySpend(ctx context.Context) error
+}
+
+type Archive interface {
+	ArchiveEnded(ctx context.Context, endedBefore time.Time, limit int) ([]model.Subscription, error)
+}
+
+type Webhooks interface {
+	CreateWebhook(ctx context.Context, hook model.Webhook) error
+	GetWebhooks(ctx context.Context) ([]model.Webhook, error)
+	GetWebhook(ctx context.Context, id uuid.UUID) (model.Webhook, error)
+	DeleteWebhook(ctx context.Context, id uuid.UUID) error
+	EnqueueDeliveries(ctx context.Context, event string, payload []byte, dedupKey string) (int, error)
+	EnqueueBatch(ctx context.Context, event string, payloads [][]byte) (int, error)
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]model.PendingDelivery, error)
+	RecordDeliveryAttempt(ctx context.Context, delivery model.WebhookDelivery) error
+	GetDeliveries(ctx context.Context, webhookID uuid.UUID, limit int) ([]model.WebhookDelivery, error)
+	RedeliverDelivery(ctx context.Context, webhookID, id uuid.UUID) (model.WebhookDelivery, error)
+	GetScannedUntil(ctx context.Context, event string) (time.Time, error)
+	SetScannedUntil(ctx context.Context, event string, until time.Time) error
+}
+
+type Outbox interface {
+	AddEvent(ctx context.Context, eventID, aggregateID uuid.UUID, eventType string, payload []byte) error
+	AddEvents(ctx context.Context, events []model.OutboxEvent) error
+	AcquireDispatcherLease(ctx context.Context, holder uuid.UUID, lease time.Duration) (bool, error)
+	ReleaseDispatcherLease(ctx context.Context, holder uuid.UUID) error
+	GetPendingEvents(ctx context.Context, limit int) ([]model.OutboxEvent, error)
+	MarkDispatched(ctx context.Context, sequences []int64) error
+	DeleteDispatchedBefore(ctx context.Context, before time.Time) (int, error)
+	GetTrimmedPosition(ctx context.Context) (model.EventPosition, error)
+	GetEventsAfter(ctx context.Context, after model.EventPosition, limit int) ([]model.OutboxEvent, error)
+	GetLastEvents(ctx context.Context, limit int) ([]model.OutboxEvent, error)
+}
+
+type Reminders interface {
+	GetContact(ctx context.Context, userID uuid.UUID) (model.UserContact, error)
+	UpsertContact(ctx context.Context, contact model.UserContact) error
+	ClaimReminder(ctx context.Context, reminder model.Reminder, channel string) (bool, error)
+	ReleaseReminder(ctx context.Context, reminder model.Reminder, channel string) error
+}
+
+type Budgets interface {
+	CreateBudget(ctx context.Context, budget model.Budget) error
+	GetBudgets(ctx context.Context, userID uuid.UUID) ([]model.Budget, error)
+	GetBudget(ctx context.Context, id uuid.UUID) (model.Budget, error)
+	UpdateBudget(ctx context.Context, budget model.Budget) error
+	DeleteBudget(ctx context.Context, id uuid.UUID) error
+}
+
+type Proposals interface {
+	UpsertProposal(ctx context.Context, proposal model.SubscriptionProposal) (model.SubscriptionProposal, bool, error)
+	GetProposals(ctx context.Context, userID uuid.UUID, status string) ([]model.SubscriptionProposal, error)
+	GetProposal(ctx context.Context, id uuid.UUID) (model.SubscriptionProposal, error)
+	ResolveProposal(ctx context.Context, id uuid.UUID, status string, subscriptionID *uuid.UUID) error
+}
+
+type CalendarTokens interface {
+	UpsertCalendarToken(ctx context.Context, token model.CalendarToken) error
+	GetCalendarToken(ctx context.Context, userID uuid.UUID) (model.CalendarToken, error)
+	DeleteCalendarToken(ctx context.Context, userID uuid.UUID) error
+}
+
+type Analytics interface {
+	GetTopServices(ctx context.Context, filter model.AnalyticsFilter, limit int) ([]model.ServiceSpend, error)
+	GetAveragePrices(ctx context.Context, filter model.AnalyticsFilter) ([]model.ServicePrice, error)
+	GetActiveSubscriptions(ctx context.Context, filter model.AnalyticsFilter) ([]model.ActiveSubscriptions, error)
+	GetChurn(ctx context.Context, filter model.AnalyticsFilter) ([]model.SubscriptionChurn, error)
+	GetUserSpendStats(ctx context.Context, filter model.AnalyticsFilter) (model.UserSpendStats, error)
+	GetActiveTotals(ctx context.Context, month time.Time) (model.ActiveTotals, error)
+}
+
+type Health interface {
+	Ping(ctx context.Context) error
+	GetMigrationVersion(ctx context.Context) (model.MigrationVersion, error)
 }
 
 type Repository struct {
 	Subscriptions
+	MonthlySpend
+	Archive
+	Webhooks
+	Outbox
+	Reminders
+	Budgets
+	Proposals
+	CalendarTokens
+	Analytics
+	Health
+	db        *sqlx.DB
+	tx        *sqlx.Tx
+	txOptions TxOptions
+}
+
+func NewRepository(cluster *Cluster, txOptions TxOptions) *Repository {
+	db, reader := cluster.Primary, cluster.Reader()
+	return &Repository{
+		Subscriptions:  newInstrumentedSubscriptions(NewSubscriptionsPostgres(db, reader), tracer),
+		MonthlySpend:   NewMonthlySpendPostgres(db, reader),
+		Archive:        NewArchivePostgres(db),
+		Webhooks:       NewWebhooksPostgres(db),
+		Outbox:         NewOutboxPostgres(db),
+		Reminders:      NewRemindersPostgres(db),
+		Budgets:        NewBudgetsPostgres(db),
+		Proposals:      NewProposalsPostgres(db),
+		CalendarTokens: NewCalendarTokensPostgres(db),
+		Analytics:      NewAnalyticsPostgres(reader),
+		Health:         NewHealthPostgres(db),
+		db:             db,
+		txOptions:      txOptions,
+	}
 }
 
-func NewRepository(db *sqlx.DB) *Repository {
+func newTxRepository(tx *sqlx.Tx, txOptions TxOptions) *Repository {
 	return &Repository{
-		Subscriptions: NewSubscriptionsPostgres(db),
+		Subscriptions:  newInstrumentedSubscriptions(NewSubscriptionsPostgres(tx, tx), tracer),
+		MonthlySpend:   NewMonthlySpendPostgres(tx, tx),
+		Archive:        NewArchivePostgres(tx),
+		Webhooks:       NewWebhooksPostgres(tx),
+		Outbox:         NewOutboxPostgres(tx),
+		Reminders:      NewRemindersPostgres(tx),
+		Budgets:        NewBudgetsPostgres(tx),
+		Proposals:      NewProposalsPostgres(tx),
+		CalendarTokens: NewCalendarTokensPostgres(tx),
+		Analytics:      NewAnalyticsPostgres(tx),
+		Health:         NewHealthPostgres(tx),
+		tx:             tx,
+		txOptions:      txOptions,
 	}
 }
@@ -0,0 +1,526 @@
+// Package repotest holds conformance suites for repository backends.
+// A backend proves it behaves like the Postgres one by running the suite
+// from its own _test.go file:
+//
+//	repotest.RunSubscriptions(t, func(t *testing.T) repository.Subscriptions {
+//		return newEmptyBackend(t)
+//	})
+package repotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/model"
+	"github.com/lavatee/subs/internal/repository"
+)
+
+// SubscriptionsFactory returns a Subscriptions backend holding no rows.
+// It is called once per subtest.
+type SubscriptionsFactory func(t *testing.T) repository.Subscriptions
+
+// RunSubscriptions runs every conformance check against backends built by newRepo.
+func RunSubscriptions(t *testing.T, newRepo SubscriptionsFactory) {
+	t.Run("CreateAndGet", func(t *testing.T) { testCreateAndGet(t, newRepo(t)) })
+	t.Run("CreateDuplicateID", func(t *testing.T) { testCreateDuplicateID(t, newRepo(t)) })
+	t.Run("CreateMany", func(t *testing.T) { testCreateMany(t, newRepo(t)) })
+	t.Run("GetMissing", func(t *testing.T) { testGetMissing(t, newRepo(t)) })
+	t.Run("Update", func(t *testing.T) { testUpdate(t, newRepo(t)) })
+	t.Run("UpdateClearsEndDate", func(t *testing.T) { testUpdateClearsEndDate(t, newRepo(t)) })
+	t.Run("UpdateMissing", func(t *testing.T) { testUpdateMissing(t, newRepo(t)) })
+	t.Run("Delete", func(t *testing.T) { testDelete(t, newRepo(t)) })
+	t.Run("DeleteMissing", func(t *testing.T) { testDeleteMissing(t, newRepo(t)) })
+	t.Run("Filters", func(t *testing.T) { testFilters(t, newRepo(t)) })
+	t.Run("NewestFirst", func(t *testing.T) { testNewestFirst(t, newRepo(t)) })
+	t.Run("Stream", func(t *testing.T) { testStream(t, newRepo(t)) })
+	t.Run("TotalCost", func(t *testing.T) { testTotalCost(t, newRepo(t)) })
+	t.Run("TotalCostEmpty", func(t *testing.T) { testTotalCostEmpty(t, newRepo(t)) })
+	t.Run("EndingBetween", func(t *testing.T) { testEndingBetween(t, newRepo(t)) })
+	t.Run("RenewingOn", func(t *testing.T) { testRenewingOn(t, newRepo(t)) })
+	t.Run("ConcurrentWrites", func(t *testing.T) { testConcurrentWrites(t, newRepo(t)) })
+}
+
+var (
+	userA = uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	userB = uuid.MustParse("22222222-2222-2222-2222-222222222222")
+)
+
+func month(year int, m time.Month) time.Time {
+	return time.Date(year, m, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func monthPtr(year int, m time.Month) *time.Time {
+	t := month(year, m)
+	return &t
+}
+
+func newSubscription(userID uuid.UUID, serviceName string, price int, start time.Time, end *time.Time) model.Subscription {
+	return model.Subscription{
+		ID:          uuid.New(),
+		ServiceName: serviceName,
+		Price:       price,
+		UserID:      userID,
+		StartDate:   start,
+		EndDate:     end,
+		CreatedAt:   time.Now().UTC().Truncate(time.Microsecond),
+	}
+}
+
+func mustCreate(t *testing.T, repo repository.Subscriptions, subs ...model.Subscription) {
+	t.Helper()
+	for _, sub := range subs {
+		if err := repo.CreateSubscription(context.Background(), sub); err != nil {
+			t.Fatalf("CreateSubscription(%s): %v", sub.ID, err)
+		}
+	}
+}
+
+func assertSameSubscription(t *testing.T, want, got model.Subscription) {
+	t.Helper()
+	if got.ID != want.ID || got.ServiceName != want.ServiceName || got.Price != want.Price || got.UserID != want.UserID {
+		t.Fatalf("subscription mismatch:\nwant %+v\ngot  %+v", want, got)
+	}
+	if !got.StartDate.Equal(want.StartDate) {
+		t.Fatalf("start_date: want %s, got %s", want.StartDate, got.StartDate)
+	}
+	if !got.CreatedAt.Equal(want.CreatedAt) {
+		t.Fatalf("created_at: want %s, got %s", want.CreatedAt, got.CreatedAt)
+	}
+	switch {
+	case want.EndDate == nil && got.EndDate != nil:
+		t.Fatalf("end_date: want nil, got %s", got.EndDate)
+	case want.EndDate != nil && got.EndDate == nil:
+		t.Fatalf("end_date: want %s, got nil", want.EndDate)
+	case want.EndDate != nil && !got.EndDate.Equal(*want.EndDate):
+		t.Fatalf("end_date: want %s, got %s", want.EndDate, got.EndDate)
+	}
+}
+
+func ids(subs []model.Subscription) []uuid.UUID {
+	result := make([]uuid.UUID, 0, len(subs))
+	for _, sub := range subs {
+		result = append(result, sub.ID)
+	}
+	return result
+}
+
+func sortedIDs(subs ...model.Subscription) []string {
+	result := make([]string, 0, len(subs))
+	for _, sub := range subs {
+		result = append(result, sub.ID.String())
+	}
+	sort.Strings(result)
+	return result
+}
+
+func testCreateAndGet(t *testing.T, repo repository.Subscriptions) {
+	ctx := context.Background()
+	open := newSubscription(userA, "Netflix", 599, month(2025, time.January), nil)
+	closed := newSubscription(userA, "Spotify", 299, month(2025, time.February), monthPtr(2025, time.December))
+	mustCreate(t, repo, open, closed)
+
+	for _, want := range []model.Subscription{open, closed} {
+		got, err := repo.GetSubscription(ctx, want.ID)
+		if err != nil {
+			t.Fatalf("GetSubscription(%s): %v", want.ID, err)
+		}
+		assertSameSubscription(t, want, got)
+	}
+}
+
+func testCreateDuplicateID(t *testing.T, repo repository.Subscriptions) {
+	sub := newSubscription(userA, "Netflix", 599, month(2025, time.January), nil)
+	mustCreate(t, repo, sub)
+
+	duplicate := sub
+	duplicate.ServiceName = "Spotify"
+	if err := repo.CreateSubscription(context.Background(), duplicate); err == nil {
+		t.Fatal("CreateSubscription with an existing id: want error, got nil")
+	}
+
+	got, err := repo.GetSubscription(context.Background(), sub.ID)
+	if err != nil {
+		t.Fatalf("GetSubscription: %v", err)
+	}
+	assertSameSubscription(t, sub, got)
+}
+
+func testCreateMany(t *testing.T, repo repository.Subscriptions) {
+	ctx := context.Background()
+	subs := []model.Subscription{
+		newSubscription(userA, "Netflix", 400, month(2025, time.January), monthPtr(2025, time.June)),
+		newSubscription(userA, "Spotify", 200, month(2025, time.February), nil),
+		newSubscription(userB, "Netflix", 400, month(2025, time.March), nil),
+	}
+	if err := repo.CreateSubscriptions(ctx, subs); err != nil {
+		t.Fatalf("CreateSubscriptions: %v", err)
+	}
+	for _, want := range subs {
+		got, err := repo.GetSubscription(ctx, want.ID)
+		if err != nil {
+			t.Fatalf("GetSubscription(%s): %v", want.ID, err)
+		}
+		assertSameSubscription(t, want, got)
+	}
+
+	// A conflicting row rolls back the whole batch.
+	fresh := newSubscription(userB, "Spotify", 100, month(2025, time.April), nil)
+	if err := repo.CreateSubscriptions(ctx, []model.Subscription{fresh, subs[0]}); err == nil {
+		t.Fatal("CreateSubscriptions with a duplicate id: want error, got nil")
+	}
+	if _, err := repo.GetSubscription(ctx, fresh.ID); err == nil {
+		t.Fatal("row of a failed batch was created")
+	}
+	if err := repo.CreateSubscriptions(ctx, nil); err != nil {
+		t.Fatalf("CreateSubscriptions(nil): %v", err)
+	}
+}
+
+func testGetMissing(t *testing.T, repo repository.Subscriptions) {
+	if _, err := repo.GetSubscription(context.Background(), uuid.New()); err == nil {
+		t.Fatal("GetSubscription of a missing id: want error, got nil")
+	}
+}
+
+func testUpdate(t *testing.T, repo repository.Subscriptions) {
+	ctx := context.Background()
+	sub := newSubscription(userA, "Netflix", 599, month(2025, time.January), nil)
+	mustCreate(t, repo, sub)
+
+	updated := sub
+	updated.ServiceName = "Netflix Premium"
+	updated.Price = 999
+	updated.StartDate = month(2025, time.March)
+	updated.EndDate = monthPtr(2026, time.March)
+	// user_id and created_at are immutable, whatever the caller passes.
+	updated.UserID = userB
+	updated.CreatedAt = sub.CreatedAt.Add(time.Hour)
+	if err := repo.UpdateSubscription(ctx, updated); err != nil {
+		t.Fatalf("UpdateSubscription: %v", err)
+	}
+
+	got, err := repo.GetSubscription(ctx, sub.ID)
+	if err != nil {
+		t.Fatalf("GetSubscription: %v", err)
+	}
+	want := updated
+	want.UserID = sub.UserID
+	want.CreatedAt = sub.CreatedAt
+	assertSameSubscription(t, want, got)
+}
+
+func testUpdateClearsEndDate(t *testing.T, repo repository.Subscriptions) {
+	ctx := context.Background()
+	sub := newSubscription(userA, "Netflix", 599, month(2025, time.January), monthPtr(2025, time.June))
+	mustCreate(t, repo, sub)
+
+	updated := sub
+	updated.EndDate = nil
+	if err := repo.UpdateSubscription(ctx, updated); err != nil {
+		t.Fatalf("UpdateSubscription: %v", err)
+	}
+
+	got, err := repo.GetSubscription(ctx, sub.ID)
+	if err != nil {
+		t.Fatalf("GetSubscription: %v", err)
+	}
+	assertSameSubscription(t, updated, got)
+}
+
+func testUpdateMissing(t *testing.T, repo repository.Subscriptions) {
+	ctx := context.Background()
+	sub := newSubscription(userA, "Netflix", 599, month(2025, time.January), nil)
+	// Updating a missing row is a silent no-op; it must not upsert.
+	if err := repo.UpdateSubscription(ctx, sub); err != nil {
+		t.Fatalf("UpdateSubscription of a missing id: %v", err)
+	}
+	if _, err := repo.GetSubscription(ctx, sub.ID); err == nil {
+		t.Fatal("UpdateSubscription of a missing id created a row")
+	}
+}
+
+func testDelete(t *testing.T, repo repository.Subscriptions) {
+	ctx := context.Background()
+	keep := newSubscription(userA, "Netflix", 599, month(2025, time.January), nil)
+	drop := newSubscription(userA, "Spotify", 299, month(2025, time.January), nil)
+	mustCreate(t, repo, keep, drop)
+
+	if err := repo.DeleteSubscription(ctx, drop.ID); err != nil {
+		t.Fatalf("DeleteSubscription: %v", err)
+	}
+	if _, err := repo.GetSubscription(ctx, drop.ID); err == nil {
+		t.Fatal("GetSubscription after delete: want error, got nil")
+	}
+	if _, err := repo.GetSubscription(ctx, keep.ID); err != nil {
+		t.Fatalf("GetSubscription of an untouched row: %v", err)
+	}
+}
+
+func testDeleteMissing(t *testing.T, repo repository.Subscriptions) {
+	if err := repo.DeleteSubscription(context.Background(), uuid.New()); err == nil {
+		t.Fatal("DeleteSubscription of a missing id: want error, got nil")
+	}
+}
+
+func testFilters(t *testing.T, repo repository.Subscriptions) {
+	aNetflix := newSubscription(userA, "Netflix", 599, month(2025, time.January), nil)
+	aSpotify := newSubscription(userA, "Spotify", 299, month(2025, time.January), nil)
+	bNetflix := newSubscription(userB, "Netflix", 599, month(2025, time.January), nil)
+	bLower := newSubscription(userB, "netflix", 599, month(2025, time.January), nil)
+	mustCreate(t, repo, aNetflix, aSpotify, bNetflix, bLower)
+
+	tests := []struct {
+		name        string
+		userID      uuid.UUID
+		serviceName string
+		want        []model.Subscription
+	}{
+		{name: "nil user and empty service return everything", userID: uuid.Nil, want: []model.Subscription{aNetflix, aSpotify, bNetflix, bLower}},
+		{name: "user only", userID: userA, want: []model.Subscription{aNetflix, aSpotify}},
+		{name: "service only", serviceName: "Netflix", want: []model.Subscription{aNetflix, bNetflix}},
+		{name: "service match is case sensitive", serviceName: "netflix", want: []model.Subscription{bLower}},
+		{name: "user and service", userID: userB, serviceName: "Netflix", want: []model.Subscription{bNetflix}},
+		{name: "unknown user", userID: uuid.New(), want: nil},
+		{name: "unknown service", serviceName: "Disney+", want: nil},
+		{name: "user without that service", userID: userA, serviceName: "netflix", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.GetUserSubscriptions(context.Background(), tt.userID, tt.serviceName, false)
+			if err != nil {
+				t.Fatalf("GetUserSubscriptions: %v", err)
+			}
+			if want, got := fmt.Sprint(sortedIDs(tt.want...)), fmt.Sprint(sortedIDs(got...)); want != got {
+				t.Fatalf("want %s, got %s", want, got)
+			}
+		})
+	}
+}
+
+func testStream(t *testing.T, repo repository.Subscriptions) {
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		sub := newSubscription(userA, "Netflix", i+1, month(2025, time.January), nil)
+		sub.CreatedAt = sub.CreatedAt.Add(time.Duration(i) * time.Second)
+		mustCreate(t, repo, sub)
+	}
+	mustCreate(t, repo, newSubscription(userB, "Netflix", 100, month(2025, time.January), nil))
+
+	listed, err := repo.GetUserSubscriptions(ctx, userA, "Netflix", false)
+	if err != nil {
+		t.Fatalf("GetUserSubscriptions: %v", err)
+	}
+	var streamed []model.Subscription
+	err = repo.StreamUserSubscriptions(ctx, userA, "Netflix", false, func(sub model.Subscription) error {
+		streamed = append(streamed, sub)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamUserSubscriptions: %v", err)
+	}
+	if fmt.Sprint(ids(listed)) != fmt.Sprint(ids(streamed)) {
+		t.Fatalf("stream order %v differs from list order %v", ids(streamed), ids(listed))
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err = repo.StreamUserSubscriptions(ctx, uuid.Nil, "", false, func(model.Subscription) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Fatalf("callback error: want stop after 1 call, got %v after %d", err, calls)
+	}
+}
+
+func testNewestFirst(t *testing.T, repo repository.Subscriptions) {
+	base := time.Date(2025, time.May, 1, 12, 0, 0, 0, time.UTC)
+	var created []model.Subscription
+	for i := 0; i < 3; i++ {
+		sub := newSubscription(userA, "Netflix", 100+i, month(2025, time.January), nil)
+		sub.CreatedAt = base.Add(time.Duration(i) * time.Minute)
+		created = append(created, sub)
+	}
+	// Insert out of order so that insertion order can't satisfy the check.
+	mustCreate(t, repo, created[1], created[2], created[0])
+
+	got, err := repo.GetUserSubscriptions(context.Background(), userA, "", false)
+	if err != nil {
+		t.Fatalf("GetUserSubscriptions: %v", err)
+	}
+	want := []uuid.UUID{created[2].ID, created[1].ID, created[0].ID}
+	if fmt.Sprint(want) != fmt.Sprint(ids(got)) {
+		t.Fatalf("want newest first %v, got %v", want, ids(got))
+	}
+}
+
+func testTotalCost(t *testing.T, repo repository.Subscriptions) {
+	// Jan..Mar 2025, Mar..open, Jun 2025 only, and userB's Feb..Apr 2025.
+	mustCreate(t, repo,
+		newSubscription(userA, "Netflix", 100, month(2025, time.January), monthPtr(2025, time.March)),
+		newSubscription(userA, "Spotify", 20, month(2025, time.March), nil),
+		newSubscription(userA, "Netflix", 3, month(2025, time.June), monthPtr(2025, time.June)),
+		newSubscription(userB, "Netflix", 4000, month(2025, time.February), monthPtr(2025, time.April)),
+	)
+
+	var zero time.Time
+	tests := []struct {
+		name        string
+		userID      uuid.UUID
+		serviceName string
+		start, end  time.Time
+		want        int
+	}{
+		{name: "no filters", want: 4123},
+		{name: "user only", userID: userA, want: 123},
+		{name: "service only", serviceName: "Netflix", want: 4103},
+		{name: "user and service", userID: userA, serviceName: "Netflix", want: 103},
+		{name: "unknown user", userID: uuid.New(), want: 0},
+		{name: "window start equals an end_date", userID: userA, start: month(2025, time.March), end: zero, want: 123},
+		{name: "window end equals a start_date", userID: userA, start: zero, end: month(2025, time.March), want: 120},
+		{name: "window after everything ended keeps open subscriptions", userID: userA, start: month(2030, time.January), want: 20},
+		{name: "window before everything started", userID: userA, end: month(2024, time.December), want: 0},
+		{name: "single month window", userID: userA, start: month(2025, time.June), end: month(2025, time.June), want: 23},
+		{name: "window between two subscriptions", userID: userA, serviceName: "Netflix", start: month(2025, time.April), end: month(2025, time.May), want: 0},
+		{name: "window overlapping several users", start: month(2025, time.April), end: month(2025, time.May), want: 4020},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.GetTotalCost(context.Background(), tt.userID, tt.serviceName, tt.start, tt.end, false)
+			if err != nil {
+				t.Fatalf("GetTotalCost: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("want %d, got %d", tt.want, got)
+			}
+
+			subs, err := repo.GetTotalCostSubscriptions(context.Background(), tt.userID, tt.serviceName, tt.start, tt.end, false)
+			if err != nil {
+				t.Fatalf("GetTotalCostSubscriptions: %v", err)
+			}
+			sum := 0
+			for _, sub := range subs {
+				sum += sub.Price
+			}
+			if sum != tt.want {
+				t.Fatalf("GetTotalCostSubscriptions adds up to %d, want %d", sum, tt.want)
+			}
+		})
+	}
+}
+
+func testTotalCostEmpty(t *testing.T, repo repository.Subscriptions) {
+	got, err := repo.GetTotalCost(context.Background(), uuid.Nil, "", time.Time{}, time.Time{}, false)
+	if err != nil {
+		t.Fatalf("GetTotalCost: %v", err)
+	}
+	if got != 0 {
+		t.Fatalf("want 0 for an empty store, got %d", got)
+	}
+}
+
+func testEndingBetween(t *testing.T, repo repository.Subscriptions) {
+	beforeWindow := newSubscription(userA, "Netflix", 1, month(2025, time.January), monthPtr(2025, time.February))
+	atFrom := newSubscription(userA, "Netflix", 2, month(2025, time.January), monthPtr(2025, time.March))
+	inside := newSubscription(userB, "Spotify", 3, month(2025, time.January), monthPtr(2025, time.April))
+	atTo := newSubscription(userA, "Netflix", 4, month(2025, time.January), monthPtr(2025, time.May))
+	open := newSubscription(userA, "Netflix", 5, month(2025, time.January), nil)
+	mustCreate(t, repo, beforeWindow, atFrom, inside, atTo, open)
+
+	got, err := repo.GetEndingBetween(context.Background(), month(2025, time.March), month(2025, time.May))
+	if err != nil {
+		t.Fatalf("GetEndingBetween: %v", err)
+	}
+	want := []uuid.UUID{atFrom.ID, inside.ID}
+	if fmt.Sprint(want) != fmt.Sprint(ids(got)) {
+		t.Fatalf("want %v ordered by end_date, got %v", want, ids(got))
+	}
+}
+
+func testRenewingOn(t *testing.T, repo repository.Subscriptions) {
+	startsOn := newSubscription(userA, "Netflix", 1, month(2025, time.March), nil)
+	endedBefore := newSubscription(userA, "Netflix", 2, month(2025, time.January), monthPtr(2025, time.February))
+	endsOn := newSubscription(userA, "Netflix", 3, month(2025, time.January), monthPtr(2025, time.March))
+	open := newSubscription(userB, "Spotify", 4, month(2025, time.January), nil)
+	mustCreate(t, repo, startsOn, endedBefore, endsOn, open)
+
+	got, err := repo.GetRenewingOn(context.Background(), month(2025, time.March))
+	if err != nil {
+		t.Fatalf("GetRenewingOn: %v", err)
+	}
+	if want := sortedIDs(endsOn, open); fmt.Sprint(want) != fmt.Sprint(sortedIDs(got...)) {
+		t.Fatalf("want %v, got %v", want, sortedIDs(got...))
+	}
+}
+
+func testConcurrentWrites(t *testing.T, repo repository.Subscriptions) {
+	const writers = 16
+	ctx := context.Background()
+
+	subs := make([]model.Subscription, writers)
+	for i := range subs {
+		subs[i] = newSubscription(userA, "Netflix", i+1, month(2025, time.January), nil)
+	}
+
+	run := func(op func(i int) error) {
+		t.Helper()
+		var wg sync.WaitGroup
+		errs := make(chan error, writers)
+		for i := 0; i < writers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				if err := op(i); err != nil {
+					errs <- err
+				}
+			}(i)
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			t.Fatal(err)
+		}
+	}
+
+	run(func(i int) error { return repo.CreateSubscription(ctx, subs[i]) })
+	assertTotal(t, repo, writers*(writers+1)/2)
+
+	run(func(i int) error {
+		sub := subs[i]
+		sub.Price *= 10
+		return repo.UpdateSubscription(ctx, sub)
+	})
+	assertTotal(t, repo, 10*writers*(writers+1)/2)
+
+	run(func(i int) error {
+		if i%2 == 0 {
+			return repo.DeleteSubscription(ctx, subs[i].ID)
+		}
+		_, err := repo.GetSubscription(ctx, subs[i].ID)
+		return err
+	})
+	got, err := repo.GetUserSubscriptions(ctx, userA, "", false)
+	if err != nil {
+		t.Fatalf("GetUserSubscriptions: %v", err)
+	}
+	if len(got) != writers/2 {
+		t.Fatalf("want %d subscriptions after concurrent deletes, got %d", writers/2, len(got))
+	}
+}
+
+func assertTotal(t *testing.T, repo repository.Subscriptions, want int) {
+	t.Helper()
+	got, err := repo.GetTotalCost(context.Background(), uuid.Nil, "", time.Time{}, time.Time{}, false)
+	if err != nil {
+		t.Fatalf("GetTotalCost: %v", err)
+	}
+	if got != want {
+		t.Fatalf("total: want %d, got %d", want, got)
+	}
+}
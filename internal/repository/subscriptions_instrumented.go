@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/logging"
+	"github.com/lavatee/subs/internal/metrics"
+	"github.com/lavatee/subs/internal/model"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentedSubscriptions wraps every Subscriptions call in a span,
+// records its latency in metrics.DBQueryDuration, labelled with the method
+// name, and logs it at debug level with the logger of the request.
+type instrumentedSubscriptions struct {
+	next   Subscriptions
+	tracer trace.Tracer
+}
+
+func newInstrumentedSubscriptions(next Subscriptions, tracer trace.Tracer) *instrumentedSubscriptions {
+	return &instrumentedSubscriptions{
+		next:   next,
+		tracer: tracer,
+	}
+}
+
+// startQuery starts the span of method. The returned function ends it and
+// observes the latency.
+func (r *instrumentedSubscriptions) startQuery(ctx context.Context, method string) (context.Context, func(err error)) {
+	started := time.Now()
+	ctx, span := r.tracer.Start(ctx, "SubscriptionsPostgres."+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNamePostgreSQL,
+			semconv.DBOperationName(method),
+			semconv.DBCollectionName(subscriptionsTable),
+		))
+	return ctx, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+		elapsed := time.Since(started)
+		metrics.DBQueryDuration.WithLabelValues(method, metrics.Status(err)).Observe(elapsed.Seconds())
+		fields := logrus.Fields{"query": method, "latency_ms": elapsed.Milliseconds()}
+		if err != nil {
+			fields[logrus.ErrorKey] = err
+		}
+		logging.FromContext(ctx, nil).WithFields(fields).Debug("Query executed")
+	}
+}
+
+func (r *instrumentedSubscriptions) CreateSubscription(ctx context.Context, sub model.Subscription) (err error) {
+	ctx, end := r.startQuery(ctx, "CreateSubscription")
+	defer func() { end(err) }()
+	return r.next.CreateSubscription(ctx, sub)
+}
+
+func (r *instrumentedSubscriptions) CreateSubscriptions(ctx context.Context, subs []model.Subscription) (err error) {
+	ctx, end := r.startQuery(ctx, "CreateSubscriptions")
+	defer func() { end(err) }()
+	return r.next.CreateSubscriptions(ctx, subs)
+}
+
+func (r *instrumentedSubscriptions) GetUserSubscriptions(ctx context.Context, userID uuid.UUID, serviceName string, includeArchived bool) (_ []model.Subscription, err error) {
+	ctx, end := r.startQuery(ctx, "GetUserSubscriptions")
+	defer func() { end(err) }()
+	return r.next.GetUserSubscriptions(ctx, userID, serviceName, includeArchived)
+}
+
+// StreamUserSubscriptions includes the time spent in fn, since rows are
+// read while it runs.
+func (r *instrumentedSubscriptions) StreamUserSubscriptions(ctx context.Context, userID uuid.UUID, serviceName string, includeArchived bool, fn func(sub model.Subscription) error) (err error) {
+	ctx, end := r.startQuery(ctx, "StreamUserSubscriptions")
+	defer func() { end(err) }()
+	return r.next.StreamUserSubscriptions(ctx, userID, serviceName, includeArchived, fn)
+}
+
+func (r *instrumentedSubscriptions) GetSubscription(ctx context.Context, id uuid.UUID) (_ model.Subscription, err error) {
+	ctx, end := r.startQuery(ctx, "GetSubscription")
+	defer func() { end(err) }()
+	return r.next.GetSubscription(ctx, id)
+}
+
+func (r *instrumentedSubscriptions) UpdateSubscription(ctx context.Context, sub model.Subscription) (err error) {
+	ctx, end := r.startQuery(ctx, "UpdateSubscription")
+	defer func() { end(err) }()
+	return r.next.UpdateSubscription(ctx, sub)
+}
+
+func (r *instrumentedSubscriptions) DeleteSubscription(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, end := r.startQuery(ctx, "DeleteSubscription")
+	defer func() { end(err) }()
+	return r.next.DeleteSubscription(ctx, id)
+}
+
+func (r *instrumentedSubscriptions) GetTotalCost(ctx context.Context, userID uuid.UUID, serviceName string, startDate, endDate time.Time, includeArchived bool) (_ int, err error) {
+	ctx, end := r.startQuery(ctx, "GetTotalCost")
+	defer func() { end(err) }()
+	return r.next.GetTotalCost(ctx, userID, serviceName, startDate, endDate, includeArchived)
+}
+
+func (r *instrumentedSubscriptions) GetTotalCostSubscriptions(ctx context.Context, userID uuid.UUID, serviceName string, startDate, endDate time.Time, includeArchived bool) (_ []model.Subscription, err error) {
+	ctx, end := r.startQuery(ctx, "GetTotalCostSubscriptions")
+	defer func() { end(err) }()
+	return r.next.GetTotalCostSubscriptions(ctx, userID, serviceName, startDate, endDate, includeArchived)
+}
+
+func (r *instrumentedSubscriptions) GetEndingBetween(ctx context.Context, from, to time.Time) (_ []model.Subscription, err error) {
+	ctx, end := r.startQuery(ctx, "GetEndingBetween")
+	defer func() { end(err) }()
+	return r.next.GetEndingBetween(ctx, from, to)
+}
+
+func (r *instrumentedSubscriptions) GetRenewingOn(ctx context.Context, renewal time.Time) (_ []model.Subscription, err error) {
+	ctx, end := r.startQuery(ctx, "GetRenewingOn")
+	defer func() { end(err) }()
+	return r.next.GetRenewingOn(ctx, renewal)
+}
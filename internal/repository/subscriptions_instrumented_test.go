@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/metrics"
+	"github.com/lavatee/subs/internal/model"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type failingSubscriptions struct {
+	Subscriptions
+	err error
+}
+
+func (f failingSubscriptions) GetSubscription(ctx context.Context, id uuid.UUID) (model.Subscription, error) {
+	return model.Subscription{}, f.err
+}
+
+func TestInstrumentedSubscriptionsObservesOutcome(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	subs := newInstrumentedSubscriptions(failingSubscriptions{err: errors.New("boom")}, provider.Tracer("test"))
+	failed := metrics.DBQueryDuration.WithLabelValues("GetSubscription", "error").(prometheus.Histogram)
+	before := sampleCount(t, failed)
+
+	if _, err := subs.GetSubscription(context.Background(), uuid.New()); err == nil {
+		t.Fatal("expected the error of the wrapped repository")
+	}
+	if got := sampleCount(t, failed) - before; got != 1 {
+		t.Fatalf("samples: got %d, want 1", got)
+	}
+	spans := recorder.Ended()
+	if len(spans) != 1 || spans[0].Name() != "SubscriptionsPostgres.GetSubscription" || spans[0].Status().Code != codes.Error {
+		t.Fatalf("spans: got %+v", spans)
+	}
+}
+
+func sampleCount(t *testing.T, h prometheus.Histogram) uint64 {
+	t.Helper()
+	var m dto.Metric
+	if err := h.Write(&m); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	return m.GetHistogram().GetSampleCount()
+}
@@ -6,17 +6,20 @@ import (
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/jmoiron/sqlx"
 	"github.com/lavatee/subs/internal/model"
 )
 
 type SubscriptionsPostgres struct {
-	db *sqlx.DB
+	db     DBTX
+	reader DBTX
 }
 
-func NewSubscriptionsPostgres(db *sqlx.DB) *SubscriptionsPostgres {
+// NewSubscriptionsPostgres writes through db and sends read-only queries to
+// reader. Pass the same handle twice to read from it as well.
+func NewSubscriptionsPostgres(db, reader DBTX) *SubscriptionsPostgres {
 	return &SubscriptionsPostgres{
-		db: db,
+		db:     db,
+		reader: reader,
 	}
 }
 
@@ -28,7 +31,54 @@ func (r *SubscriptionsPostgres) CreateSubscription(ctx context.Context, sub mode
 	return err
 }
 
-func (r *SubscriptionsPostgres) GetUserSubscriptions(ctx context.Context, userID uuid.UUID, serviceName string) ([]model.Subscription, error) {
+// CreateSubscriptions inserts subs in one COPY, all or none.
+func (r *SubscriptionsPostgres) CreateSubscriptions(ctx context.Context, subs []model.Subscription) error {
+	rows := make([][]interface{}, 0, len(subs))
+	for _, sub := range subs {
+		rows = append(rows, []interface{}{sub.ID, sub.ServiceName, sub.Price, sub.UserID, sub.StartDate, sub.EndDate, sub.CreatedAt})
+	}
+	return copyIn(ctx, r.db, subscriptionsTable, []string{"id", "service_name", "price", "user_id", "start_date", "end_date", "created_at"}, rows)
+}
+
+func (r *SubscriptionsPostgres) GetUserSubscriptions(ctx context.Context, userID uuid.UUID, serviceName string, includeArchived bool) ([]model.Subscription, error) {
+	query, args := userSubscriptionsQuery(userID, serviceName, includeArchived)
+
+	var subs []model.Subscription
+	if err := r.reader.SelectContext(ctx, &subs, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get subscriptions: %w", err)
+	}
+
+	return subs, nil
+}
+
+// StreamUserSubscriptions calls fn for each subscription GetUserSubscriptions
+// would return, in the same order, reading rows from the cursor one at a
+// time. An error from fn stops the stream and is returned.
+func (r *SubscriptionsPostgres) StreamUserSubscriptions(ctx context.Context, userID uuid.UUID, serviceName string, includeArchived bool, fn func(sub model.Subscription) error) error {
+	query, args := userSubscriptionsQuery(userID, serviceName, includeArchived)
+
+	rows, err := r.reader.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to stream subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sub model.Subscription
+		if err := rows.StructScan(&sub); err != nil {
+			return fmt.Errorf("failed to stream subscriptions: %w", err)
+		}
+		if err := fn(sub); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to stream subscriptions: %w", err)
+	}
+	return nil
+}
+
+func userSubscriptionsQuery(userID uuid.UUID, serviceName string, includeArchived bool) (string, []interface{}) {
 	var userIDArg interface{} = userID
 	if userID == uuid.Nil {
 		userIDArg = nil
@@ -43,14 +93,9 @@ func (r *SubscriptionsPostgres) GetUserSubscriptions(ctx context.Context, userID
     FROM %s
     WHERE ($1::uuid IS NULL OR user_id = $1)
     AND ($2::text IS NULL OR service_name = $2)
-    ORDER BY created_at DESC`, subscriptionsTable)
+    ORDER BY created_at DESC`, subscriptionsSource(includeArchived))
 
-	var subs []model.Subscription
-	if err := r.db.SelectContext(ctx, &subs, query, userIDArg, serviceNameArg); err != nil {
-		return nil, fmt.Errorf("failed to get subscriptions: %w", err)
-	}
-
-	return subs, nil
+	return query, []interface{}{userIDArg, serviceNameArg}
 }
 
 func (r *SubscriptionsPostgres) GetSubscription(ctx context.Context, id uuid.UUID) (model.Subscription, error) {
@@ -58,7 +103,7 @@ func (r *SubscriptionsPostgres) GetSubscription(ctx context.Context, id uuid.UUI
 	FROM %s
 	WHERE id = $1`, subscriptionsTable)
 	var sub model.Subscription
-	if err := r.db.GetContext(ctx, &sub, query, id); err != nil {
+	if err := r.reader.GetContext(ctx, &sub, query, id); err != nil {
 		return model.Subscription{}, err
 	}
 	return sub, nil
@@ -91,7 +136,51 @@ func (r *SubscriptionsPostgres) DeleteSubscription(ctx context.Context, id uuid.
 	return nil
 }
 
-func (r *SubscriptionsPostgres) GetTotalCost(ctx context.Context, userID uuid.UUID, serviceName string, startDate, endDate time.Time) (int, error) {
+func (r *SubscriptionsPostgres) GetTotalCost(ctx context.Context, userID uuid.UUID, serviceName string, startDate, endDate time.Time, includeArchived bool) (int, error) {
+	args := totalCostArgs(userID, serviceName, startDate, endDate)
+
+	// The ledger only tracks live subscriptions.
+	if !includeArchived && monthlySpendCovers(startDate, endDate) {
+		return totalFromMonthlySpend(ctx, r.reader, args[0], args[1], args[2], args[3])
+	}
+
+	query := fmt.Sprintf(`SELECT COALESCE(SUM(price), 0) 
+    FROM %s 
+    WHERE %s`, subscriptionsSource(includeArchived), totalCostWhere)
+
+	var total int
+	if err := r.reader.GetContext(ctx, &total, query, args...); err != nil {
+		return 0, fmt.Errorf("failed to calculate total cost: %w", err)
+	}
+
+	return total, nil
+}
+
+// GetTotalCostSubscriptions returns the subscriptions whose prices
+// GetTotalCost adds up for the same arguments.
+func (r *SubscriptionsPostgres) GetTotalCostSubscriptions(ctx context.Context, userID uuid.UUID, serviceName string, startDate, endDate time.Time, includeArchived bool) ([]model.Subscription, error) {
+	query := fmt.Sprintf(`SELECT id, service_name, price, user_id, start_date, end_date, created_at
+    FROM %s
+    WHERE %s
+    ORDER BY start_date, id`, subscriptionsSource(includeArchived), totalCostWhere)
+
+	var subs []model.Subscription
+	if err := r.reader.SelectContext(ctx, &subs, query, totalCostArgs(userID, serviceName, startDate, endDate)...); err != nil {
+		return nil, fmt.Errorf("failed to get total cost subscriptions: %w", err)
+	}
+
+	return subs, nil
+}
+
+// totalCostWhere matches the subscriptions of a user and service active at
+// some point of a window, given by totalCostArgs.
+const totalCostWhere = `($1::uuid IS NULL OR user_id = $1)
+    AND ($2::text IS NULL OR service_name = $2)
+    AND ($3::timestamp IS NULL OR (end_date IS NULL OR end_date >= $3))
+    AND ($4::timestamp IS NULL OR start_date <= $4)`
+
+// totalCostArgs passes zero filters as NULL.
+func totalCostArgs(userID uuid.UUID, serviceName string, startDate, endDate time.Time) []interface{} {
 	var userIDArg interface{} = userID
 	if userID == uuid.Nil {
 		userIDArg = nil
@@ -112,17 +201,47 @@ func (r *SubscriptionsPostgres) GetTotalCost(ctx context.Context, userID uuid.UU
 		endDateArg = nil
 	}
 
-	query := fmt.Sprintf(`SELECT COALESCE(SUM(price), 0) 
-    FROM %s 
-    WHERE ($1::uuid IS NULL OR user_id = $1)
-    AND ($2::text IS NULL OR service_name = $2)
-    AND ($3::timestamp IS NULL OR (end_date IS NULL OR end_date >= $3))
-    AND ($4::timestamp IS NULL OR start_date <= $4)`, subscriptionsTable)
+	return []interface{}{userIDArg, serviceNameArg, startDateArg, endDateArg}
+}
 
-	var total int
-	if err := r.db.GetContext(ctx, &total, query, userIDArg, serviceNameArg, startDateArg, endDateArg); err != nil {
-		return 0, fmt.Errorf("failed to calculate total cost: %w", err)
+// GetEndingBetween returns live subscriptions whose end_date is in [from, to).
+func (r *SubscriptionsPostgres) GetEndingBetween(ctx context.Context, from, to time.Time) ([]model.Subscription, error) {
+	query := fmt.Sprintf(`SELECT id, service_name, price, user_id, start_date, end_date, created_at
+	FROM %s
+	WHERE end_date >= $1 AND end_date < $2
+	ORDER BY end_date, id`, subscriptionsTable)
+
+	var subs []model.Subscription
+	if err := r.reader.SelectContext(ctx, &subs, query, from, to); err != nil {
+		return nil, fmt.Errorf("failed to get ending subscriptions: %w", err)
 	}
 
-	return total, nil
+	return subs, nil
+}
+
+// GetRenewingOn returns live subscriptions charged again on renewal, the first
+// day of a month: those started before it and not ended before it.
+func (r *SubscriptionsPostgres) GetRenewingOn(ctx context.Context, renewal time.Time) ([]model.Subscription, error) {
+	query := fmt.Sprintf(`SELECT id, service_name, price, user_id, start_date, end_date, created_at
+	FROM %s
+	WHERE start_date < $1 AND (end_date IS NULL OR end_date >= $1)
+	ORDER BY user_id, id`, subscriptionsTable)
+
+	var subs []model.Subscription
+	if err := r.reader.SelectContext(ctx, &subs, query, renewal); err != nil {
+		return nil, fmt.Errorf("failed to get renewing subscriptions: %w", err)
+	}
+
+	return subs, nil
+}
+
+// subscriptionsSource is the relation to select subscriptions from: the live
+// table alone or together with the archive.
+func subscriptionsSource(includeArchived bool) string {
+	if !includeArchived {
+		return subscriptionsTable
+	}
+	return fmt.Sprintf(`(SELECT id, service_name, price, user_id, start_date, end_date, created_at FROM %s
+	UNION ALL
+	SELECT id, service_name, price, user_id, start_date, end_date, created_at FROM %s) AS %s`, subscriptionsTable, subscriptionsArchiveTable, subscriptionsTable)
 }
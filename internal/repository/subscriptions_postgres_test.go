@@ -0,0 +1,16 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/lavatee/subs/internal/repository"
+	"github.com/lavatee/subs/internal/repository/repotest"
+)
+
+func TestSubscriptionsPostgres(t *testing.T) {
+	db := testDB(t)
+	repotest.RunSubscriptions(t, func(t *testing.T) repository.Subscriptions {
+		truncate(t, db, "subscriptions", "monthly_spend")
+		return repository.NewSubscriptionsPostgres(db, db)
+	})
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+
+	txRetryBaseDelay = 10 * time.Millisecond
+	txRetryMaxDelay  = time.Second
+)
+
+// TxOptions controls how WithinTx opens and retries transactions.
+type TxOptions struct {
+	Isolation sql.IsolationLevel
+	ReadOnly  bool
+	// MaxRetries is how many times a transaction is re-run after a
+	// serialization failure or a deadlock. Zero disables retries.
+	MaxRetries int
+}
+
+type TxOption func(*TxOptions)
+
+func WithIsolation(level sql.IsolationLevel) TxOption {
+	return func(o *TxOptions) {
+		o.Isolation = level
+	}
+}
+
+func WithMaxRetries(n int) TxOption {
+	return func(o *TxOptions) {
+		o.MaxRetries = n
+	}
+}
+
+func ReadOnly() TxOption {
+	return func(o *TxOptions) {
+		o.ReadOnly = true
+	}
+}
+
+// ParseIsolationLevel maps SQL names such as "repeatable read" to sql.IsolationLevel.
+// An empty string selects the database default.
+func ParseIsolationLevel(name string) (sql.IsolationLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "default":
+		return sql.LevelDefault, nil
+	case "read committed":
+		return sql.LevelReadCommitted, nil
+	case "repeatable read":
+		return sql.LevelRepeatableRead, nil
+	case "serializable":
+		return sql.LevelSerializable, nil
+	}
+	return sql.LevelDefault, fmt.Errorf("unknown isolation level %q", name)
+}
+
+// WithinTx runs fn with repositories bound to a single transaction. The
+// transaction is committed when fn returns nil and rolled back otherwise.
+// Serialization failures and deadlocks re-run fn from scratch, so fn must not
+// have side effects outside the database. Calling WithinTx on repositories that
+// are already transactional reuses the outer transaction; asking it for a
+// stricter isolation level than the outer transaction's is an error, since
+// the level of a running transaction cannot be raised.
+func (r *Repository) WithinTx(ctx context.Context, fn func(repos *Repository) error, opts ...TxOption) error {
+	options := r.txOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if r.tx != nil {
+		if isolationRank(options.Isolation) > isolationRank(r.txOptions.Isolation) {
+			return fmt.Errorf("nested transaction requires %s isolation, outer transaction runs at %s",
+				isolationName(options.Isolation), isolationName(r.txOptions.Isolation))
+		}
+		return fn(r)
+	}
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = r.runTx(ctx, options, fn)
+		if err == nil || !isRetryable(err) || attempt >= options.MaxRetries {
+			return err
+		}
+		if err := sleepCtx(ctx, retryDelay(attempt)); err != nil {
+			return err
+		}
+	}
+}
+
+// isolationRank orders isolation levels by strength as Postgres implements
+// them: the default is read committed and read uncommitted behaves like it.
+func isolationRank(level sql.IsolationLevel) sql.IsolationLevel {
+	switch level {
+	case sql.LevelDefault, sql.LevelReadUncommitted:
+		return sql.LevelReadCommitted
+	}
+	return level
+}
+
+func isolationName(level sql.IsolationLevel) string {
+	return strings.ToLower(isolationRank(level).String())
+}
+
+func (r *Repository) runTx(ctx context.Context, options TxOptions, fn func(repos *Repository) error) error {
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{
+		Isolation: options.Isolation,
+		ReadOnly:  options.ReadOnly,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	if err := fn(newTxRepository(tx, options)); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rbErr)
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+func isRetryable(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == serializationFailure || pqErr.Code == deadlockDetected
+}
+
+func retryDelay(attempt int) time.Duration {
+	delay := txRetryBaseDelay << attempt
+	if delay > txRetryMaxDelay || delay <= 0 {
+		delay = txRetryMaxDelay
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
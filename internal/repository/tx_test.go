@@ -0,0 +1,142 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/model"
+	"github.com/lavatee/subs/internal/repository"
+	"github.com/lib/pq"
+)
+
+func newTestSubscription() model.Subscription {
+	return model.Subscription{
+		ID:          uuid.New(),
+		ServiceName: "Netflix",
+		Price:       599,
+		UserID:      uuid.New(),
+		StartDate:   time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+		CreatedAt:   time.Now().UTC(),
+	}
+}
+
+func TestWithinTx(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	repo := repository.NewRepository(&repository.Cluster{Primary: db}, repository.TxOptions{MaxRetries: 3})
+
+	t.Run("commits on success", func(t *testing.T) {
+		truncate(t, db, "subscriptions", "monthly_spend")
+		sub := newTestSubscription()
+		err := repo.WithinTx(ctx, func(repos *repository.Repository) error {
+			return repos.Subscriptions.CreateSubscription(ctx, sub)
+		})
+		if err != nil {
+			t.Fatalf("WithinTx: %v", err)
+		}
+		if _, err := repo.Subscriptions.GetSubscription(ctx, sub.ID); err != nil {
+			t.Fatalf("GetSubscription after commit: %v", err)
+		}
+	})
+
+	t.Run("rolls back on error", func(t *testing.T) {
+		truncate(t, db, "subscriptions", "monthly_spend")
+		sub := newTestSubscription()
+		failure := errors.New("boom")
+		err := repo.WithinTx(ctx, func(repos *repository.Repository) error {
+			if err := repos.Subscriptions.CreateSubscription(ctx, sub); err != nil {
+				return err
+			}
+			return failure
+		})
+		if !errors.Is(err, failure) {
+			t.Fatalf("WithinTx: want %v, got %v", failure, err)
+		}
+		if _, err := repo.Subscriptions.GetSubscription(ctx, sub.ID); err == nil {
+			t.Fatal("GetSubscription after rollback: want error, got nil")
+		}
+	})
+
+	t.Run("nested calls share the outer transaction", func(t *testing.T) {
+		truncate(t, db, "subscriptions", "monthly_spend")
+		sub := newTestSubscription()
+		failure := errors.New("boom")
+		err := repo.WithinTx(ctx, func(outer *repository.Repository) error {
+			err := outer.WithinTx(ctx, func(inner *repository.Repository) error {
+				return inner.Subscriptions.CreateSubscription(ctx, sub)
+			})
+			if err != nil {
+				return err
+			}
+			return failure
+		})
+		if !errors.Is(err, failure) {
+			t.Fatalf("WithinTx: want %v, got %v", failure, err)
+		}
+		if _, err := repo.Subscriptions.GetSubscription(ctx, sub.ID); err == nil {
+			t.Fatal("inner write survived the outer rollback")
+		}
+	})
+
+	t.Run("nested calls cannot raise the isolation level", func(t *testing.T) {
+		called := false
+		err := repo.WithinTx(ctx, func(outer *repository.Repository) error {
+			return outer.WithinTx(ctx, func(inner *repository.Repository) error {
+				called = true
+				return nil
+			}, repository.WithIsolation(sql.LevelRepeatableRead))
+		}, repository.WithIsolation(sql.LevelReadCommitted))
+		if err == nil {
+			t.Fatal("WithinTx: want error, got nil")
+		}
+		if called {
+			t.Fatal("nested fn ran at a weaker isolation level than requested")
+		}
+	})
+
+	t.Run("nested calls may ask for an equal or weaker level", func(t *testing.T) {
+		err := repo.WithinTx(ctx, func(outer *repository.Repository) error {
+			return outer.WithinTx(ctx, func(inner *repository.Repository) error {
+				return nil
+			}, repository.WithIsolation(sql.LevelRepeatableRead))
+		}, repository.WithIsolation(sql.LevelSerializable))
+		if err != nil {
+			t.Fatalf("WithinTx: %v", err)
+		}
+	})
+
+	t.Run("retries serialization failures", func(t *testing.T) {
+		attempts := 0
+		err := repo.WithinTx(ctx, func(repos *repository.Repository) error {
+			attempts++
+			if attempts < 3 {
+				return &pq.Error{Code: "40001"}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("WithinTx: %v", err)
+		}
+		if attempts != 3 {
+			t.Fatalf("want 3 attempts, got %d", attempts)
+		}
+	})
+
+	t.Run("gives up after max retries", func(t *testing.T) {
+		attempts := 0
+		err := repo.WithinTx(ctx, func(repos *repository.Repository) error {
+			attempts++
+			return &pq.Error{Code: "40P01"}
+		}, repository.WithMaxRetries(1))
+		if err == nil {
+			t.Fatal("WithinTx: want error, got nil")
+		}
+		if attempts != 2 {
+			t.Fatalf("want 2 attempts, got %d", attempts)
+		}
+	})
+}
@@ -0,0 +1,235 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/model"
+	"github.com/lib/pq"
+)
+
+type WebhooksPostgres struct {
+	db DBTX
+}
+
+func NewWebhooksPostgres(db DBTX) *WebhooksPostgres {
+	return &WebhooksPostgres{
+		db: db,
+	}
+}
+
+type webhookRow struct {
+	ID        uuid.UUID      `db:"id"`
+	URL       string         `db:"url"`
+	Events    pq.StringArray `db:"events"`
+	Secret    string         `db:"secret"`
+	CreatedAt time.Time      `db:"created_at"`
+}
+
+func (row webhookRow) toModel() model.Webhook {
+	return model.Webhook{
+		ID:        row.ID,
+		URL:       row.URL,
+		Events:    []string(row.Events),
+		Secret:    row.Secret,
+		CreatedAt: row.CreatedAt,
+	}
+}
+
+func (r *WebhooksPostgres) CreateWebhook(ctx context.Context, hook model.Webhook) error {
+	query := fmt.Sprintf(`INSERT INTO %s (id, url, secret, events, created_at)
+	VALUES ($1, $2, $3, $4, $5)`, webhooksTable)
+	_, err := r.db.ExecContext(ctx, query, hook.ID, hook.URL, hook.Secret, pq.StringArray(hook.Events), hook.CreatedAt)
+	return err
+}
+
+func (r *WebhooksPostgres) GetWebhooks(ctx context.Context) ([]model.Webhook, error) {
+	query := fmt.Sprintf(`SELECT id, url, secret, events, created_at FROM %s ORDER BY created_at DESC`, webhooksTable)
+	var rows []webhookRow
+	if err := r.db.SelectContext(ctx, &rows, query); err != nil {
+		return nil, fmt.Errorf("failed to get webhooks: %w", err)
+	}
+	hooks := make([]model.Webhook, 0, len(rows))
+	for _, row := range rows {
+		hooks = append(hooks, row.toModel())
+	}
+	return hooks, nil
+}
+
+func (r *WebhooksPostgres) GetWebhook(ctx context.Context, id uuid.UUID) (model.Webhook, error) {
+	query := fmt.Sprintf(`SELECT id, url, secret, events, created_at FROM %s WHERE id = $1`, webhooksTable)
+	var row webhookRow
+	if err := r.db.GetContext(ctx, &row, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Webhook{}, fmt.Errorf("webhook %s: %w", id, model.ErrNotFound)
+		}
+		return model.Webhook{}, err
+	}
+	return row.toModel(), nil
+}
+
+func (r *WebhooksPostgres) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id = $1`, webhooksTable)
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("webhook %s: %w", id, model.ErrNotFound)
+	}
+	return nil
+}
+
+// EnqueueDeliveries schedules payload for every webhook subscribed to event
+// and returns how many deliveries were created. A non-empty dedupKey makes the
+// call idempotent per webhook.
+func (r *WebhooksPostgres) EnqueueDeliveries(ctx context.Context, event string, payload []byte, dedupKey string) (int, error) {
+	var dedupKeyArg interface{} = dedupKey
+	if dedupKey == "" {
+		dedupKeyArg = nil
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (id, webhook_id, event, payload, dedup_key)
+	SELECT gen_random_uuid(), id, $1, $2, $3
+	FROM %s
+	WHERE $1 = ANY(events)
+	ON CONFLICT (webhook_id, dedup_key) DO NOTHING`, webhookDeliveriesTable, webhooksTable)
+
+	result, err := r.db.ExecContext(ctx, query, event, payload, dedupKeyArg)
+	if err != nil {
+		return 0, fmt.Errorf("failed to enqueue webhook deliveries: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(rows), nil
+}
+
+// EnqueueBatch schedules each of payloads, all of the same event, for every
+// webhook subscribed to it and returns how many deliveries were created.
+func (r *WebhooksPostgres) EnqueueBatch(ctx context.Context, event string, payloads [][]byte) (int, error) {
+	if len(payloads) == 0 {
+		return 0, nil
+	}
+	texts := make([]string, 0, len(payloads))
+	for _, payload := range payloads {
+		texts = append(texts, string(payload))
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (id, webhook_id, event, payload)
+	SELECT gen_random_uuid(), w.id, $1, p.payload::jsonb
+	FROM %s AS w, unnest($2::text[]) AS p(payload)
+	WHERE $1 = ANY(w.events)`, webhookDeliveriesTable, webhooksTable)
+
+	result, err := r.db.ExecContext(ctx, query, event, pq.StringArray(texts))
+	if err != nil {
+		return 0, fmt.Errorf("failed to enqueue webhook deliveries: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(rows), nil
+}
+
+// ClaimDueDeliveries returns up to limit pending deliveries whose time has
+// come and pushes their next attempt lease into the future, so that other
+// dispatchers skip them while they are being sent.
+func (r *WebhooksPostgres) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]model.PendingDelivery, error) {
+	query := fmt.Sprintf(`UPDATE %s AS d
+	SET next_attempt_at = now() + make_interval(secs => $2)
+	FROM %s AS w
+	WHERE w.id = d.webhook_id
+	AND d.id IN (
+		SELECT id FROM %s
+		WHERE status = 'pending' AND next_attempt_at <= now()
+		ORDER BY next_attempt_at
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	)
+	RETURNING d.id, d.webhook_id, d.event, d.payload, d.status, d.attempts, d.last_error, d.response_status,
+	d.next_attempt_at, d.created_at, d.delivered_at, w.url, w.secret`, webhookDeliveriesTable, webhooksTable, webhookDeliveriesTable)
+
+	var deliveries []model.PendingDelivery
+	if err := r.db.SelectContext(ctx, &deliveries, query, limit, lease.Seconds()); err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// RecordDeliveryAttempt stores the outcome of sending a delivery.
+func (r *WebhooksPostgres) RecordDeliveryAttempt(ctx context.Context, delivery model.WebhookDelivery) error {
+	query := fmt.Sprintf(`UPDATE %s
+	SET status = $2, attempts = $3, last_error = $4, response_status = $5, next_attempt_at = $6, delivered_at = $7
+	WHERE id = $1`, webhookDeliveriesTable)
+	_, err := r.db.ExecContext(ctx, query, delivery.ID, delivery.Status, delivery.Attempts, delivery.LastError,
+		delivery.ResponseStatus, delivery.NextAttemptAt, delivery.DeliveredAt)
+	return err
+}
+
+func (r *WebhooksPostgres) GetDeliveries(ctx context.Context, webhookID uuid.UUID, limit int) ([]model.WebhookDelivery, error) {
+	query := fmt.Sprintf(`SELECT id, webhook_id, event, payload, status, attempts, last_error, response_status,
+	next_attempt_at, created_at, delivered_at
+	FROM %s
+	WHERE webhook_id = $1
+	ORDER BY created_at DESC
+	LIMIT $2`, webhookDeliveriesTable)
+
+	var deliveries []model.WebhookDelivery
+	if err := r.db.SelectContext(ctx, &deliveries, query, webhookID, limit); err != nil {
+		return nil, fmt.Errorf("failed to get webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// RedeliverDelivery puts a delivery of the given webhook back into the queue
+// with a fresh attempt budget.
+func (r *WebhooksPostgres) RedeliverDelivery(ctx context.Context, webhookID, id uuid.UUID) (model.WebhookDelivery, error) {
+	query := fmt.Sprintf(`UPDATE %s
+	SET status = 'pending', attempts = 0, next_attempt_at = now(), delivered_at = NULL
+	WHERE id = $1 AND webhook_id = $2
+	RETURNING id, webhook_id, event, payload, status, attempts, last_error, response_status,
+	next_attempt_at, created_at, delivered_at`, webhookDeliveriesTable)
+
+	var delivery model.WebhookDelivery
+	if err := r.db.GetContext(ctx, &delivery, query, id, webhookID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.WebhookDelivery{}, fmt.Errorf("webhook delivery %s: %w", id, model.ErrNotFound)
+		}
+		return model.WebhookDelivery{}, err
+	}
+	return delivery, nil
+}
+
+// GetScannedUntil returns how far the lifecycle scanner has emitted event,
+// or the zero time if it never has.
+func (r *WebhooksPostgres) GetScannedUntil(ctx context.Context, event string) (time.Time, error) {
+	query := fmt.Sprintf(`SELECT scanned_until FROM %s WHERE event = $1`, webhookScansTable)
+	var until time.Time
+	err := r.db.GetContext(ctx, &until, query, event)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get webhook scan: %w", err)
+	}
+	return until.UTC(), nil
+}
+
+func (r *WebhooksPostgres) SetScannedUntil(ctx context.Context, event string, until time.Time) error {
+	query := fmt.Sprintf(`INSERT INTO %s (event, scanned_until) VALUES ($1, $2)
+	ON CONFLICT (event) DO UPDATE SET scanned_until = EXCLUDED.scanned_until`, webhookScansTable)
+	if _, err := r.db.ExecContext(ctx, query, event, until); err != nil {
+		return fmt.Errorf("failed to set webhook scan: %w", err)
+	}
+	return nil
+}
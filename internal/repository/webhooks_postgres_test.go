@@ -0,0 +1,117 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/model"
+	"github.com/lavatee/subs/internal/repository"
+)
+
+func TestWebhooksPostgres(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	truncate(t, db, "webhooks CASCADE")
+
+	repo := repository.NewWebhooksPostgres(db)
+	created := model.Webhook{ID: uuid.New(), URL: "http://example.com/created", Secret: "s1", Events: []string{model.EventSubscriptionCreated}, CreatedAt: time.Now().UTC()}
+	ending := model.Webhook{ID: uuid.New(), URL: "http://example.com/ending", Secret: "s2", Events: []string{model.EventSubscriptionEndingSoon, model.EventSubscriptionCreated}, CreatedAt: time.Now().UTC()}
+	for _, hook := range []model.Webhook{created, ending} {
+		if err := repo.CreateWebhook(ctx, hook); err != nil {
+			t.Fatalf("CreateWebhook: %v", err)
+		}
+	}
+
+	got, err := repo.GetWebhook(ctx, ending.ID)
+	if err != nil {
+		t.Fatalf("GetWebhook: %v", err)
+	}
+	if len(got.Events) != 2 || got.Secret != "s2" {
+		t.Fatalf("GetWebhook returned %+v", got)
+	}
+	if _, err := repo.GetWebhook(ctx, uuid.New()); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("GetWebhook of a missing id: want ErrNotFound, got %v", err)
+	}
+
+	n, err := repo.EnqueueDeliveries(ctx, model.EventSubscriptionCreated, []byte(`{}`), "")
+	if err != nil || n != 2 {
+		t.Fatalf("EnqueueDeliveries(created): want 2, got %d, %v", n, err)
+	}
+	for i, want := range []int{1, 0} {
+		n, err := repo.EnqueueDeliveries(ctx, model.EventSubscriptionEndingSoon, []byte(`{}`), "sub:1")
+		if err != nil || n != want {
+			t.Fatalf("EnqueueDeliveries(ending_soon) call %d: want %d, got %d, %v", i+1, want, n, err)
+		}
+	}
+
+	n, err = repo.EnqueueBatch(ctx, model.EventSubscriptionCreated, [][]byte{[]byte(`{"n":1}`), []byte(`{"n":2}`)})
+	if err != nil || n != 4 {
+		t.Fatalf("EnqueueBatch(created): want 4, got %d, %v", n, err)
+	}
+
+	claimed, err := repo.ClaimDueDeliveries(ctx, 10, time.Minute)
+	if err != nil {
+		t.Fatalf("ClaimDueDeliveries: %v", err)
+	}
+	if len(claimed) != 7 {
+		t.Fatalf("want 7 claimed deliveries, got %d", len(claimed))
+	}
+	if again, err := repo.ClaimDueDeliveries(ctx, 10, time.Minute); err != nil || len(again) != 0 {
+		t.Fatalf("leased deliveries were claimed twice: %d, %v", len(again), err)
+	}
+
+	delivery := claimed[0].WebhookDelivery
+	delivery.Status = model.DeliveryFailed
+	delivery.Attempts = 8
+	if err := repo.RecordDeliveryAttempt(ctx, delivery); err != nil {
+		t.Fatalf("RecordDeliveryAttempt: %v", err)
+	}
+	redelivered, err := repo.RedeliverDelivery(ctx, delivery.WebhookID, delivery.ID)
+	if err != nil {
+		t.Fatalf("RedeliverDelivery: %v", err)
+	}
+	if redelivered.Status != model.DeliveryPending || redelivered.Attempts != 0 {
+		t.Fatalf("RedeliverDelivery returned %+v", redelivered)
+	}
+	if _, err := repo.RedeliverDelivery(ctx, uuid.New(), delivery.ID); !errors.Is(err, model.ErrNotFound) {
+		t.Fatalf("RedeliverDelivery with a foreign webhook: want ErrNotFound, got %v", err)
+	}
+
+	if err := repo.DeleteWebhook(ctx, created.ID); err != nil {
+		t.Fatalf("DeleteWebhook: %v", err)
+	}
+	deliveries, err := repo.GetDeliveries(ctx, created.ID, 10)
+	if err != nil {
+		t.Fatalf("GetDeliveries: %v", err)
+	}
+	if len(deliveries) != 0 {
+		t.Fatalf("deliveries of a deleted webhook survived: %d", len(deliveries))
+	}
+}
+
+func TestWebhookScans(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	truncate(t, db, "webhook_scans")
+
+	repo := repository.NewWebhooksPostgres(db)
+	until, err := repo.GetScannedUntil(ctx, model.EventSubscriptionEnded)
+	if err != nil || !until.IsZero() {
+		t.Fatalf("GetScannedUntil before any scan: got %s, %v", until, err)
+	}
+	for _, want := range []time.Time{
+		time.Date(2025, time.March, 1, 12, 0, 0, 0, time.UTC),
+		time.Date(2025, time.March, 2, 12, 0, 0, 0, time.UTC),
+	} {
+		if err := repo.SetScannedUntil(ctx, model.EventSubscriptionEnded, want); err != nil {
+			t.Fatalf("SetScannedUntil: %v", err)
+		}
+		until, err := repo.GetScannedUntil(ctx, model.EventSubscriptionEnded)
+		if err != nil || !until.Equal(want) {
+			t.Fatalf("GetScannedUntil: want %s, got %s, %v", want, until, err)
+		}
+	}
+}
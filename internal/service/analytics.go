@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lavatee/subs/internal/logging"
+	"github.com/lavatee/subs/internal/model"
+	"github.com/lavatee/subs/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultTopServices = 10
+	maxTopServices     = 100
+	// Monthly series cover the last defaultSeriesMonths months unless a
+	// window is given, and at most maxSeriesMonths.
+	defaultSeriesMonths = 12
+	maxSeriesMonths     = 120
+)
+
+type AnalyticsService struct {
+	repo   *repository.Repository
+	logger *logrus.Logger
+}
+
+func NewAnalyticsService(repo *repository.Repository, logger *logrus.Logger) *AnalyticsService {
+	return &AnalyticsService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// GetTopServices returns the services with the highest spend; limit 0 means
+// the default of 10.
+func (s *AnalyticsService) GetTopServices(ctx context.Context, filter model.AnalyticsFilter, limit int) ([]model.ServiceSpend, error) {
+	if limit == 0 {
+		limit = defaultTopServices
+	}
+	if limit < 0 || limit > maxTopServices {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", model.ErrInvalidInput, maxTopServices)
+	}
+	if err := validateAnalyticsWindow(filter); err != nil {
+		return nil, err
+	}
+	services, err := s.repo.Analytics.GetTopServices(ctx, filter, limit)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to get top services from repository: %v", err)
+		return nil, err
+	}
+	return services, nil
+}
+
+func (s *AnalyticsService) GetAveragePrices(ctx context.Context, filter model.AnalyticsFilter) ([]model.ServicePrice, error) {
+	if err := validateAnalyticsWindow(filter); err != nil {
+		return nil, err
+	}
+	prices, err := s.repo.Analytics.GetAveragePrices(ctx, filter)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to get average prices from repository: %v", err)
+		return nil, err
+	}
+	return prices, nil
+}
+
+func (s *AnalyticsService) GetActiveSubscriptions(ctx context.Context, filter model.AnalyticsFilter) ([]model.ActiveSubscriptions, error) {
+	filter, err := seriesWindow(filter, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	active, err := s.repo.Analytics.GetActiveSubscriptions(ctx, filter)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to get active subscriptions from repository: %v", err)
+		return nil, err
+	}
+	return active, nil
+}
+
+func (s *AnalyticsService) GetChurn(ctx context.Context, filter model.AnalyticsFilter) ([]model.SubscriptionChurn, error) {
+	filter, err := seriesWindow(filter, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	churn, err := s.repo.Analytics.GetChurn(ctx, filter)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to get churn from repository: %v", err)
+		return nil, err
+	}
+	return churn, nil
+}
+
+func (s *AnalyticsService) GetUserSpendStats(ctx context.Context, filter model.AnalyticsFilter) (model.UserSpendStats, error) {
+	if err := validateAnalyticsWindow(filter); err != nil {
+		return model.UserSpendStats{}, err
+	}
+	stats, err := s.repo.Analytics.GetUserSpendStats(ctx, filter)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to get user spend stats from repository: %v", err)
+		return model.UserSpendStats{}, err
+	}
+	return stats, nil
+}
+
+func validateAnalyticsWindow(filter model.AnalyticsFilter) error {
+	if !filter.StartDate.IsZero() && !filter.EndDate.IsZero() && filter.StartDate.After(filter.EndDate) {
+		return fmt.Errorf("%w: start_date must not be after end_date", model.ErrInvalidInput)
+	}
+	return nil
+}
+
+// seriesWindow fills in the window of a monthly series: it ends with the
+// current month and spans defaultSeriesMonths unless given otherwise.
+func seriesWindow(filter model.AnalyticsFilter, now time.Time) (model.AnalyticsFilter, error) {
+	if filter.EndDate.IsZero() {
+		filter.EndDate = monthStart(now)
+		if !filter.StartDate.IsZero() && filter.StartDate.After(filter.EndDate) {
+			filter.EndDate = filter.StartDate
+		}
+	}
+	if filter.StartDate.IsZero() {
+		filter.StartDate = filter.EndDate.AddDate(0, 1-defaultSeriesMonths, 0)
+	}
+	if err := validateAnalyticsWindow(filter); err != nil {
+		return model.AnalyticsFilter{}, err
+	}
+	if filter.EndDate.After(filter.StartDate.AddDate(0, maxSeriesMonths-1, 0)) {
+		return model.AnalyticsFilter{}, fmt.Errorf("%w: the window must not exceed %d months", model.ErrInvalidInput, maxSeriesMonths)
+	}
+	return filter, nil
+}
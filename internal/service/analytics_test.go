@@ -0,0 +1,47 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lavatee/subs/internal/model"
+)
+
+func TestSeriesWindow(t *testing.T) {
+	now := time.Date(2025, 7, 15, 0, 0, 0, 0, time.UTC)
+	month := func(year int, m time.Month) time.Time {
+		return time.Date(year, m, 1, 0, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name       string
+		start, end time.Time
+		wantStart  time.Time
+		wantEnd    time.Time
+	}{
+		{name: "defaults to the last 12 months", wantStart: month(2024, time.August), wantEnd: month(2025, time.July)},
+		{name: "open end runs to this month", start: month(2025, time.January), wantStart: month(2025, time.January), wantEnd: month(2025, time.July)},
+		{name: "future start", start: month(2026, time.January), wantStart: month(2026, time.January), wantEnd: month(2026, time.January)},
+		{name: "open start covers 12 months", end: month(2025, time.March), wantStart: month(2024, time.April), wantEnd: month(2025, time.March)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := seriesWindow(model.AnalyticsFilter{StartDate: tt.start, EndDate: tt.end}, now)
+			if err != nil {
+				t.Fatalf("seriesWindow: %v", err)
+			}
+			if !got.StartDate.Equal(tt.wantStart) || !got.EndDate.Equal(tt.wantEnd) {
+				t.Fatalf("got %s..%s, want %s..%s", got.StartDate, got.EndDate, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+
+	for _, filter := range []model.AnalyticsFilter{
+		{StartDate: month(2025, time.May), EndDate: month(2025, time.April)},
+		{StartDate: month(2010, time.January), EndDate: month(2025, time.January)},
+	} {
+		if _, err := seriesWindow(filter, now); !errors.Is(err, model.ErrInvalidInput) {
+			t.Errorf("seriesWindow(%s..%s): want ErrInvalidInput, got %v", filter.StartDate, filter.EndDate, err)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/lavatee/subs/internal/model"
+	"github.com/lavatee/subs/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+const archiveBatchSize = 1000
+
+type ArchiveService struct {
+	repo   *repository.Repository
+	logger *logrus.Logger
+}
+
+func NewArchiveService(repo *repository.Repository, logger *logrus.Logger) *ArchiveService {
+	return &ArchiveService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// ArchiveEnded moves subscriptions that ended more than horizon ago into the
+// archive, in batches so that no single statement holds locks for long. Each
+// batch records a subscription.archived event per subscription, so that
+// change feed clients drop them.
+func (s *ArchiveService) ArchiveEnded(ctx context.Context, horizon time.Duration) (int, error) {
+	endedBefore := time.Now().UTC().Add(-horizon)
+	archived := 0
+	for {
+		var moved []model.Subscription
+		err := s.repo.WithinTx(ctx, func(repos *repository.Repository) error {
+			var err error
+			moved, err = repos.Archive.ArchiveEnded(ctx, endedBefore, archiveBatchSize)
+			if err != nil || len(moved) == 0 {
+				return err
+			}
+			events := make([]model.Event, 0, len(moved))
+			for _, sub := range moved {
+				events = append(events, model.NewSubscriptionEvent(model.EventSubscriptionArchived, sub))
+			}
+			return recordEvents(ctx, repos, model.EventSubscriptionArchived, events)
+		})
+		if err != nil {
+			s.logger.Errorf("Failed to archive subscriptions in repository: %v", err)
+			return archived, err
+		}
+		archived += len(moved)
+		if len(moved) < archiveBatchSize {
+			return archived, nil
+		}
+	}
+}
+
+// RunArchiver archives ended subscriptions every interval until ctx is cancelled.
+func (s *ArchiveService) RunArchiver(ctx context.Context, interval, horizon time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			archived, err := s.ArchiveEnded(ctx, horizon)
+			if err != nil {
+				continue
+			}
+			if archived > 0 {
+				s.logger.Infof("Archived %d subscriptions", archived)
+			}
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/logging"
+	"github.com/lavatee/subs/internal/model"
+	"github.com/lavatee/subs/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+type BudgetsService struct {
+	repo   *repository.Repository
+	logger *logrus.Logger
+}
+
+func NewBudgetsService(repo *repository.Repository, logger *logrus.Logger) *BudgetsService {
+	return &BudgetsService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+func (s *BudgetsService) CreateBudget(ctx context.Context, req model.CreateBudgetRequest) (model.Budget, error) {
+	budget := model.Budget{
+		ID:          uuid.New(),
+		UserID:      req.UserID,
+		ServiceName: req.ServiceName,
+		Period:      req.Period,
+		Amount:      req.Amount,
+		CreatedAt:   time.Now().UTC(),
+	}
+	if err := s.repo.Budgets.CreateBudget(ctx, budget); err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to create budget in repository: %v", err)
+		return model.Budget{}, err
+	}
+	return budget, nil
+}
+
+func (s *BudgetsService) GetBudgets(ctx context.Context, userID uuid.UUID) ([]model.Budget, error) {
+	budgets, err := s.repo.Budgets.GetBudgets(ctx, userID)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to get budgets from repository: %v", err)
+		return nil, err
+	}
+	return budgets, nil
+}
+
+func (s *BudgetsService) GetBudget(ctx context.Context, id uuid.UUID) (model.Budget, error) {
+	budget, err := s.repo.Budgets.GetBudget(ctx, id)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to get budget from repository: %v", err)
+		return model.Budget{}, err
+	}
+	return budget, nil
+}
+
+func (s *BudgetsService) UpdateBudget(ctx context.Context, id uuid.UUID, req model.UpdateBudgetRequest) (model.Budget, error) {
+	var updated model.Budget
+	err := s.repo.WithinTx(ctx, func(repos *repository.Repository) error {
+		budget, err := repos.Budgets.GetBudget(ctx, id)
+		if err != nil {
+			logging.FromContext(ctx, s.logger).Errorf("Failed to get existing budget for update: %v", err)
+			return err
+		}
+		budget.Amount = req.Amount
+		if err := repos.Budgets.UpdateBudget(ctx, budget); err != nil {
+			logging.FromContext(ctx, s.logger).Errorf("Failed to update budget in repository: %v", err)
+			return err
+		}
+		updated = budget
+		return nil
+	})
+	if err != nil {
+		return model.Budget{}, err
+	}
+	return updated, nil
+}
+
+func (s *BudgetsService) DeleteBudget(ctx context.Context, id uuid.UUID) error {
+	if err := s.repo.Budgets.DeleteBudget(ctx, id); err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to delete budget from repository: %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetConsumption reports the budget's spend over its period containing at.
+func (s *BudgetsService) GetConsumption(ctx context.Context, id uuid.UUID, at time.Time) (model.BudgetConsumption, error) {
+	budget, err := s.repo.Budgets.GetBudget(ctx, id)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to get budget from repository: %v", err)
+		return model.BudgetConsumption{}, err
+	}
+	consumption, err := budgetConsumption(ctx, s.repo, budget, at)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to calculate budget consumption: %v", err)
+		return model.BudgetConsumption{}, err
+	}
+	return consumption, nil
+}
+
+// budgetConsumption computes the spend of budget's period containing at with
+// the same query as GetTotalCost.
+func budgetConsumption(ctx context.Context, repos *repository.Repository, budget model.Budget, at time.Time) (model.BudgetConsumption, error) {
+	start, end := budgetPeriod(budget.Period, at)
+	spent, err := repos.Subscriptions.GetTotalCost(ctx, budget.UserID, budget.ServiceName, start, end, false)
+	if err != nil {
+		return model.BudgetConsumption{}, err
+	}
+	return model.NewBudgetConsumption(budget, start, end, spent), nil
+}
+
+// budgetPeriod returns the first and last month of the period containing at.
+func budgetPeriod(period string, at time.Time) (time.Time, time.Time) {
+	if period == model.BudgetYearly {
+		start := time.Date(at.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 11, 0)
+	}
+	start := time.Date(at.Year(), at.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return start, start
+}
+
+// userBudgetsConsumption returns the current consumption of every budget of
+// userID.
+func userBudgetsConsumption(ctx context.Context, repos *repository.Repository, userID uuid.UUID, at time.Time) ([]model.BudgetConsumption, error) {
+	budgets, err := repos.Budgets.GetBudgets(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	consumptions := make([]model.BudgetConsumption, 0, len(budgets))
+	for _, budget := range budgets {
+		consumption, err := budgetConsumption(ctx, repos, budget, at)
+		if err != nil {
+			return nil, err
+		}
+		consumptions = append(consumptions, consumption)
+	}
+	return consumptions, nil
+}
+
+// checkBudgets compares budget consumption before a change of sub with the
+// consumption now. Budgets whose spend grew beyond the limit produce
+// warnings, and those that were within the limit before also raise a
+// budget.exceeded event. repos must be the transaction that made the change.
+func checkBudgets(ctx context.Context, repos *repository.Repository, before []model.BudgetConsumption, sub model.Subscription, at time.Time) ([]model.BudgetWarning, error) {
+	var warnings []model.BudgetWarning
+	for _, previous := range before {
+		current, err := budgetConsumption(ctx, repos, previous.Budget, at)
+		if err != nil {
+			return nil, err
+		}
+		if !current.Exceeded || current.Spent <= previous.Spent {
+			continue
+		}
+		warnings = append(warnings, model.BudgetWarning{
+			Message:     budgetWarningMessage(current),
+			Consumption: current,
+		})
+		if !previous.Exceeded {
+			if err := recordEvent(ctx, repos, model.NewBudgetExceededEvent(current, sub)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return warnings, nil
+}
+
+func budgetWarningMessage(consumption model.BudgetConsumption) string {
+	scope := "all services"
+	if consumption.Budget.ServiceName != "" {
+		scope = consumption.Budget.ServiceName
+	}
+	return fmt.Sprintf("%s budget of %d for %s exceeded: %d spent since %s",
+		consumption.Budget.Period, consumption.Budget.Amount, scope, consumption.Spent, consumption.PeriodStart.Format("01-2006"))
+}
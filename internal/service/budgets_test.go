@@ -0,0 +1,37 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lavatee/subs/internal/model"
+)
+
+func TestBudgetPeriod(t *testing.T) {
+	at := time.Date(2025, time.August, 17, 13, 0, 0, 0, time.UTC)
+	tests := []struct {
+		period     string
+		start, end time.Time
+	}{
+		{period: model.BudgetMonthly, start: time.Date(2025, time.August, 1, 0, 0, 0, 0, time.UTC), end: time.Date(2025, time.August, 1, 0, 0, 0, 0, time.UTC)},
+		{period: model.BudgetYearly, start: time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC), end: time.Date(2025, time.December, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		start, end := budgetPeriod(tt.period, at)
+		if !start.Equal(tt.start) || !end.Equal(tt.end) {
+			t.Errorf("budgetPeriod(%s): want %s..%s, got %s..%s", tt.period, tt.start, tt.end, start, end)
+		}
+	}
+}
+
+func TestNewBudgetConsumption(t *testing.T) {
+	budget := model.Budget{Period: model.BudgetMonthly, Amount: 800}
+	within := model.NewBudgetConsumption(budget, time.Time{}, time.Time{}, 600)
+	if within.Exceeded || within.Remaining != 200 || within.Percent != 75 {
+		t.Fatalf("within the limit: got %+v", within)
+	}
+	over := model.NewBudgetConsumption(budget, time.Time{}, time.Time{}, 1000)
+	if !over.Exceeded || over.Remaining != 0 || over.Percent != 125 {
+		t.Fatalf("over the limit: got %+v", over)
+	}
+}
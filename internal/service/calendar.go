@@ -0,0 +1,143 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/logging"
+	"github.com/lavatee/subs/internal/model"
+	"github.com/lavatee/subs/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultCalendarHorizon is how many months of billing dates the feed lists
+// when no horizon is configured.
+const defaultCalendarHorizon = 12
+
+type CalendarService struct {
+	repo    *repository.Repository
+	logger  *logrus.Logger
+	horizon int
+}
+
+func NewCalendarService(repo *repository.Repository, logger *logrus.Logger, horizonMonths int) *CalendarService {
+	if horizonMonths <= 0 {
+		horizonMonths = defaultCalendarHorizon
+	}
+	return &CalendarService{
+		repo:    repo,
+		logger:  logger,
+		horizon: horizonMonths,
+	}
+}
+
+// IssueToken creates a new feed token for the user. The previous token, if
+// any, stops working.
+func (s *CalendarService) IssueToken(ctx context.Context, userID uuid.UUID) (model.CalendarToken, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return model.CalendarToken{}, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(secret)
+
+	calendarToken := model.CalendarToken{
+		UserID:    userID,
+		Token:     token,
+		TokenHash: hashCalendarToken(token),
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.repo.CalendarTokens.UpsertCalendarToken(ctx, calendarToken); err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to store calendar token: %v", err)
+		return model.CalendarToken{}, err
+	}
+	return calendarToken, nil
+}
+
+func (s *CalendarService) RevokeToken(ctx context.Context, userID uuid.UUID) error {
+	if err := s.repo.CalendarTokens.DeleteCalendarToken(ctx, userID); err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to revoke calendar token: %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetCalendar renders the user's feed if token is the user's current one.
+// A wrong token is reported as model.ErrNotFound, like a missing one.
+func (s *CalendarService) GetCalendar(ctx context.Context, userID uuid.UUID, token string) ([]byte, error) {
+	stored, err := s.repo.CalendarTokens.GetCalendarToken(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(stored.TokenHash), []byte(hashCalendarToken(token))) != 1 {
+		return nil, fmt.Errorf("calendar of user %s: %w", userID, model.ErrNotFound)
+	}
+
+	subscriptions, err := s.repo.Subscriptions.GetUserSubscriptions(ctx, userID, "", false)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to get subscriptions from repository: %v", err)
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	var buf bytes.Buffer
+	writeICal(&buf, calendarEvents(subscriptions, now, s.horizon), now)
+	return buf.Bytes(), nil
+}
+
+func hashCalendarToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// calendarEvent is one all-day entry of the feed.
+type calendarEvent struct {
+	UID         string
+	Date        time.Time
+	Summary     string
+	Description string
+}
+
+// calendarEvents lists, from today on and for horizon months, the billing
+// dates of subs, which fall on the first of every month from start_date to
+// end_date, and the end_date of each subscription.
+func calendarEvents(subs []model.Subscription, now time.Time, horizon int) []calendarEvent {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	until := monthStart(today).AddDate(0, horizon, 0)
+
+	var events []calendarEvent
+	for _, sub := range subs {
+		for billing := sub.StartDate; !billing.After(until); billing = billing.AddDate(0, 1, 0) {
+			if sub.EndDate != nil && billing.After(*sub.EndDate) {
+				break
+			}
+			if billing.Before(today) {
+				continue
+			}
+			events = append(events, calendarEvent{
+				UID:         fmt.Sprintf("%s-%s-billing", sub.ID, billing.Format("20060102")),
+				Date:        billing,
+				Summary:     fmt.Sprintf("%s: %d", sub.ServiceName, sub.Price),
+				Description: fmt.Sprintf("Subscription %s is billed %d.", sub.ServiceName, sub.Price),
+			})
+		}
+		if sub.EndDate != nil && !sub.EndDate.Before(today) && !sub.EndDate.After(until) {
+			events = append(events, calendarEvent{
+				UID:         fmt.Sprintf("%s-%s-end", sub.ID, sub.EndDate.Format("20060102")),
+				Date:        *sub.EndDate,
+				Summary:     fmt.Sprintf("%s ends", sub.ServiceName),
+				Description: fmt.Sprintf("Subscription %s ends this month. Cancel it before renewal if you no longer need it.", sub.ServiceName),
+			})
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Date.Before(events[j].Date) })
+	return events
+}
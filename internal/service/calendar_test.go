@@ -0,0 +1,92 @@
+package service
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/model"
+)
+
+func TestCalendarEvents(t *testing.T) {
+	now := time.Date(2025, 7, 15, 10, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	pastEnd := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	subs := []model.Subscription{
+		{ID: uuid.New(), ServiceName: "Netflix", Price: 799, StartDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), EndDate: &end},
+		{ID: uuid.New(), ServiceName: "Spotify", Price: 169, StartDate: time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: uuid.New(), ServiceName: "Okko", Price: 199, StartDate: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), EndDate: &pastEnd},
+	}
+
+	events := calendarEvents(subs, now, 3)
+	var got []string
+	for _, event := range events {
+		got = append(got, event.Date.Format("2006-01-02")+" "+event.Summary)
+	}
+	want := []string{
+		"2025-08-01 Netflix: 799",
+		"2025-09-01 Netflix: 799",
+		"2025-09-01 Netflix ends",
+		"2025-10-01 Spotify: 169",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected events:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if events[0].UID == events[1].UID || events[1].UID == events[2].UID {
+		t.Fatalf("event UIDs are not unique: %+v", events)
+	}
+}
+
+func TestWriteICal(t *testing.T) {
+	now := time.Date(2025, 7, 15, 10, 0, 0, 0, time.UTC)
+	events := []calendarEvent{{
+		UID:         "sub-20250801-billing",
+		Date:        time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC),
+		Summary:     "Кинопоиск; HD, 4K: 399",
+		Description: strings.Repeat("Подписка продлевается. ", 5),
+	}}
+	var buf bytes.Buffer
+	writeICal(&buf, events, now)
+	out := buf.String()
+
+	if !strings.HasPrefix(out, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n") || !strings.HasSuffix(out, "END:VCALENDAR\r\n") {
+		t.Fatalf("not a calendar:\n%s", out)
+	}
+	for _, line := range []string{
+		"UID:sub-20250801-billing@subs",
+		"DTSTAMP:20250715T100000Z",
+		"DTSTART;VALUE=DATE:20250801",
+		"DTEND;VALUE=DATE:20250802",
+		`SUMMARY:Кинопоиск\; HD\, 4K: 399`,
+	} {
+		if !strings.Contains(out, line+"\r\n") {
+			t.Errorf("missing line %q in:\n%s", line, out)
+		}
+	}
+
+	var unfolded strings.Builder
+	for i, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if len(line) > icalLineLimit {
+			t.Errorf("line %d is %d octets long", i+1, len(line))
+		}
+		if !strings.Contains(line, ":") && !strings.HasPrefix(line, " ") {
+			t.Errorf("line %d is neither a property nor a continuation: %q", i+1, line)
+		}
+		if strings.HasPrefix(line, " ") {
+			unfolded.WriteString(line[1:])
+		} else {
+			unfolded.WriteString("\n" + line)
+		}
+	}
+	if !strings.Contains(unfolded.String(), "\nDESCRIPTION:"+events[0].Description+"\n") {
+		t.Fatalf("description did not survive folding:\n%s", unfolded.String())
+	}
+}
+
+func TestHashCalendarToken(t *testing.T) {
+	if hashCalendarToken("a") == hashCalendarToken("b") || len(hashCalendarToken("a")) != 64 {
+		t.Fatal("calendar tokens must hash to distinct SHA-256 digests")
+	}
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/logging"
+	"github.com/lavatee/subs/internal/model"
+	"github.com/lavatee/subs/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+const changesPageSize = 1000
+
+type ChangesService struct {
+	repo     *repository.Repository
+	logger   *logrus.Logger
+	tokenTTL time.Duration
+}
+
+// NewChangesService builds the change feed. Sync tokens older than tokenTTL
+// are rejected; it must stay below the outbox retention so that no event a
+// valid token still needs has been trimmed.
+func NewChangesService(repo *repository.Repository, logger *logrus.Logger, tokenTTL time.Duration) *ChangesService {
+	return &ChangesService{
+		repo:     repo,
+		logger:   logger,
+		tokenTTL: tokenTTL,
+	}
+}
+
+// GetChanges returns what changed for userID (every user for uuid.Nil) since
+// the sync token. Without a token it returns every live subscription as the
+// initial sync. Each subscription appears at most once per page, in its
+// latest state or as a tombstone.
+func (s *ChangesService) GetChanges(ctx context.Context, userID uuid.UUID, since string) (model.SubscriptionChangesResponse, error) {
+	if since == "" {
+		return s.initialSync(ctx, userID)
+	}
+
+	position, issuedAt, err := parseSyncToken(since)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Warnf("Invalid sync token: %v", err)
+		return model.SubscriptionChangesResponse{}, err
+	}
+	if s.tokenTTL > 0 && time.Since(issuedAt) > s.tokenTTL {
+		return model.SubscriptionChangesResponse{}, fmt.Errorf("sync token from %s: %w, sync from scratch", issuedAt.Format(time.RFC3339), model.ErrExpired)
+	}
+
+	events, err := s.repo.Outbox.GetEventsAfter(ctx, position, changesPageSize)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to get changes from repository: %v", err)
+		return model.SubscriptionChangesResponse{}, err
+	}
+
+	changes := make([]model.SubscriptionChange, 0)
+	index := make(map[uuid.UUID]int)
+	for _, event := range events {
+		position = event.Position()
+		change, ok := subscriptionChange(event, userID)
+		if !ok {
+			continue
+		}
+		if i, seen := index[change.ID]; seen {
+			changes[i] = change
+			continue
+		}
+		index[change.ID] = len(changes)
+		changes = append(changes, change)
+	}
+
+	return model.SubscriptionChangesResponse{
+		Changes: changes,
+		Next:    newSyncToken(position, time.Now()),
+		HasMore: len(events) == changesPageSize,
+	}, nil
+}
+
+func (s *ChangesService) initialSync(ctx context.Context, userID uuid.UUID) (model.SubscriptionChangesResponse, error) {
+	// Read the position first: changes committed in between show up in both
+	// the list and the next page, which clients apply idempotently.
+	last, err := s.repo.Outbox.GetLastEvents(ctx, 1)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to get feed position from repository: %v", err)
+		return model.SubscriptionChangesResponse{}, err
+	}
+	var position model.EventPosition
+	if len(last) > 0 {
+		position = last[0].Position()
+	}
+
+	subs, err := s.repo.Subscriptions.GetUserSubscriptions(ctx, userID, "", false)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to get subscriptions from repository: %v", err)
+		return model.SubscriptionChangesResponse{}, err
+	}
+	changes := make([]model.SubscriptionChange, 0, len(subs))
+	for _, sub := range subs {
+		changes = append(changes, model.SubscriptionChange{ID: sub.ID, Subscription: &sub})
+	}
+
+	return model.SubscriptionChangesResponse{
+		Changes: changes,
+		Next:    newSyncToken(position, time.Now()),
+	}, nil
+}
+
+// subscriptionChange turns a create, update, delete or archive event of
+// userID's subscription into a change. Archived subscriptions leave the live
+// set, so they are tombstones like deleted ones.
+func subscriptionChange(event model.OutboxEvent, userID uuid.UUID) (model.SubscriptionChange, bool) {
+	switch event.Type {
+	case model.EventSubscriptionCreated, model.EventSubscriptionUpdated, model.EventSubscriptionDeleted, model.EventSubscriptionArchived:
+	default:
+		return model.SubscriptionChange{}, false
+	}
+	var envelope model.Event
+	if err := json.Unmarshal(event.Payload, &envelope); err != nil || envelope.Subscription == nil {
+		return model.SubscriptionChange{}, false
+	}
+	sub := envelope.Subscription
+	if userID != uuid.Nil && sub.UserID != userID {
+		return model.SubscriptionChange{}, false
+	}
+	if event.Type == model.EventSubscriptionDeleted || event.Type == model.EventSubscriptionArchived {
+		return model.SubscriptionChange{ID: sub.ID, Deleted: true}, true
+	}
+	return model.SubscriptionChange{ID: sub.ID, Subscription: sub}, true
+}
+
+// Sync tokens are "v1.<txid>-<sequence>.<unix issue time>", base64 encoded.
+func newSyncToken(position model.EventPosition, issuedAt time.Time) string {
+	raw := fmt.Sprintf("v1.%s.%d", position, issuedAt.Unix())
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func parseSyncToken(token string) (model.EventPosition, time.Time, error) {
+	invalid := fmt.Errorf("%w: invalid sync token", model.ErrInvalidInput)
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return model.EventPosition{}, time.Time{}, invalid
+	}
+	parts := strings.Split(string(raw), ".")
+	if len(parts) != 3 || parts[0] != "v1" {
+		return model.EventPosition{}, time.Time{}, invalid
+	}
+	position, err := model.ParseEventPosition(parts[1])
+	if err != nil {
+		return model.EventPosition{}, time.Time{}, invalid
+	}
+	issuedAt, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return model.EventPosition{}, time.Time{}, invalid
+	}
+	return position, time.Unix(issuedAt, 0), nil
+}
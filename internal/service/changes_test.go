@@ -0,0 +1,57 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/model"
+)
+
+func TestSyncTokenRoundTrip(t *testing.T) {
+	position := model.EventPosition{TxID: 812, Sequence: 40}
+	issuedAt := time.Unix(1750000000, 0)
+	gotPosition, gotIssuedAt, err := parseSyncToken(newSyncToken(position, issuedAt))
+	if err != nil {
+		t.Fatalf("parseSyncToken: %v", err)
+	}
+	if gotPosition != position || !gotIssuedAt.Equal(issuedAt) {
+		t.Fatalf("want %s at %s, got %s at %s", position, issuedAt, gotPosition, gotIssuedAt)
+	}
+
+	for _, token := range []string{"garbage!", "djIuMS0xLjA", "djEuMTIuMQ"} {
+		if _, _, err := parseSyncToken(token); !errors.Is(err, model.ErrInvalidInput) {
+			t.Errorf("parseSyncToken(%q): want ErrInvalidInput, got %v", token, err)
+		}
+	}
+}
+
+func TestSubscriptionChange(t *testing.T) {
+	alice, bob := uuid.New(), uuid.New()
+
+	updated := outboxEventFor(t, 1, 1, model.EventSubscriptionUpdated, alice)
+	change, ok := subscriptionChange(updated, alice)
+	if !ok || change.Deleted || change.Subscription == nil || change.Subscription.UserID != alice {
+		t.Fatalf("update: got %+v, %v", change, ok)
+	}
+
+	deleted := outboxEventFor(t, 1, 2, model.EventSubscriptionDeleted, alice)
+	change, ok = subscriptionChange(deleted, uuid.Nil)
+	if !ok || !change.Deleted || change.Subscription != nil || change.ID == uuid.Nil {
+		t.Fatalf("delete: got %+v, %v", change, ok)
+	}
+
+	archived := outboxEventFor(t, 1, 3, model.EventSubscriptionArchived, alice)
+	change, ok = subscriptionChange(archived, alice)
+	if !ok || !change.Deleted || change.Subscription != nil {
+		t.Fatalf("archive: got %+v, %v", change, ok)
+	}
+
+	if _, ok := subscriptionChange(updated, bob); ok {
+		t.Fatal("another user's change passed the filter")
+	}
+	if _, ok := subscriptionChange(outboxEventFor(t, 1, 4, model.EventBudgetExceeded, alice), alice); ok {
+		t.Fatal("budget event turned into a change")
+	}
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/logging"
+	"github.com/lavatee/subs/internal/model"
+	"github.com/lavatee/subs/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+type ComparisonService struct {
+	repo   *repository.Repository
+	logger *logrus.Logger
+}
+
+func NewComparisonService(repo *repository.Repository, logger *logrus.Logger) *ComparisonService {
+	return &ComparisonService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// CompareSpend compares the total cost of two periods and lists the
+// subscriptions behind the difference. Without dates the current period is
+// the current month.
+func (s *ComparisonService) CompareSpend(ctx context.Context, req model.CompareSpendRequest) (model.SpendComparison, error) {
+	current, previous, err := comparisonPeriods(req, time.Now().UTC())
+	if err != nil {
+		return model.SpendComparison{}, err
+	}
+
+	currentSubs, err := s.repo.Subscriptions.GetTotalCostSubscriptions(ctx, req.UserID, req.ServiceName, current.StartDate, current.EndDate, req.IncludeArchived)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to get subscriptions of the current period: %v", err)
+		return model.SpendComparison{}, err
+	}
+	previousSubs, err := s.repo.Subscriptions.GetTotalCostSubscriptions(ctx, req.UserID, req.ServiceName, previous.StartDate, previous.EndDate, req.IncludeArchived)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to get subscriptions of the previous period: %v", err)
+		return model.SpendComparison{}, err
+	}
+
+	return compareSpend(current, previous, currentSubs, previousSubs), nil
+}
+
+// comparisonPeriods resolves the current and previous period of req.
+func comparisonPeriods(req model.CompareSpendRequest, now time.Time) (model.SpendPeriod, model.SpendPeriod, error) {
+	current := model.SpendPeriod{StartDate: req.StartDate, EndDate: req.EndDate}
+	switch {
+	case current.StartDate.IsZero() && current.EndDate.IsZero():
+		current.StartDate, current.EndDate = monthStart(now), monthStart(now)
+	case current.StartDate.IsZero():
+		current.StartDate = current.EndDate
+	case current.EndDate.IsZero():
+		current.EndDate = current.StartDate
+	}
+	if current.StartDate.After(current.EndDate) {
+		return model.SpendPeriod{}, model.SpendPeriod{}, fmt.Errorf("%w: start_date must not be after end_date", model.ErrInvalidInput)
+	}
+
+	previous := model.SpendPeriod{StartDate: req.CompareStartDate, EndDate: req.CompareEndDate}
+	if previous.StartDate.IsZero() != previous.EndDate.IsZero() {
+		return model.SpendPeriod{}, model.SpendPeriod{}, fmt.Errorf("%w: compare_start_date and compare_end_date must be set together", model.ErrInvalidInput)
+	}
+	if previous.StartDate.IsZero() {
+		var months int
+		switch req.Against {
+		case "", model.CompareAgainstPrevious:
+			months = monthsBetween(current.StartDate, current.EndDate) + 1
+		case model.CompareAgainstYear:
+			months = 12
+		default:
+			return model.SpendPeriod{}, model.SpendPeriod{}, fmt.Errorf("%w: against must be previous or year", model.ErrInvalidInput)
+		}
+		previous.StartDate = current.StartDate.AddDate(0, -months, 0)
+		previous.EndDate = current.EndDate.AddDate(0, -months, 0)
+	}
+	if previous.StartDate.After(previous.EndDate) {
+		return model.SpendPeriod{}, model.SpendPeriod{}, fmt.Errorf("%w: compare_start_date must not be after compare_end_date", model.ErrInvalidInput)
+	}
+	return current, previous, nil
+}
+
+// closestReplaced picks the subscription of candidates that added most
+// likely replaced: the one whose paid months end closest to the month added
+// starts, then the one starting closest to it, then the lowest ID.
+func closestReplaced(candidates []model.Subscription, added model.Subscription) int {
+	best := 0
+	for i := 1; i < len(candidates); i++ {
+		if replacementLess(candidates[i], candidates[best], added) {
+			best = i
+		}
+	}
+	return best
+}
+
+func replacementLess(a, b, added model.Subscription) bool {
+	gap := func(sub model.Subscription) int {
+		end := added.StartDate
+		if sub.EndDate != nil {
+			end = sub.EndDate.AddDate(0, 1, 0)
+		}
+		return abs(monthsBetween(end, added.StartDate))
+	}
+	if gapA, gapB := gap(a), gap(b); gapA != gapB {
+		return gapA < gapB
+	}
+	startA, startB := abs(monthsBetween(a.StartDate, added.StartDate)), abs(monthsBetween(b.StartDate, added.StartDate))
+	if startA != startB {
+		return startA < startB
+	}
+	return a.ID.String() < b.ID.String()
+}
+
+func sortByStart(subs []model.Subscription) {
+	sort.Slice(subs, func(i, j int) bool {
+		if !subs[i].StartDate.Equal(subs[j].StartDate) {
+			return subs[i].StartDate.Before(subs[j].StartDate)
+		}
+		return subs[i].ID.String() < subs[j].ID.String()
+	})
+}
+
+func monthsBetween(from, to time.Time) int {
+	return (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month())
+}
+
+// compareSpend builds the comparison from the subscriptions counted in each
+// period. A subscription has one price, so the difference of the totals is
+// made up of the subscriptions counted in only one of them. That also means
+// a price updated in place counts in both periods at the new price and is
+// not a change; only replacing a subscription with a new one is.
+func compareSpend(current, previous model.SpendPeriod, currentSubs, previousSubs []model.Subscription) model.SpendComparison {
+	inCurrent := make(map[uuid.UUID]bool, len(currentSubs))
+	for _, sub := range currentSubs {
+		inCurrent[sub.ID] = true
+		current.Total += sub.Price
+	}
+	inPrevious := make(map[uuid.UUID]bool, len(previousSubs))
+	for _, sub := range previousSubs {
+		inPrevious[sub.ID] = true
+		previous.Total += sub.Price
+	}
+
+	type userService struct {
+		userID      uuid.UUID
+		serviceName string
+	}
+	removed := make(map[userService][]model.Subscription)
+	for _, sub := range previousSubs {
+		if !inCurrent[sub.ID] {
+			key := userService{sub.UserID, sub.ServiceName}
+			removed[key] = append(removed[key], sub)
+		}
+	}
+
+	var addedSubs []model.Subscription
+	for _, sub := range currentSubs {
+		if !inPrevious[sub.ID] {
+			addedSubs = append(addedSubs, sub)
+		}
+	}
+	sortByStart(addedSubs)
+
+	changes := []model.SpendChange{}
+	for _, sub := range addedSubs {
+		added := sub
+		change := model.SpendChange{
+			Kind:                  model.SpendChangeAdded,
+			ServiceName:           added.ServiceName,
+			UserID:                added.UserID,
+			CurrentSubscriptionID: &added.ID,
+			CurrentPrice:          added.Price,
+		}
+		// A subscription replaced by another of the same user and service is
+		// a price change, or no change at all if the price is the same.
+		key := userService{sub.UserID, sub.ServiceName}
+		if replaced := removed[key]; len(replaced) > 0 {
+			i := closestReplaced(replaced, added)
+			old := replaced[i]
+			removed[key] = append(replaced[:i:i], replaced[i+1:]...)
+			if old.Price == added.Price {
+				continue
+			}
+			change.Kind = model.SpendChangePriceChanged
+			change.PreviousSubscriptionID = &old.ID
+			change.PreviousPrice = old.Price
+		}
+		change.Delta = change.CurrentPrice - change.PreviousPrice
+		changes = append(changes, change)
+	}
+	for _, subs := range removed {
+		for _, sub := range subs {
+			old := sub
+			changes = append(changes, model.SpendChange{
+				Kind:                   model.SpendChangeRemoved,
+				ServiceName:            old.ServiceName,
+				UserID:                 old.UserID,
+				PreviousSubscriptionID: &old.ID,
+				PreviousPrice:          old.Price,
+				Delta:                  -old.Price,
+			})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if abs(a.Delta) != abs(b.Delta) {
+			return abs(a.Delta) > abs(b.Delta)
+		}
+		if a.ServiceName != b.ServiceName {
+			return a.ServiceName < b.ServiceName
+		}
+		return a.UserID.String() < b.UserID.String()
+	})
+
+	comparison := model.SpendComparison{
+		Current:  current,
+		Previous: previous,
+		Delta:    current.Total - previous.Total,
+		Changes:  changes,
+	}
+	if previous.Total != 0 {
+		percent := math.Round(float64(comparison.Delta)*10000/float64(previous.Total)) / 100
+		comparison.DeltaPercent = &percent
+	}
+	return comparison
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/model"
+)
+
+func TestComparisonPeriods(t *testing.T) {
+	now := time.Date(2025, 7, 15, 0, 0, 0, 0, time.UTC)
+	month := func(year int, m time.Month) time.Time {
+		return time.Date(year, m, 1, 0, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name string
+		req  model.CompareSpendRequest
+		want string
+	}{
+		{name: "this month against last", want: "2025-07..2025-07 vs 2025-06..2025-06"},
+		{name: "quarter against previous quarter", req: model.CompareSpendRequest{StartDate: month(2025, time.April), EndDate: month(2025, time.June)}, want: "2025-04..2025-06 vs 2025-01..2025-03"},
+		{name: "year over year", req: model.CompareSpendRequest{StartDate: month(2025, time.March), Against: model.CompareAgainstYear}, want: "2025-03..2025-03 vs 2024-03..2024-03"},
+		{name: "explicit period", req: model.CompareSpendRequest{EndDate: month(2025, time.May), CompareStartDate: month(2023, time.January), CompareEndDate: month(2023, time.February)}, want: "2025-05..2025-05 vs 2023-01..2023-02"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current, previous, err := comparisonPeriods(tt.req, now)
+			if err != nil {
+				t.Fatalf("comparisonPeriods: %v", err)
+			}
+			got := fmt.Sprintf("%s..%s vs %s..%s", current.StartDate.Format("2006-01"), current.EndDate.Format("2006-01"), previous.StartDate.Format("2006-01"), previous.EndDate.Format("2006-01"))
+			if got != tt.want {
+				t.Fatalf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	for _, req := range []model.CompareSpendRequest{
+		{StartDate: month(2025, time.May), EndDate: month(2025, time.April)},
+		{Against: "decade"},
+		{CompareStartDate: month(2025, time.May)},
+	} {
+		if _, _, err := comparisonPeriods(req, now); !errors.Is(err, model.ErrInvalidInput) {
+			t.Errorf("comparisonPeriods(%+v): want ErrInvalidInput, got %v", req, err)
+		}
+	}
+}
+
+func TestCompareSpend(t *testing.T) {
+	user := uuid.New()
+	sub := func(service string, price int) model.Subscription {
+		return model.Subscription{ID: uuid.New(), UserID: user, ServiceName: service, Price: price}
+	}
+	kept := sub("Okko", 199)
+	oldNetflix, newNetflix := sub("Netflix", 599), sub("Netflix", 799)
+	oldYandex, newYandex := sub("Yandex Plus", 299), sub("Yandex Plus", 299)
+	spotify, ivi := sub("Spotify", 169), sub("Ivi", 399)
+
+	comparison := compareSpend(model.SpendPeriod{}, model.SpendPeriod{},
+		[]model.Subscription{kept, newNetflix, newYandex, ivi},
+		[]model.Subscription{kept, oldNetflix, oldYandex, spotify},
+	)
+	if comparison.Current.Total != 1696 || comparison.Previous.Total != 1266 || comparison.Delta != 430 {
+		t.Fatalf("unexpected totals %+v", comparison)
+	}
+	if comparison.DeltaPercent == nil || *comparison.DeltaPercent != 33.97 {
+		t.Fatalf("unexpected delta percent %v", comparison.DeltaPercent)
+	}
+
+	var got []string
+	sum := 0
+	for _, change := range comparison.Changes {
+		got = append(got, fmt.Sprintf("%s %s %+d", change.Kind, change.ServiceName, change.Delta))
+		sum += change.Delta
+	}
+	want := []string{"added Ivi +399", "price_changed Netflix +200", "removed Spotify -169"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("got changes %v, want %v", got, want)
+	}
+	if sum != comparison.Delta {
+		t.Fatalf("changes add up to %d, delta is %d", sum, comparison.Delta)
+	}
+	if change := comparison.Changes[1]; *change.PreviousSubscriptionID != oldNetflix.ID || *change.CurrentSubscriptionID != newNetflix.ID {
+		t.Fatalf("price change does not link both subscriptions: %+v", change)
+	}
+
+	empty := compareSpend(model.SpendPeriod{}, model.SpendPeriod{}, []model.Subscription{ivi}, nil)
+	if empty.DeltaPercent != nil || empty.Delta != 399 {
+		t.Fatalf("a comparison with an empty previous period has no percentage: %+v", empty)
+	}
+}
+
+func TestCompareSpendPairsClosestReplacement(t *testing.T) {
+	user := uuid.New()
+	month := func(year int, m time.Month) time.Time {
+		return time.Date(year, m, 1, 0, 0, 0, 0, time.UTC)
+	}
+	ended := func(start, end time.Time, price int) model.Subscription {
+		return model.Subscription{ID: uuid.New(), UserID: user, ServiceName: "Netflix", Price: price, StartDate: start, EndDate: &end}
+	}
+	early := ended(month(2024, time.January), month(2024, time.June), 499)
+	late := ended(month(2024, time.July), month(2025, time.February), 599)
+	replacement := model.Subscription{ID: uuid.New(), UserID: user, ServiceName: "Netflix", Price: 799, StartDate: month(2025, time.March)}
+
+	for _, previous := range [][]model.Subscription{{early, late}, {late, early}} {
+		comparison := compareSpend(model.SpendPeriod{}, model.SpendPeriod{}, []model.Subscription{replacement}, previous)
+		var got []string
+		for _, change := range comparison.Changes {
+			got = append(got, fmt.Sprintf("%s %d->%d", change.Kind, change.PreviousPrice, change.CurrentPrice))
+		}
+		want := []string{"removed 499->0", "price_changed 599->799"}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Fatalf("got changes %v, want %v", got, want)
+		}
+	}
+}
+
+func TestCompareSpendIgnoresInPlaceUpdates(t *testing.T) {
+	// After UpdateSubscription only the new price exists, so both periods
+	// count it and there is nothing to report.
+	updated := model.Subscription{ID: uuid.New(), UserID: uuid.New(), ServiceName: "Netflix", Price: 799}
+	comparison := compareSpend(model.SpendPeriod{}, model.SpendPeriod{}, []model.Subscription{updated}, []model.Subscription{updated})
+	if comparison.Delta != 0 || len(comparison.Changes) != 0 {
+		t.Fatalf("in-place update produced %+v", comparison)
+	}
+}
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/logging"
+	"github.com/lavatee/subs/internal/model"
+	"github.com/lavatee/subs/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+type DuplicatesService struct {
+	repo   *repository.Repository
+	logger *logrus.Logger
+}
+
+func NewDuplicatesService(repo *repository.Repository, logger *logrus.Logger) *DuplicatesService {
+	return &DuplicatesService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// GetDuplicates finds the user's subscriptions to the same service, compared
+// by merchantKey, with overlapping periods.
+func (s *DuplicatesService) GetDuplicates(ctx context.Context, userID uuid.UUID) (model.DuplicatesResponse, error) {
+	subscriptions, err := s.repo.Subscriptions.GetUserSubscriptions(ctx, userID, "", false)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to get subscriptions from repository: %v", err)
+		return model.DuplicatesResponse{}, err
+	}
+
+	response := model.DuplicatesResponse{
+		Duplicates: findDuplicates(subscriptions, time.Now().UTC()),
+	}
+	for _, group := range response.Duplicates {
+		response.WastedSpend += group.WastedSpend
+	}
+	return response, nil
+}
+
+// MergeDuplicates collapses subscriptions of one user and service into the
+// kept one, which is stretched over all their periods; the rest are deleted.
+func (s *DuplicatesService) MergeDuplicates(ctx context.Context, userID uuid.UUID, req model.MergeDuplicatesRequest) (model.MergeDuplicatesResponse, error) {
+	var response model.MergeDuplicatesResponse
+	err := s.repo.WithinTx(ctx, func(repos *repository.Repository) error {
+		subs := make([]model.Subscription, 0, len(req.SubscriptionIDs))
+		seen := make(map[uuid.UUID]bool, len(req.SubscriptionIDs))
+		for _, id := range req.SubscriptionIDs {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			sub, err := repos.Subscriptions.GetSubscription(ctx, id)
+			if errors.Is(err, sql.ErrNoRows) || err == nil && sub.UserID != userID {
+				return fmt.Errorf("subscription %s of user %s: %w", id, userID, model.ErrNotFound)
+			}
+			if err != nil {
+				logging.FromContext(ctx, s.logger).Errorf("Failed to get subscription for merge: %v", err)
+				return err
+			}
+			subs = append(subs, sub)
+		}
+		if len(subs) < 2 {
+			return fmt.Errorf("%w: at least two distinct subscriptions are needed", model.ErrInvalidInput)
+		}
+
+		kept, removed, err := mergeSubscriptions(subs, req.KeepID)
+		if err != nil {
+			return err
+		}
+		if err := repos.Subscriptions.UpdateSubscription(ctx, kept); err != nil {
+			logging.FromContext(ctx, s.logger).Errorf("Failed to update merged subscription: %v", err)
+			return err
+		}
+		events := []model.Event{model.NewSubscriptionEvent(model.EventSubscriptionUpdated, kept)}
+		for _, sub := range removed {
+			if err := repos.Subscriptions.DeleteSubscription(ctx, sub.ID); err != nil {
+				logging.FromContext(ctx, s.logger).Errorf("Failed to delete merged subscription: %v", err)
+				return err
+			}
+			events = append(events, model.NewSubscriptionEvent(model.EventSubscriptionDeleted, sub))
+			response.Removed = append(response.Removed, sub.ID)
+		}
+		for _, event := range events {
+			if err := recordEvent(ctx, repos, event); err != nil {
+				logging.FromContext(ctx, s.logger).Errorf("Failed to record subscription event: %v", err)
+				return err
+			}
+		}
+		response.Subscription = kept
+		return nil
+	})
+	if err != nil {
+		return model.MergeDuplicatesResponse{}, err
+	}
+	return response, nil
+}
+
+// mergeSubscriptions picks the subscription to keep, keepID or the most
+// expensive one, and stretches it from the earliest start to the latest end.
+func mergeSubscriptions(subs []model.Subscription, keepID *uuid.UUID) (model.Subscription, []model.Subscription, error) {
+	key := merchantKey(subs[0].ServiceName)
+	for _, sub := range subs[1:] {
+		if merchantKey(sub.ServiceName) != key {
+			return model.Subscription{}, nil, fmt.Errorf("%w: %q and %q are different services", model.ErrInvalidInput, subs[0].ServiceName, sub.ServiceName)
+		}
+	}
+
+	keep := -1
+	for i, sub := range subs {
+		if keepID != nil {
+			if sub.ID == *keepID {
+				keep = i
+			}
+			continue
+		}
+		if keep < 0 || sub.Price > subs[keep].Price || sub.Price == subs[keep].Price && sub.CreatedAt.Before(subs[keep].CreatedAt) {
+			keep = i
+		}
+	}
+	if keep < 0 {
+		return model.Subscription{}, nil, fmt.Errorf("%w: keep_id must be one of subscription_ids", model.ErrInvalidInput)
+	}
+
+	kept := subs[keep]
+	var removed []model.Subscription
+	for i, sub := range subs {
+		if i == keep {
+			continue
+		}
+		removed = append(removed, sub)
+		if sub.StartDate.Before(kept.StartDate) {
+			kept.StartDate = sub.StartDate
+		}
+		switch {
+		case kept.EndDate == nil:
+		case sub.EndDate == nil:
+			kept.EndDate = nil
+		case sub.EndDate.After(*kept.EndDate):
+			end := *sub.EndDate
+			kept.EndDate = &end
+		}
+	}
+	return kept, removed, nil
+}
+
+// findDuplicates groups subs by service and returns the sets of overlapping
+// ones, most wasteful first.
+func findDuplicates(subs []model.Subscription, now time.Time) []model.DuplicateGroup {
+	byService := make(map[string][]model.Subscription)
+	for _, sub := range subs {
+		key := merchantKey(sub.ServiceName)
+		if key == "" {
+			key = sub.ServiceName
+		}
+		byService[key] = append(byService[key], sub)
+	}
+
+	groups := []model.DuplicateGroup{}
+	for _, service := range byService {
+		sort.Slice(service, func(i, j int) bool { return service[i].StartDate.Before(service[j].StartDate) })
+		// Sweep by start date, extending the set while the next subscription
+		// starts before the current set has ended.
+		var set []model.Subscription
+		var setEnd *time.Time
+		flush := func() {
+			if len(set) > 1 {
+				groups = append(groups, duplicateGroup(set, now))
+			}
+		}
+		for _, sub := range service {
+			if len(set) > 0 && (setEnd == nil || !sub.StartDate.After(*setEnd)) {
+				set = append(set, sub)
+				if setEnd != nil && (sub.EndDate == nil || sub.EndDate.After(*setEnd)) {
+					setEnd = sub.EndDate
+				}
+				continue
+			}
+			flush()
+			set, setEnd = []model.Subscription{sub}, sub.EndDate
+		}
+		flush()
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].WastedSpend != groups[j].WastedSpend {
+			return groups[i].WastedSpend > groups[j].WastedSpend
+		}
+		return groups[i].ServiceName < groups[j].ServiceName
+	})
+	return groups
+}
+
+// duplicateGroup measures the overlap of set month by month. Open-ended
+// subscriptions are followed up to the current month, or to the latest start
+// in the set if that is later.
+func duplicateGroup(set []model.Subscription, now time.Time) model.DuplicateGroup {
+	group := model.DuplicateGroup{
+		ServiceName:   set[0].ServiceName,
+		Subscriptions: set,
+	}
+
+	last := monthStart(now)
+	for _, sub := range set {
+		if sub.StartDate.After(last) {
+			last = sub.StartDate
+		}
+		if sub.EndDate != nil && sub.EndDate.After(last) {
+			last = *sub.EndDate
+		}
+	}
+
+	ongoing := false
+	for month := set[0].StartDate; !month.After(last); month = month.AddDate(0, 1, 0) {
+		active, open, sum, highest := 0, 0, 0, 0
+		for _, sub := range set {
+			if sub.StartDate.After(month) || sub.EndDate != nil && sub.EndDate.Before(month) {
+				continue
+			}
+			active++
+			sum += sub.Price
+			highest = max(highest, sub.Price)
+			if sub.EndDate == nil {
+				open++
+			}
+		}
+		if active < 2 {
+			ongoing = false
+			continue
+		}
+		if group.OverlapMonths == 0 {
+			group.OverlapStart = month
+		}
+		overlapEnd := month
+		group.OverlapEnd = &overlapEnd
+		group.OverlapMonths++
+		group.WastedSpend += sum - highest
+		group.MonthlyWaste = sum - highest
+		ongoing = month.Equal(last) && open > 1
+	}
+
+	// Two open-ended subscriptions keep overlapping past the last month.
+	if ongoing {
+		group.OverlapEnd = nil
+	} else if group.OverlapEnd == nil || group.OverlapEnd.Before(monthStart(now)) {
+		group.MonthlyWaste = 0
+	}
+	return group
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/model"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	now := time.Date(2025, 7, 15, 0, 0, 0, 0, time.UTC)
+	month := func(m time.Month) time.Time {
+		return time.Date(2025, m, 1, 0, 0, 0, 0, time.UTC)
+	}
+	monthPtr := func(m time.Month) *time.Time {
+		t := month(m)
+		return &t
+	}
+	sub := func(service string, price int, start time.Time, end *time.Time) model.Subscription {
+		return model.Subscription{ID: uuid.New(), ServiceName: service, Price: price, StartDate: start, EndDate: end}
+	}
+
+	subs := []model.Subscription{
+		// Both still running since May: 3 months of overlap so far.
+		sub("Netflix", 799, month(time.January), nil),
+		sub("netflix.com", 599, month(time.May), nil),
+		// Overlapped in February and March only.
+		sub("Spotify", 169, month(time.January), monthPtr(time.March)),
+		sub("SPOTIFY", 199, month(time.February), monthPtr(time.June)),
+		// Back to back, no overlap.
+		sub("Okko", 199, month(time.January), monthPtr(time.March)),
+		sub("Okko", 199, month(time.April), nil),
+	}
+
+	groups := findDuplicates(subs, now)
+	if len(groups) != 2 {
+		t.Fatalf("want Netflix and Spotify, got %+v", groups)
+	}
+
+	netflix, spotify := groups[0], groups[1]
+	if netflix.ServiceName != "Netflix" || len(netflix.Subscriptions) != 2 || !netflix.OverlapStart.Equal(month(time.May)) || netflix.OverlapEnd != nil {
+		t.Fatalf("unexpected Netflix group %+v", netflix)
+	}
+	if netflix.OverlapMonths != 3 || netflix.WastedSpend != 3*599 || netflix.MonthlyWaste != 599 {
+		t.Fatalf("unexpected Netflix waste %+v", netflix)
+	}
+	if spotify.OverlapMonths != 2 || spotify.WastedSpend != 2*169 || spotify.MonthlyWaste != 0 || spotify.OverlapEnd == nil || !spotify.OverlapEnd.Equal(month(time.March)) {
+		t.Fatalf("unexpected Spotify group %+v", spotify)
+	}
+}
+
+func TestMergeSubscriptions(t *testing.T) {
+	march, june := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	cheap := model.Subscription{ID: uuid.New(), ServiceName: "Spotify", Price: 169, StartDate: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), EndDate: &march}
+	dear := model.Subscription{ID: uuid.New(), ServiceName: "spotify", Price: 199, StartDate: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), EndDate: &june}
+
+	kept, removed, err := mergeSubscriptions([]model.Subscription{cheap, dear}, nil)
+	if err != nil {
+		t.Fatalf("mergeSubscriptions: %v", err)
+	}
+	if kept.ID != dear.ID || !kept.StartDate.Equal(cheap.StartDate) || !kept.EndDate.Equal(june) {
+		t.Fatalf("want the dearer subscription stretched over both, got %+v", kept)
+	}
+	if len(removed) != 1 || removed[0].ID != cheap.ID {
+		t.Fatalf("unexpected removed %+v", removed)
+	}
+
+	open := dear
+	open.EndDate = nil
+	kept, _, err = mergeSubscriptions([]model.Subscription{open, cheap}, &cheap.ID)
+	if err != nil {
+		t.Fatalf("mergeSubscriptions with keep_id: %v", err)
+	}
+	if kept.ID != cheap.ID || kept.Price != 169 || kept.EndDate != nil {
+		t.Fatalf("want the kept subscription to become open-ended, got %+v", kept)
+	}
+
+	other := model.Subscription{ID: uuid.New(), ServiceName: "Netflix"}
+	if _, _, err := mergeSubscriptions([]model.Subscription{cheap, other}, nil); !errors.Is(err, model.ErrInvalidInput) {
+		t.Fatalf("merging different services: want ErrInvalidInput, got %v", err)
+	}
+	stranger := uuid.New()
+	if _, _, err := mergeSubscriptions([]model.Subscription{cheap, dear}, &stranger); !errors.Is(err, model.ErrInvalidInput) {
+		t.Fatalf("keep_id outside the merge: want ErrInvalidInput, got %v", err)
+	}
+}
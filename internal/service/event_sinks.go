@@ -0,0 +1,238 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lavatee/subs/internal/model"
+)
+
+const sinkTimeout = 10 * time.Second
+
+// EventSink receives outbox events. Publish must return nil only once the
+// event is safely handed over; events may be published more than once.
+type EventSink interface {
+	Publish(ctx context.Context, event model.OutboxEvent) error
+}
+
+type SinkConfig struct {
+	// Type is one of stdout, file, http or nats.
+	Type string
+	// Path is the file that the file sink appends to.
+	Path string
+	// URL is the endpoint of the http sink or the server of the nats sink.
+	URL string
+	// Subject prefixes NATS subjects; the event type is appended to it.
+	Subject string
+}
+
+func NewEventSink(config SinkConfig) (EventSink, error) {
+	switch config.Type {
+	case "stdout":
+		return NewWriterSink(os.Stdout), nil
+	case "file":
+		return NewFileSink(config.Path)
+	case "http":
+		if config.URL == "" {
+			return nil, fmt.Errorf("http sink needs a url")
+		}
+		return NewHTTPSink(config.URL), nil
+	case "nats":
+		return NewNATSSink(config.URL, config.Subject)
+	}
+	return nil, fmt.Errorf("unknown event sink type %q", config.Type)
+}
+
+// WriterSink writes events as newline-delimited JSON.
+type WriterSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+func (s *WriterSink) Publish(ctx context.Context, event model.OutboxEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(line, '\n'))
+	return err
+}
+
+// FileSink appends events to a file as newline-delimited JSON and syncs
+// after every event.
+type FileSink struct {
+	WriterSink
+	file *os.File
+}
+
+func NewFileSink(path string) (*FileSink, error) {
+	if path == "" {
+		return nil, fmt.Errorf("file sink needs a path")
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSink{WriterSink: WriterSink{w: file}, file: file}, nil
+}
+
+func (s *FileSink) Publish(ctx context.Context, event model.OutboxEvent) error {
+	if err := s.WriterSink.Publish(ctx, event); err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
+
+// HTTPSink POSTs each event's payload and expects a 2xx response.
+type HTTPSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewHTTPSink(url string) *HTTPSink {
+	return &HTTPSink{
+		url:    url,
+		client: &http.Client{Timeout: sinkTimeout},
+	}
+}
+
+func (s *HTTPSink) Publish(ctx context.Context, event model.OutboxEvent) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(event.Payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-Type", event.Type)
+	req.Header.Set("X-Event-ID", event.EventID.String())
+	req.Header.Set("X-Event-Sequence", strconv.FormatInt(event.Sequence, 10))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("http sink: unexpected response status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// NATSSink publishes to a NATS-compatible server using the plain text
+// protocol. Each publish is followed by a PING so that it only succeeds once
+// the server has processed the message.
+type NATSSink struct {
+	addr    string
+	subject string
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func NewNATSSink(rawURL, subject string) (*NATSSink, error) {
+	if rawURL == "" {
+		return nil, fmt.Errorf("nats sink needs a url")
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	addr := parsed.Host
+	if parsed.Port() == "" {
+		addr = net.JoinHostPort(parsed.Hostname(), "4222")
+	}
+	if subject == "" {
+		subject = "subscriptions"
+	}
+	return &NATSSink{addr: addr, subject: subject}, nil
+}
+
+func (s *NATSSink) Publish(ctx context.Context, event model.OutboxEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		if err := s.connect(ctx); err != nil {
+			return fmt.Errorf("nats sink: %w", err)
+		}
+	}
+	if err := s.publish(event); err != nil {
+		s.conn.Close()
+		s.conn = nil
+		return fmt.Errorf("nats sink: %w", err)
+	}
+	return nil
+}
+
+func (s *NATSSink) connect(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: sinkTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(sinkTimeout))
+	reader := bufio.NewReader(conn)
+
+	info, err := reader.ReadString('\n')
+	if err != nil || !strings.HasPrefix(info, "INFO") {
+		conn.Close()
+		return fmt.Errorf("unexpected greeting %q: %v", strings.TrimSpace(info), err)
+	}
+	if _, err := io.WriteString(conn, "CONNECT {\"verbose\":false,\"pedantic\":false,\"name\":\"subs-outbox\"}\r\n"); err != nil {
+		conn.Close()
+		return err
+	}
+	s.conn, s.reader = conn, reader
+	return nil
+}
+
+func (s *NATSSink) publish(event model.OutboxEvent) error {
+	s.conn.SetDeadline(time.Now().Add(sinkTimeout))
+	subject := s.subject + "." + event.Type
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "PUB %s %d\r\n", subject, len(event.Payload))
+	msg.Write(event.Payload)
+	msg.WriteString("\r\nPING\r\n")
+	if _, err := s.conn.Write(msg.Bytes()); err != nil {
+		return err
+	}
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		switch line = strings.TrimSpace(line); {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := io.WriteString(s.conn, "PONG\r\n"); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("server error: %s", line)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/model"
+)
+
+func testOutboxEvent() model.OutboxEvent {
+	return model.OutboxEvent{
+		Sequence:    42,
+		EventID:     uuid.New(),
+		AggregateID: uuid.New(),
+		Type:        model.EventSubscriptionCreated,
+		Payload:     json.RawMessage(`{"type":"subscription.created"}`),
+	}
+}
+
+func TestWriterSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewWriterSink(&buf)
+	event := testOutboxEvent()
+	for i := 0; i < 2; i++ {
+		if err := sink.Publish(context.Background(), event); err != nil {
+			t.Fatalf("Publish: %v", err)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("want 2 lines, got %d: %q", len(lines), buf.String())
+	}
+	var decoded model.OutboxEvent
+	if err := json.Unmarshal([]byte(lines[0]), &decoded); err != nil {
+		t.Fatalf("line is not JSON: %v", err)
+	}
+	if decoded.EventID != event.EventID || decoded.Sequence != event.Sequence {
+		t.Fatalf("decoded %+v, want %+v", decoded, event)
+	}
+}
+
+func TestHTTPSink(t *testing.T) {
+	event := testOutboxEvent()
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != string(event.Payload) {
+			t.Errorf("body: want %s, got %s", event.Payload, body)
+		}
+		if r.Header.Get("X-Event-Sequence") != "42" {
+			t.Errorf("sequence header: got %q", r.Header.Get("X-Event-Sequence"))
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sink := NewHTTPSink(server.URL)
+	if err := sink.Publish(context.Background(), event); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	status = http.StatusServiceUnavailable
+	if err := sink.Publish(context.Background(), event); err == nil {
+		t.Fatal("Publish with a 503 response: want error, got nil")
+	}
+}
+
+func TestNATSSink(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	defer listener.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		io.WriteString(conn, "INFO {\"server_id\":\"test\"}\r\n")
+		reader := bufio.NewReader(conn)
+		var published strings.Builder
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch {
+			case strings.HasPrefix(line, "PUB "):
+				payload, _ := reader.ReadString('\n')
+				published.WriteString(line + payload)
+			case strings.HasPrefix(line, "PING"):
+				io.WriteString(conn, "PONG\r\n")
+				received <- published.String()
+				return
+			}
+		}
+	}()
+
+	sink, err := NewNATSSink("nats://"+listener.Addr().String(), "subs")
+	if err != nil {
+		t.Fatalf("NewNATSSink: %v", err)
+	}
+	event := testOutboxEvent()
+	if err := sink.Publish(context.Background(), event); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	want := "PUB subs.subscription.created 31\r\n" + string(event.Payload) + "\r\n"
+	if got := <-received; got != want {
+		t.Fatalf("want %q, got %q", want, got)
+	}
+}
+
+func TestNewEventSinkRejectsUnknownType(t *testing.T) {
+	if _, err := NewEventSink(SinkConfig{Type: "kafka"}); err == nil {
+		t.Fatal("want error for an unknown sink type")
+	}
+}
@@ -0,0 +1,279 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/logging"
+	"github.com/lavatee/subs/internal/model"
+	"github.com/lavatee/subs/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	eventStreamBatchSize  = 500
+	eventSubscriberBuffer = 64
+)
+
+// streamEventTypes are the outbox events published on the live stream.
+var streamEventTypes = []string{
+	model.EventSubscriptionCreated,
+	model.EventSubscriptionUpdated,
+	model.EventSubscriptionDeleted,
+	model.EventSubscriptionArchived,
+}
+
+var errEventStreamNotRunning = fmt.Errorf("event stream is not running: %w", model.ErrUnavailable)
+
+// EventsService fans outbox events out to live subscribers. It follows the
+// outbox from a single polling loop and keeps the most recent events in a
+// ring buffer, so that reconnecting clients can resume where they stopped.
+type EventsService struct {
+	repo   *repository.Repository
+	logger *logrus.Logger
+
+	mu          sync.Mutex
+	running     bool
+	ring        eventRing
+	last        model.EventPosition
+	floor       model.EventPosition
+	subscribers map[*EventSubscription]struct{}
+}
+
+func NewEventsService(repo *repository.Repository, logger *logrus.Logger) *EventsService {
+	return &EventsService{
+		repo:        repo,
+		logger:      logger,
+		subscribers: make(map[*EventSubscription]struct{}),
+	}
+}
+
+// EventSubscription is one stream client. Backlog holds the buffered events
+// it missed since the position it resumed from; Reset reports that some of
+// them are no longer buffered and the client has to reload its state.
+// Position is what the client has caught up to once Backlog is sent. Events
+// then delivers live events and is closed when the client falls too far
+// behind or the stream stops.
+type EventSubscription struct {
+	Backlog  []model.StreamEvent
+	Reset    bool
+	Position model.EventPosition
+	Events   <-chan model.StreamEvent
+
+	events chan model.StreamEvent
+	userID uuid.UUID
+	close  func()
+}
+
+func (s *EventSubscription) Close() {
+	s.close()
+}
+
+// Subscribe registers a stream client interested in userID, or in every user
+// for uuid.Nil, resuming after the given position when it is not nil.
+func (s *EventsService) Subscribe(ctx context.Context, userID uuid.UUID, after *model.EventPosition) (*EventSubscription, error) {
+	events := make(chan model.StreamEvent, eventSubscriberBuffer)
+	sub := &EventSubscription{Events: events, events: events, userID: userID}
+	sub.close = func() { s.unsubscribe(sub) }
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.running {
+		return nil, errEventStreamNotRunning
+	}
+	if after != nil {
+		if after.Less(s.floor) {
+			sub.Reset = true
+		} else {
+			for _, event := range s.ring.after(*after) {
+				if sub.wants(event) {
+					sub.Backlog = append(sub.Backlog, event)
+				}
+			}
+		}
+	}
+	sub.Position = s.last
+	s.subscribers[sub] = struct{}{}
+	return sub, nil
+}
+
+func (s *EventsService) unsubscribe(sub *EventSubscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subscribers[sub]; ok {
+		delete(s.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+func (s *EventSubscription) wants(event model.StreamEvent) bool {
+	return s.userID == uuid.Nil || s.userID == event.UserID
+}
+
+// RunHub follows the outbox every interval until ctx is cancelled, keeping
+// the last bufferSize stream events for resuming clients.
+func (s *EventsService) RunHub(ctx context.Context, interval time.Duration, bufferSize int) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	defer s.stop()
+	for !s.start(ctx, bufferSize) {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.poll(ctx)
+		}
+	}
+}
+
+// start fills the ring buffer with the most recent events. Clients resuming
+// from before them, or from before the last event trimmed from the outbox,
+// may have missed events and are reset.
+func (s *EventsService) start(ctx context.Context, bufferSize int) bool {
+	trimmed, err := s.repo.Outbox.GetTrimmedPosition(ctx)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to load the trimmed outbox position for the event stream: %v", err)
+		return false
+	}
+	recent, err := s.repo.Outbox.GetLastEvents(ctx, bufferSize)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to load recent events for the event stream: %v", err)
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ring = newEventRing(bufferSize)
+	s.floor = trimmed
+	if len(recent) == bufferSize && s.floor.Less(recent[0].Position()) {
+		s.floor = recent[0].Position()
+	}
+	for _, event := range recent {
+		s.last = event.Position()
+		if stream, ok := s.toStreamEvent(event); ok {
+			s.ring.push(stream)
+		}
+	}
+	s.running = true
+	return true
+}
+
+func (s *EventsService) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running = false
+	for sub := range s.subscribers {
+		delete(s.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+func (s *EventsService) poll(ctx context.Context) {
+	for {
+		s.mu.Lock()
+		after := s.last
+		s.mu.Unlock()
+
+		events, err := s.repo.Outbox.GetEventsAfter(ctx, after, eventStreamBatchSize)
+		if err != nil {
+			logging.FromContext(ctx, s.logger).Errorf("Failed to read events for the event stream: %v", err)
+			return
+		}
+		s.publish(events)
+		if len(events) < eventStreamBatchSize {
+			return
+		}
+	}
+}
+
+func (s *EventsService) publish(events []model.OutboxEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, event := range events {
+		s.last = event.Position()
+		stream, ok := s.toStreamEvent(event)
+		if !ok {
+			continue
+		}
+		if evicted, ok := s.ring.push(stream); ok {
+			s.floor = evicted.Position
+		}
+		for sub := range s.subscribers {
+			if !sub.wants(stream) {
+				continue
+			}
+			select {
+			case sub.events <- stream:
+			default:
+				// The client can't keep up; it reconnects and resumes.
+				delete(s.subscribers, sub)
+				close(sub.events)
+			}
+		}
+	}
+}
+
+func (s *EventsService) toStreamEvent(event model.OutboxEvent) (model.StreamEvent, bool) {
+	if !slices.Contains(streamEventTypes, event.Type) {
+		return model.StreamEvent{}, false
+	}
+	var envelope model.Event
+	if err := json.Unmarshal(event.Payload, &envelope); err != nil || envelope.Subscription == nil {
+		s.logger.Warnf("Skipping malformed outbox event %d on the event stream: %v", event.Sequence, err)
+		return model.StreamEvent{}, false
+	}
+	return model.StreamEvent{
+		Position: event.Position(),
+		Type:     event.Type,
+		UserID:   envelope.Subscription.UserID,
+		Payload:  event.Payload,
+	}, true
+}
+
+// eventRing keeps the last len(events) stream events, oldest first.
+type eventRing struct {
+	events []model.StreamEvent
+	start  int
+	size   int
+}
+
+func newEventRing(capacity int) eventRing {
+	return eventRing{events: make([]model.StreamEvent, max(capacity, 1))}
+}
+
+// push appends event and returns the event it evicted, if any.
+func (r *eventRing) push(event model.StreamEvent) (model.StreamEvent, bool) {
+	if r.size < len(r.events) {
+		r.events[(r.start+r.size)%len(r.events)] = event
+		r.size++
+		return model.StreamEvent{}, false
+	}
+	evicted := r.events[r.start]
+	r.events[r.start] = event
+	r.start = (r.start + 1) % len(r.events)
+	return evicted, true
+}
+
+// after returns the buffered events that follow position, oldest first.
+func (r *eventRing) after(position model.EventPosition) []model.StreamEvent {
+	var events []model.StreamEvent
+	for i := 0; i < r.size; i++ {
+		event := r.events[(r.start+i)%len(r.events)]
+		if position.Less(event.Position) {
+			events = append(events, event)
+		}
+	}
+	return events
+}
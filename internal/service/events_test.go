@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/model"
+	"github.com/lavatee/subs/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+func newTestEventsService(bufferSize int) *EventsService {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	s := NewEventsService(nil, logger)
+	s.ring = newEventRing(bufferSize)
+	s.running = true
+	return s
+}
+
+func outboxEventFor(t *testing.T, txID uint64, sequence int64, eventType string, userID uuid.UUID) model.OutboxEvent {
+	t.Helper()
+	sub := model.Subscription{ID: uuid.New(), UserID: userID}
+	payload, err := json.Marshal(model.NewSubscriptionEvent(eventType, sub))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return model.OutboxEvent{Sequence: sequence, TxID: txID, Type: eventType, AggregateID: sub.ID, Payload: payload}
+}
+
+func TestEventsServiceFanOut(t *testing.T) {
+	s := newTestEventsService(8)
+	alice, bob := uuid.New(), uuid.New()
+
+	all, err := s.Subscribe(context.Background(), uuid.Nil, nil)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	defer all.Close()
+	onlyBob, err := s.Subscribe(context.Background(), bob, nil)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	defer onlyBob.Close()
+
+	s.publish([]model.OutboxEvent{
+		outboxEventFor(t, 10, 1, model.EventSubscriptionCreated, alice),
+		outboxEventFor(t, 10, 2, model.EventBudgetExceeded, bob),
+		outboxEventFor(t, 11, 3, model.EventSubscriptionDeleted, bob),
+	})
+
+	if got := len(all.Events); got != 2 {
+		t.Fatalf("unfiltered subscriber: want 2 events, got %d", got)
+	}
+	event := <-onlyBob.Events
+	if event.Type != model.EventSubscriptionDeleted || event.UserID != bob || event.Position.String() != "11-3" {
+		t.Fatalf("filtered subscriber got %+v", event)
+	}
+	if len(onlyBob.Events) != 0 {
+		t.Fatal("filtered subscriber got another user's events")
+	}
+}
+
+func TestEventsServiceResume(t *testing.T) {
+	s := newTestEventsService(2)
+	user := uuid.New()
+	var events []model.OutboxEvent
+	for i := int64(1); i <= 3; i++ {
+		events = append(events, outboxEventFor(t, 10, i, model.EventSubscriptionUpdated, user))
+	}
+	s.publish(events)
+
+	resumed, err := s.Subscribe(context.Background(), uuid.Nil, &model.EventPosition{TxID: 10, Sequence: 1})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	defer resumed.Close()
+	if resumed.Reset || len(resumed.Backlog) != 2 || resumed.Backlog[0].Position.Sequence != 2 {
+		t.Fatalf("resume within the buffer: got reset=%v backlog=%+v", resumed.Reset, resumed.Backlog)
+	}
+	if resumed.Position.String() != "10-3" {
+		t.Fatalf("resume position: want 10-3, got %s", resumed.Position)
+	}
+
+	stale, err := s.Subscribe(context.Background(), uuid.Nil, &model.EventPosition{TxID: 9, Sequence: 7})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	defer stale.Close()
+	if !stale.Reset || len(stale.Backlog) != 0 {
+		t.Fatalf("resume before the buffer: got reset=%v backlog=%+v", stale.Reset, stale.Backlog)
+	}
+}
+
+// fakeOutboxRepo serves the event stream's startup queries.
+type fakeOutboxRepo struct {
+	repository.Outbox
+	events  []model.OutboxEvent
+	trimmed model.EventPosition
+}
+
+func (f *fakeOutboxRepo) GetTrimmedPosition(ctx context.Context) (model.EventPosition, error) {
+	return f.trimmed, nil
+}
+
+func (f *fakeOutboxRepo) GetLastEvents(ctx context.Context, limit int) ([]model.OutboxEvent, error) {
+	return f.events[max(len(f.events)-limit, 0):], nil
+}
+
+func TestEventsServiceResumeAfterTrim(t *testing.T) {
+	user := uuid.New()
+	outbox := &fakeOutboxRepo{
+		events: []model.OutboxEvent{
+			outboxEventFor(t, 12, 6, model.EventSubscriptionUpdated, user),
+			outboxEventFor(t, 12, 7, model.EventSubscriptionUpdated, user),
+		},
+		trimmed: model.EventPosition{TxID: 11, Sequence: 5},
+	}
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	s := NewEventsService(&repository.Repository{Outbox: outbox}, logger)
+	if !s.start(context.Background(), 8) {
+		t.Fatal("start failed")
+	}
+
+	stale, err := s.Subscribe(context.Background(), uuid.Nil, &model.EventPosition{TxID: 10, Sequence: 3})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	defer stale.Close()
+	if !stale.Reset || len(stale.Backlog) != 0 {
+		t.Fatalf("resume before the trimmed events: got reset=%v backlog=%+v", stale.Reset, stale.Backlog)
+	}
+
+	resumed, err := s.Subscribe(context.Background(), uuid.Nil, &model.EventPosition{TxID: 11, Sequence: 5})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	defer resumed.Close()
+	if resumed.Reset || len(resumed.Backlog) != 2 {
+		t.Fatalf("resume from the last trimmed event: got reset=%v backlog=%+v", resumed.Reset, resumed.Backlog)
+	}
+}
+
+func TestEventsServiceDropsSlowSubscriber(t *testing.T) {
+	s := newTestEventsService(4)
+	sub, err := s.Subscribe(context.Background(), uuid.Nil, nil)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	defer sub.Close()
+
+	user := uuid.New()
+	var events []model.OutboxEvent
+	for i := int64(1); i <= eventSubscriberBuffer+1; i++ {
+		events = append(events, outboxEventFor(t, 10, i, model.EventSubscriptionCreated, user))
+	}
+	s.publish(events)
+
+	received := 0
+	for range sub.Events {
+		received++
+	}
+	if received != eventSubscriberBuffer {
+		t.Fatalf("want %d events before the stream closes, got %d", eventSubscriberBuffer, received)
+	}
+}
+
+func TestEventsServiceNotRunning(t *testing.T) {
+	s := NewEventsService(nil, logrus.New())
+	if _, err := s.Subscribe(context.Background(), uuid.Nil, nil); err == nil {
+		t.Fatal("want an error before the hub has started")
+	}
+}
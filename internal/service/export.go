@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/logging"
+	"github.com/lavatee/subs/internal/model"
+	"github.com/lavatee/subs/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	ExportCSV    = "csv"
+	ExportNDJSON = "ndjson"
+	ExportXLSX   = "xlsx"
+)
+
+// exportColumns are written in this order by every format. Dates use the
+// MM-YYYY format of the API, so exported files can be imported again.
+var exportColumns = []string{"id", "service_name", "price", "user_id", "start_date", "end_date", "created_at"}
+
+// ExportContentType returns the media type of an export format, or an error
+// wrapping model.ErrInvalidInput for unknown formats.
+func ExportContentType(format string) (string, error) {
+	switch format {
+	case ExportCSV:
+		return "text/csv; charset=utf-8", nil
+	case ExportNDJSON:
+		return "application/x-ndjson", nil
+	case ExportXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", nil
+	}
+	return "", fmt.Errorf("%w: unknown export format %q, expected csv, ndjson or xlsx", model.ErrInvalidInput, format)
+}
+
+type ExportService struct {
+	repo   *repository.Repository
+	logger *logrus.Logger
+}
+
+func NewExportService(repo *repository.Repository, logger *logrus.Logger) *ExportService {
+	return &ExportService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// ExportSubscriptions writes the subscriptions matching the list filters to w
+// in format, row by row as they are read from the database.
+func (s *ExportService) ExportSubscriptions(ctx context.Context, w io.Writer, format string, userID uuid.UUID, serviceName string, includeArchived bool) error {
+	rows, err := newExportWriter(w, format)
+	if err != nil {
+		return err
+	}
+	if err := rows.WriteRow(exportColumns); err != nil {
+		return err
+	}
+
+	count := 0
+	err = s.repo.Subscriptions.StreamUserSubscriptions(ctx, userID, serviceName, includeArchived, func(sub model.Subscription) error {
+		count++
+		return rows.WriteRow(exportRow(sub))
+	})
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to export subscriptions after %d rows: %v", count, err)
+		return err
+	}
+	return rows.Close()
+}
+
+func exportRow(sub model.Subscription) []string {
+	endDate := ""
+	if sub.EndDate != nil {
+		endDate = sub.EndDate.Format("01-2006")
+	}
+	return []string{
+		sub.ID.String(),
+		sub.ServiceName,
+		strconv.Itoa(sub.Price),
+		sub.UserID.String(),
+		sub.StartDate.Format("01-2006"),
+		endDate,
+		sub.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// exportWriter writes rows of exportColumns; the first row is the header.
+type exportWriter interface {
+	WriteRow(values []string) error
+	Close() error
+}
+
+func newExportWriter(w io.Writer, format string) (exportWriter, error) {
+	switch format {
+	case ExportCSV:
+		return &csvExportWriter{csv: csv.NewWriter(w)}, nil
+	case ExportNDJSON:
+		return &ndjsonExportWriter{encoder: json.NewEncoder(w)}, nil
+	case ExportXLSX:
+		return newXLSXWriter(w, "Subscriptions")
+	}
+	_, err := ExportContentType(format)
+	return nil, err
+}
+
+type csvExportWriter struct {
+	csv  *csv.Writer
+	rows int
+}
+
+func (c *csvExportWriter) WriteRow(values []string) error {
+	if err := c.csv.Write(values); err != nil {
+		return err
+	}
+	// Flush periodically so the client sees progress on big exports.
+	if c.rows++; c.rows%500 == 0 {
+		c.csv.Flush()
+		return c.csv.Error()
+	}
+	return nil
+}
+
+func (c *csvExportWriter) Close() error {
+	c.csv.Flush()
+	return c.csv.Error()
+}
+
+// ndjsonExportWriter writes one object per row keyed by the header names,
+// with price as a number and a missing end_date as null.
+type ndjsonExportWriter struct {
+	encoder *json.Encoder
+	header  []string
+}
+
+func (n *ndjsonExportWriter) WriteRow(values []string) error {
+	if n.header == nil {
+		n.header = values
+		return nil
+	}
+	object := make(map[string]interface{}, len(values))
+	for i, value := range values {
+		switch column := n.header[i]; {
+		case column == "price":
+			price, _ := strconv.Atoi(value)
+			object[column] = price
+		case value == "":
+			object[column] = nil
+		default:
+			object[column] = value
+		}
+	}
+	return n.encoder.Encode(object)
+}
+
+func (n *ndjsonExportWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/model"
+)
+
+func exportTestSubscriptions() []model.Subscription {
+	end := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	userID := uuid.MustParse("60601fee-2bf1-4721-ae6f-7636e79a0cba")
+	return []model.Subscription{
+		{ID: uuid.New(), ServiceName: "Netflix", Price: 400, UserID: userID, StartDate: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), CreatedAt: time.Now()},
+		{ID: uuid.New(), ServiceName: `Yandex "Plus" & <more>`, Price: 300, UserID: userID, StartDate: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), EndDate: &end, CreatedAt: time.Now()},
+	}
+}
+
+func writeExport(t *testing.T, format string, subs []model.Subscription) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	rows, err := newExportWriter(&buf, format)
+	if err != nil {
+		t.Fatalf("newExportWriter(%s): %v", format, err)
+	}
+	if err := rows.WriteRow(exportColumns); err != nil {
+		t.Fatalf("WriteRow: %v", err)
+	}
+	for _, sub := range subs {
+		if err := rows.WriteRow(exportRow(sub)); err != nil {
+			t.Fatalf("WriteRow: %v", err)
+		}
+	}
+	if err := rows.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestExportCSVRoundTripsThroughImport(t *testing.T) {
+	out := writeExport(t, ExportCSV, exportTestSubscriptions())
+	if !strings.HasPrefix(string(out), "id,service_name,price,user_id,start_date,end_date,created_at\n") {
+		t.Fatalf("unexpected header in %q", out)
+	}
+
+	report, err := newTestImportService().ImportSubscriptions(context.Background(), bytes.NewReader(out), ImportOptions{
+		Format: ImportCSV,
+		DryRun: true,
+	})
+	if err != nil {
+		t.Fatalf("ImportSubscriptions: %v", err)
+	}
+	if report.Total != 2 || report.Valid != 2 {
+		t.Fatalf("exported CSV did not import cleanly: %+v", report)
+	}
+}
+
+func TestExportNDJSON(t *testing.T) {
+	subs := exportTestSubscriptions()
+	out := writeExport(t, ExportNDJSON, subs)
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("want 2 lines, got %d: %q", len(lines), out)
+	}
+
+	var first, second map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatalf("line 1: %v", err)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &second); err != nil {
+		t.Fatalf("line 2: %v", err)
+	}
+	if first["id"] != subs[0].ID.String() || first["price"] != float64(400) || first["end_date"] != nil {
+		t.Fatalf("unexpected first object %v", first)
+	}
+	if second["service_name"] != subs[1].ServiceName || second["end_date"] != "12-2025" {
+		t.Fatalf("unexpected second object %v", second)
+	}
+}
+
+func TestExportXLSX(t *testing.T) {
+	subs := exportTestSubscriptions()
+	out := writeExport(t, ExportXLSX, subs)
+
+	archive, err := zip.NewReader(bytes.NewReader(out), int64(len(out)))
+	if err != nil {
+		t.Fatalf("export is not a zip archive: %v", err)
+	}
+	files := make(map[string]*zip.File)
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"} {
+		if files[name] == nil {
+			t.Fatalf("workbook part %s is missing", name)
+		}
+	}
+
+	r, err := files["xl/worksheets/sheet1.xml"].Open()
+	if err != nil {
+		t.Fatalf("open sheet: %v", err)
+	}
+	defer r.Close()
+	var sheet struct {
+		Rows []struct {
+			Ref   string `xml:"r,attr"`
+			Cells []struct {
+				Ref    string `xml:"r,attr"`
+				Type   string `xml:"t,attr"`
+				Value  string `xml:"v"`
+				Inline string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := xml.NewDecoder(r).Decode(&sheet); err != nil {
+		t.Fatalf("sheet is not valid XML: %v", err)
+	}
+	if len(sheet.Rows) != 3 || len(sheet.Rows[2].Cells) != len(exportColumns) {
+		t.Fatalf("unexpected sheet layout %+v", sheet.Rows)
+	}
+	name, price := sheet.Rows[2].Cells[1], sheet.Rows[2].Cells[2]
+	if name.Ref != "B3" || name.Inline != subs[1].ServiceName {
+		t.Fatalf("unexpected name cell %+v", name)
+	}
+	if price.Ref != "C3" || price.Type != "" || price.Value != "300" {
+		t.Fatalf("price should be a number cell, got %+v", price)
+	}
+}
+
+func TestExportRejectsUnknownFormat(t *testing.T) {
+	if _, err := ExportContentType("pdf"); !errors.Is(err, model.ErrInvalidInput) {
+		t.Fatalf("ExportContentType(pdf): want ErrInvalidInput, got %v", err)
+	}
+	if _, err := newExportWriter(io.Discard, "pdf"); !errors.Is(err, model.ErrInvalidInput) {
+		t.Fatalf("newExportWriter(pdf): want ErrInvalidInput, got %v", err)
+	}
+}
+
+func TestXLSXColumn(t *testing.T) {
+	for index, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"} {
+		if got := xlsxColumn(index); got != want {
+			t.Errorf("xlsxColumn(%d) = %s, want %s", index, got, want)
+		}
+	}
+}
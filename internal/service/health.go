@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lavatee/subs/internal/model"
+	"github.com/lavatee/subs/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// healthCheckTimeout bounds the database checks of a readiness probe, which
+// must answer before the probe itself times out.
+const healthCheckTimeout = 2 * time.Second
+
+// HealthService answers liveness and readiness probes. Readiness requires
+// the database to answer, the schema to be at least at migrationVersion (a
+// newer one is applied by a newer release during a rolling deploy) and every
+// worker started with RunWorker to still run; it fails for good once
+// StartShutdown is called, so that load balancers stop sending requests
+// before the server drains.
+type HealthService struct {
+	repo             *repository.Repository
+	logger           *logrus.Logger
+	migrationVersion uint
+	shuttingDown     atomic.Bool
+
+	mu      sync.Mutex
+	stopped []string
+	workers sync.WaitGroup
+}
+
+func NewHealthService(repo *repository.Repository, logger *logrus.Logger, migrationVersion uint) *HealthService {
+	return &HealthService{
+		repo:             repo,
+		logger:           logger,
+		migrationVersion: migrationVersion,
+	}
+}
+
+func (s *HealthService) Live() model.HealthResponse {
+	return model.HealthResponse{Status: model.HealthOK}
+}
+
+// Ready runs the readiness checks and reports whether all of them passed.
+func (s *HealthService) Ready(ctx context.Context) (model.HealthResponse, bool) {
+	if s.shuttingDown.Load() {
+		return healthResponse([]model.HealthCheck{failingCheck("shutdown", "shutting down")})
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+	checks := []model.HealthCheck{s.checkDatabase(ctx), s.checkMigrations(ctx), s.checkWorkers()}
+	return healthResponse(checks)
+}
+
+func (s *HealthService) checkDatabase(ctx context.Context) model.HealthCheck {
+	if err := s.repo.Health.Ping(ctx); err != nil {
+		return failingCheck("database", err.Error())
+	}
+	return model.HealthCheck{Name: "database", Status: model.HealthOK}
+}
+
+func (s *HealthService) checkMigrations(ctx context.Context) model.HealthCheck {
+	version, err := s.repo.Health.GetMigrationVersion(ctx)
+	if err != nil {
+		return failingCheck("migrations", err.Error())
+	}
+	if version.Dirty {
+		return failingCheck("migrations", fmt.Sprintf("migration %d failed halfway", version.Version))
+	}
+	if version.Version < s.migrationVersion {
+		return failingCheck("migrations", fmt.Sprintf("schema is at version %d, want at least %d", version.Version, s.migrationVersion))
+	}
+	return model.HealthCheck{Name: "migrations", Status: model.HealthOK}
+}
+
+func (s *HealthService) checkWorkers() model.HealthCheck {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.stopped) > 0 {
+		return failingCheck("workers", fmt.Sprintf("stopped: %v", s.stopped))
+	}
+	return model.HealthCheck{Name: "workers", Status: model.HealthOK}
+}
+
+// StartShutdown makes readiness fail from now on.
+func (s *HealthService) StartShutdown() {
+	s.shuttingDown.Store(true)
+}
+
+// RunWorker runs a background worker in its own goroutine. Workers return
+// when their context is cancelled; one that returns before shutdown makes
+// readiness fail.
+func (s *HealthService) RunWorker(name string, run func()) {
+	s.workers.Add(1)
+	go func() {
+		defer s.workers.Done()
+		run()
+		if s.shuttingDown.Load() {
+			return
+		}
+		s.logger.Errorf("Worker %s stopped unexpectedly", name)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if !slices.Contains(s.stopped, name) {
+			s.stopped = append(s.stopped, name)
+		}
+	}()
+}
+
+// WaitWorkers waits for the workers started with RunWorker to return, or
+// for ctx to be done.
+func (s *HealthService) WaitWorkers(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func failingCheck(name, reason string) model.HealthCheck {
+	return model.HealthCheck{Name: name, Status: model.HealthFailing, Error: reason}
+}
+
+func healthResponse(checks []model.HealthCheck) (model.HealthResponse, bool) {
+	for _, check := range checks {
+		if check.Status != model.HealthOK {
+			return model.HealthResponse{Status: model.HealthFailing, Checks: checks}, false
+		}
+	}
+	return model.HealthResponse{Status: model.HealthOK, Checks: checks}, true
+}
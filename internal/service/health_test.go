@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lavatee/subs/internal/model"
+	"github.com/lavatee/subs/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+type fakeHealthRepo struct {
+	pingErr error
+	version model.MigrationVersion
+}
+
+func (f *fakeHealthRepo) Ping(ctx context.Context) error {
+	return f.pingErr
+}
+
+func (f *fakeHealthRepo) GetMigrationVersion(ctx context.Context) (model.MigrationVersion, error) {
+	return f.version, nil
+}
+
+func newTestHealth(repo *fakeHealthRepo) *HealthService {
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	return NewHealthService(&repository.Repository{Health: repo}, logger, 10)
+}
+
+func TestHealthReady(t *testing.T) {
+	tests := []struct {
+		name   string
+		repo   fakeHealthRepo
+		failed string
+	}{
+		{name: "healthy", repo: fakeHealthRepo{version: model.MigrationVersion{Version: 10}}},
+		{name: "database down", repo: fakeHealthRepo{pingErr: errors.New("connection refused"), version: model.MigrationVersion{Version: 10}}, failed: "database"},
+		{name: "schema ahead", repo: fakeHealthRepo{version: model.MigrationVersion{Version: 11}}},
+		{name: "schema behind", repo: fakeHealthRepo{version: model.MigrationVersion{Version: 9}}, failed: "migrations"},
+		{name: "dirty migration", repo: fakeHealthRepo{version: model.MigrationVersion{Version: 10, Dirty: true}}, failed: "migrations"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			health, ready := newTestHealth(&tt.repo).Ready(context.Background())
+			if ready != (tt.failed == "") {
+				t.Fatalf("ready: got %v, checks %+v", ready, health.Checks)
+			}
+			for _, check := range health.Checks {
+				if (check.Status == model.HealthFailing) != (check.Name == tt.failed) {
+					t.Fatalf("check %s: got %s (%s)", check.Name, check.Status, check.Error)
+				}
+			}
+		})
+	}
+}
+
+func TestHealthWorkersAndShutdown(t *testing.T) {
+	s := newTestHealth(&fakeHealthRepo{version: model.MigrationVersion{Version: 10}})
+	ctx, stop := context.WithCancel(context.Background())
+	s.RunWorker("blocking", func() { <-ctx.Done() })
+	s.RunWorker("crashing", func() {})
+
+	waitCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	for {
+		if _, ready := s.Ready(context.Background()); !ready {
+			break
+		}
+		select {
+		case <-waitCtx.Done():
+			t.Fatal("a worker that returned early must fail readiness")
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	s.StartShutdown()
+	health, ready := s.Ready(context.Background())
+	if ready || len(health.Checks) != 1 || health.Checks[0].Name != "shutdown" {
+		t.Fatalf("readiness during shutdown: %+v", health)
+	}
+	stop()
+	if err := s.WaitWorkers(waitCtx); err != nil {
+		t.Fatalf("WaitWorkers: %v", err)
+	}
+}
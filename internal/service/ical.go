@@ -0,0 +1,56 @@
+package service
+
+import (
+	"bytes"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// icalLineLimit is the RFC 5545 limit on line length in octets, without CRLF.
+const icalLineLimit = 75
+
+var icalEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// writeICal renders events as an RFC 5545 calendar of all-day events.
+func writeICal(buf *bytes.Buffer, events []calendarEvent, now time.Time) {
+	stamp := now.UTC().Format("20060102T150405Z")
+	writeICalLine(buf, "BEGIN:VCALENDAR")
+	writeICalLine(buf, "VERSION:2.0")
+	writeICalLine(buf, "PRODID:-//lavatee//subs//EN")
+	writeICalLine(buf, "CALSCALE:GREGORIAN")
+	writeICalLine(buf, "METHOD:PUBLISH")
+	writeICalLine(buf, "X-WR-CALNAME:Subscriptions")
+	for _, event := range events {
+		writeICalLine(buf, "BEGIN:VEVENT")
+		writeICalLine(buf, "UID:"+event.UID+"@subs")
+		writeICalLine(buf, "DTSTAMP:"+stamp)
+		writeICalLine(buf, "DTSTART;VALUE=DATE:"+event.Date.Format("20060102"))
+		writeICalLine(buf, "DTEND;VALUE=DATE:"+event.Date.AddDate(0, 0, 1).Format("20060102"))
+		writeICalLine(buf, "SUMMARY:"+icalEscaper.Replace(event.Summary))
+		writeICalLine(buf, "DESCRIPTION:"+icalEscaper.Replace(event.Description))
+		writeICalLine(buf, "TRANSP:TRANSPARENT")
+		writeICalLine(buf, "END:VEVENT")
+	}
+	writeICalLine(buf, "END:VCALENDAR")
+}
+
+// writeICalLine writes a content line, folding it into continuation lines
+// that start with a space when it is too long. Folds never split a UTF-8
+// sequence.
+func writeICalLine(buf *bytes.Buffer, line string) {
+	limit := icalLineLimit
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n ")
+		line = line[cut:]
+		// The leading space counts towards the next line's length.
+		limit = icalLineLimit - 1
+	}
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
@@ -0,0 +1,319 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/logging"
+	"github.com/lavatee/subs/internal/model"
+	"github.com/lavatee/subs/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	ImportCSV    = "csv"
+	ImportNDJSON = "ndjson"
+
+	importMaxLine = 1 << 20
+)
+
+// importFields are the CreateSubscriptionRequest fields an import fills.
+var importFields = []string{"service_name", "price", "user_id", "start_date", "end_date"}
+
+type ImportOptions struct {
+	// Format is ImportCSV or ImportNDJSON.
+	Format string
+	// DryRun validates every row without writing anything.
+	DryRun bool
+	// Mapping maps request fields to CSV columns or NDJSON keys. Fields
+	// without a mapping are read from the column or key of the same name.
+	Mapping map[string]string
+}
+
+// ParseImportMapping parses "field=column,field=column" into a mapping.
+func ParseImportMapping(value string) (map[string]string, error) {
+	mapping := make(map[string]string)
+	if value == "" {
+		return mapping, nil
+	}
+	for _, pair := range strings.Split(value, ",") {
+		field, column, ok := strings.Cut(pair, "=")
+		field, column = strings.TrimSpace(field), strings.TrimSpace(column)
+		if !ok || column == "" {
+			return nil, fmt.Errorf("%w: invalid mapping %q, expected field=column", model.ErrInvalidInput, pair)
+		}
+		if !slices.Contains(importFields, field) {
+			return nil, fmt.Errorf("%w: unknown mapping field %q", model.ErrInvalidInput, field)
+		}
+		mapping[field] = column
+	}
+	return mapping, nil
+}
+
+type ImportService struct {
+	repo   *repository.Repository
+	logger *logrus.Logger
+}
+
+func NewImportService(repo *repository.Repository, logger *logrus.Logger) *ImportService {
+	return &ImportService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// ImportSubscriptions reads subscriptions from r, validates every row like
+// CreateSubscription does and, unless opts.DryRun is set, inserts the valid
+// ones in a single transaction. Invalid rows are reported, not imported.
+func (s *ImportService) ImportSubscriptions(ctx context.Context, r io.Reader, opts ImportOptions) (model.ImportReport, error) {
+	report := model.ImportReport{DryRun: opts.DryRun, Errors: make([]model.ImportRowError, 0)}
+	var subs []model.Subscription
+	collect := func(row int, fields map[string]string, err error) {
+		report.Total++
+		var sub model.Subscription
+		if err == nil {
+			sub, err = importSubscription(fields)
+		}
+		if err != nil {
+			report.Errors = append(report.Errors, model.ImportRowError{Row: row, Error: err.Error()})
+			return
+		}
+		subs = append(subs, sub)
+	}
+
+	var err error
+	switch opts.Format {
+	case ImportCSV:
+		err = readImportCSV(r, opts.Mapping, collect)
+	case ImportNDJSON:
+		err = readImportNDJSON(r, opts.Mapping, collect)
+	default:
+		err = fmt.Errorf("%w: unknown import format %q", model.ErrInvalidInput, opts.Format)
+	}
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Warnf("Failed to read import: %v", err)
+		return model.ImportReport{}, err
+	}
+	report.Valid = len(subs)
+	if opts.DryRun || len(subs) == 0 {
+		return report, nil
+	}
+
+	events := make([]model.Event, 0, len(subs))
+	for _, sub := range subs {
+		events = append(events, model.NewSubscriptionEvent(model.EventSubscriptionCreated, sub))
+	}
+	var warnings []model.BudgetWarning
+	err = s.repo.WithinTx(ctx, func(repos *repository.Repository) error {
+		now := time.Now().UTC()
+		before, err := importBudgetsConsumption(ctx, repos, subs, now)
+		if err != nil {
+			logging.FromContext(ctx, s.logger).Errorf("Failed to get budget consumption: %v", err)
+			return err
+		}
+		if err := repos.Subscriptions.CreateSubscriptions(ctx, subs); err != nil {
+			logging.FromContext(ctx, s.logger).Errorf("Failed to import subscriptions in repository: %v", err)
+			return err
+		}
+		if err := recordEvents(ctx, repos, model.EventSubscriptionCreated, events); err != nil {
+			logging.FromContext(ctx, s.logger).Errorf("Failed to record subscription events: %v", err)
+			return err
+		}
+		warnings, err = checkImportBudgets(ctx, repos, before, subs, now)
+		if err != nil {
+			logging.FromContext(ctx, s.logger).Errorf("Failed to check budgets: %v", err)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return model.ImportReport{}, err
+	}
+	report.Imported = len(subs)
+	report.Warnings = warnings
+	logging.FromContext(ctx, s.logger).Infof("Imported %d subscriptions, %d rows rejected", report.Imported, len(report.Errors))
+	return report, nil
+}
+
+// importBudgetsConsumption returns the budget consumption of every user of
+// subs before they are written.
+func importBudgetsConsumption(ctx context.Context, repos *repository.Repository, subs []model.Subscription, at time.Time) (map[uuid.UUID][]model.BudgetConsumption, error) {
+	before := make(map[uuid.UUID][]model.BudgetConsumption)
+	for _, sub := range subs {
+		if _, ok := before[sub.UserID]; ok {
+			continue
+		}
+		consumption, err := userBudgetsConsumption(ctx, repos, sub.UserID, at)
+		if err != nil {
+			return nil, err
+		}
+		before[sub.UserID] = consumption
+	}
+	return before, nil
+}
+
+// checkImportBudgets runs the budget check of a single create once per
+// budget the import touched. The budget.exceeded event names the last
+// imported subscription counted by the budget.
+func checkImportBudgets(ctx context.Context, repos *repository.Repository, before map[uuid.UUID][]model.BudgetConsumption, subs []model.Subscription, at time.Time) ([]model.BudgetWarning, error) {
+	var warnings []model.BudgetWarning
+	checked := make(map[uuid.UUID]bool)
+	for _, sub := range subs {
+		if checked[sub.UserID] {
+			continue
+		}
+		checked[sub.UserID] = true
+		for _, consumption := range before[sub.UserID] {
+			last, ok := lastCountedBy(subs, consumption.Budget)
+			if !ok {
+				continue
+			}
+			exceeded, err := checkBudgets(ctx, repos, []model.BudgetConsumption{consumption}, last, at)
+			if err != nil {
+				return nil, err
+			}
+			warnings = append(warnings, exceeded...)
+		}
+	}
+	return warnings, nil
+}
+
+func lastCountedBy(subs []model.Subscription, budget model.Budget) (model.Subscription, bool) {
+	for i := len(subs) - 1; i >= 0; i-- {
+		if subs[i].UserID == budget.UserID && (budget.ServiceName == "" || subs[i].ServiceName == budget.ServiceName) {
+			return subs[i], true
+		}
+	}
+	return model.Subscription{}, false
+}
+
+func importColumn(mapping map[string]string, field string) string {
+	if column, ok := mapping[field]; ok {
+		return column
+	}
+	return field
+}
+
+func readImportCSV(r io.Reader, mapping map[string]string, collect func(row int, fields map[string]string, err error)) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("%w: empty CSV, expected a header row", model.ErrInvalidInput)
+		}
+		return fmt.Errorf("%w: invalid CSV header: %v", model.ErrInvalidInput, err)
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+
+	index := make(map[string]int)
+	for _, field := range importFields {
+		column := importColumn(mapping, field)
+		if i := slices.Index(header, column); i >= 0 {
+			index[field] = i
+		} else if field != "end_date" {
+			return fmt.Errorf("%w: CSV has no %q column for %s", model.ErrInvalidInput, column, field)
+		}
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				collect(parseErr.StartLine, nil, parseErr.Err)
+				continue
+			}
+			return err
+		}
+		line, _ := reader.FieldPos(0)
+		fields := make(map[string]string, len(index))
+		for field, i := range index {
+			if i < len(record) {
+				fields[field] = strings.TrimSpace(record[i])
+			}
+		}
+		collect(line, fields, nil)
+	}
+}
+
+func readImportNDJSON(r io.Reader, mapping map[string]string, collect func(row int, fields map[string]string, err error)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64<<10), importMaxLine)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var object map[string]interface{}
+		if err := json.Unmarshal([]byte(text), &object); err != nil {
+			collect(line, nil, fmt.Errorf("invalid JSON: %w", err))
+			continue
+		}
+		fields := make(map[string]string, len(importFields))
+		for _, field := range importFields {
+			switch value := object[importColumn(mapping, field)].(type) {
+			case string:
+				fields[field] = strings.TrimSpace(value)
+			case float64:
+				fields[field] = strconv.FormatFloat(value, 'f', -1, 64)
+			case nil:
+			default:
+				fields[field] = fmt.Sprint(value)
+			}
+		}
+		collect(line, fields, nil)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%w: failed to read NDJSON: %v", model.ErrInvalidInput, err)
+	}
+	return nil
+}
+
+// importSubscription validates one row with the rules of
+// CreateSubscriptionRequest.
+func importSubscription(fields map[string]string) (model.Subscription, error) {
+	if fields["service_name"] == "" {
+		return model.Subscription{}, errors.New("service_name is required")
+	}
+	price, err := strconv.Atoi(fields["price"])
+	if err != nil || price < 1 {
+		return model.Subscription{}, fmt.Errorf("price must be a positive integer, got %q", fields["price"])
+	}
+	userID, err := uuid.Parse(fields["user_id"])
+	if err != nil || userID == uuid.Nil {
+		return model.Subscription{}, fmt.Errorf("user_id must be a UUID, got %q", fields["user_id"])
+	}
+	if fields["start_date"] == "" {
+		return model.Subscription{}, errors.New("start_date is required")
+	}
+	sub, err := newSubscription(model.CreateSubscriptionRequest{
+		ServiceName: fields["service_name"],
+		Price:       price,
+		UserID:      userID,
+		StartDate:   fields["start_date"],
+		EndDate:     fields["end_date"],
+	})
+	if err != nil {
+		return model.Subscription{}, err
+	}
+	return sub, nil
+}
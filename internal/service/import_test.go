@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/lavatee/subs/internal/model"
+	"github.com/sirupsen/logrus"
+)
+
+func newTestImportService() *ImportService {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return NewImportService(nil, logger)
+}
+
+func TestImportCSVDryRun(t *testing.T) {
+	input := strings.Join([]string{
+		"Service,Cost,user_id,start_date,end_date",
+		"Netflix,400,60601fee-2bf1-4721-ae6f-7636e79a0cba,07-2025,",
+		"Spotify,abc,60601fee-2bf1-4721-ae6f-7636e79a0cba,07-2025,",
+		"Yandex Plus,300,60601fee-2bf1-4721-ae6f-7636e79a0cba,2025-07,",
+		"Kinopoisk,299,not-a-uuid,07-2025,12-2025",
+		"Okko,199,60601fee-2bf1-4721-ae6f-7636e79a0cba,01-2025,12-2025",
+	}, "\n")
+	mapping, err := ParseImportMapping("service_name=Service, price=Cost")
+	if err != nil {
+		t.Fatalf("ParseImportMapping: %v", err)
+	}
+
+	report, err := newTestImportService().ImportSubscriptions(context.Background(), strings.NewReader(input), ImportOptions{
+		Format:  ImportCSV,
+		DryRun:  true,
+		Mapping: mapping,
+	})
+	if err != nil {
+		t.Fatalf("ImportSubscriptions: %v", err)
+	}
+	if report.Total != 5 || report.Valid != 2 || report.Imported != 0 || !report.DryRun {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	var rows []int
+	for _, rowErr := range report.Errors {
+		rows = append(rows, rowErr.Row)
+	}
+	if len(rows) != 3 || rows[0] != 3 || rows[1] != 4 || rows[2] != 5 {
+		t.Fatalf("want errors on lines 3, 4 and 5, got %+v", report.Errors)
+	}
+}
+
+func TestImportNDJSONDryRun(t *testing.T) {
+	input := `{"name":"Netflix","price":400,"user_id":"60601fee-2bf1-4721-ae6f-7636e79a0cba","start_date":"07-2025"}
+
+{"name":"Spotify","price":0,"user_id":"60601fee-2bf1-4721-ae6f-7636e79a0cba","start_date":"07-2025"}
+{not json}
+`
+	report, err := newTestImportService().ImportSubscriptions(context.Background(), strings.NewReader(input), ImportOptions{
+		Format:  ImportNDJSON,
+		DryRun:  true,
+		Mapping: map[string]string{"service_name": "name"},
+	})
+	if err != nil {
+		t.Fatalf("ImportSubscriptions: %v", err)
+	}
+	if report.Total != 3 || report.Valid != 1 || len(report.Errors) != 2 || report.Errors[0].Row != 3 || report.Errors[1].Row != 4 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+}
+
+func TestImportRejectsBadInput(t *testing.T) {
+	s := newTestImportService()
+	tests := []struct {
+		name  string
+		input string
+		opts  ImportOptions
+	}{
+		{name: "unknown format", input: "", opts: ImportOptions{Format: "xml"}},
+		{name: "empty csv", input: "", opts: ImportOptions{Format: ImportCSV}},
+		{name: "missing column", input: "service_name,price\nNetflix,400\n", opts: ImportOptions{Format: ImportCSV}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.ImportSubscriptions(context.Background(), strings.NewReader(tt.input), tt.opts)
+			if !errors.Is(err, model.ErrInvalidInput) {
+				t.Fatalf("want ErrInvalidInput, got %v", err)
+			}
+		})
+	}
+
+	for _, mapping := range []string{"price", "color=Colour", "price="} {
+		if _, err := ParseImportMapping(mapping); !errors.Is(err, model.ErrInvalidInput) {
+			t.Errorf("ParseImportMapping(%q): want ErrInvalidInput, got %v", mapping, err)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/lavatee/subs/internal/metrics"
+	"github.com/lavatee/subs/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+type MetricsService struct {
+	repo   *repository.Repository
+	logger *logrus.Logger
+}
+
+func NewMetricsService(repo *repository.Repository, logger *logrus.Logger) *MetricsService {
+	return &MetricsService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// RefreshMetrics sets the business gauges from the subscriptions active in
+// the current month.
+func (s *MetricsService) RefreshMetrics(ctx context.Context) error {
+	totals, err := s.repo.Analytics.GetActiveTotals(ctx, monthStart(time.Now().UTC()))
+	if err != nil {
+		return err
+	}
+	metrics.ActiveSubscriptions.Set(float64(totals.Subscriptions))
+	metrics.ActiveUsers.Set(float64(totals.Users))
+	metrics.MonthlyRecurringSpend.Set(float64(totals.MonthlySpend))
+	return nil
+}
+
+// RunCollector refreshes the business gauges right away and then every
+// interval until ctx is cancelled. They are computed with a query each, so
+// they are not refreshed on scrape.
+func (s *MetricsService) RunCollector(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.RefreshMetrics(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Errorf("Failed to refresh business metrics: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/logging"
+	"github.com/lavatee/subs/internal/model"
+	"github.com/lavatee/subs/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+type MonthlySpendService struct {
+	repo   *repository.Repository
+	logger *logrus.Logger
+}
+
+func NewMonthlySpendService(repo *repository.Repository, logger *logrus.Logger) *MonthlySpendService {
+	return &MonthlySpendService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+func (s *MonthlySpendService) GetMonthlySpend(ctx context.Context, userID uuid.UUID, serviceName string, startDate, endDate time.Time) ([]model.MonthlySpend, error) {
+	spend, err := s.repo.MonthlySpend.GetMonthlySpend(ctx, userID, serviceName, startDate, endDate)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to get monthly spend from repository: %v", err)
+		return nil, err
+	}
+
+	return spend, nil
+}
+
+// RunRebuilder recomputes the monthly spend ledger every interval until ctx
+// is cancelled. The ledger is maintained incrementally by the database, so
+// this only repairs drift, e.g. after manual edits with triggers disabled.
+func (s *MonthlySpendService) RunRebuilder(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.repo.MonthlySpend.RebuildMonthlySpend(ctx); err != nil {
+				logging.FromContext(ctx, s.logger).Errorf("Failed to rebuild monthly spend: %v", err)
+				continue
+			}
+			logging.FromContext(ctx, s.logger).Infof("Monthly spend rebuilt")
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lavatee/subs/internal/model"
+)
+
+const notifierTimeout = 10 * time.Second
+
+// Notifier delivers reminders through one channel.
+type Notifier interface {
+	// Channel names the notifier; reminders are deduplicated per channel.
+	Channel() string
+	// Address returns where contact receives reminders through this
+	// channel, or "" when it doesn't.
+	Address(contact model.UserContact) string
+	Notify(ctx context.Context, address string, reminder model.Reminder) error
+}
+
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// SMTPNotifier sends reminders as plain text emails.
+type SMTPNotifier struct {
+	config SMTPConfig
+}
+
+func NewSMTPNotifier(config SMTPConfig) *SMTPNotifier {
+	return &SMTPNotifier{
+		config: config,
+	}
+}
+
+func (n *SMTPNotifier) Channel() string {
+	return "email"
+}
+
+func (n *SMTPNotifier) Address(contact model.UserContact) string {
+	return contact.Email
+}
+
+// Notify sends the email like smtp.SendMail, but gives up after
+// notifierTimeout or once ctx is done, so that a stuck server cannot block
+// the scheduler.
+func (n *SMTPNotifier) Notify(ctx context.Context, address string, reminder model.Reminder) error {
+	addr := net.JoinHostPort(n.config.Host, strconv.Itoa(n.config.Port))
+	dialer := &net.Dialer{Timeout: notifierTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	deadline := time.Now().Add(notifierTimeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	conn.SetDeadline(deadline)
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, n.config.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: n.config.Host}); err != nil {
+			return err
+		}
+	}
+	if n.config.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", n.config.Username, n.config.Password, n.config.Host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(n.config.From); err != nil {
+		return err
+	}
+	if err := client.Rcpt(address); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(reminderEmail(n.config.From, address, reminder)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func reminderEmail(from, to string, reminder model.Reminder) []byte {
+	sub := reminder.Subscription
+	var subject, text string
+	switch reminder.Kind {
+	case model.ReminderExpiry:
+		subject = fmt.Sprintf("Your %s subscription ends soon", sub.ServiceName)
+		text = fmt.Sprintf("Your %s subscription ends on %s.", sub.ServiceName, reminder.DueDate.Format(time.DateOnly))
+	default:
+		subject = fmt.Sprintf("Your %s subscription renews soon", sub.ServiceName)
+		text = fmt.Sprintf("Your %s subscription renews on %s for %d. Cancel it before then if you no longer need it.",
+			sub.ServiceName, reminder.DueDate.Format(time.DateOnly), sub.Price)
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	// Service names come from users; encoding the subject keeps line
+	// breaks in them from starting new headers.
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().UTC().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(text + "\r\n")
+	return []byte(msg.String())
+}
+
+// WebhookNotifier posts reminders as JSON to the user's webhook URL. With a
+// secret, requests carry the same signature header as subscription webhooks.
+// Unless allowPrivate is set, it only connects to public addresses.
+type WebhookNotifier struct {
+	secret string
+	client *http.Client
+}
+
+func NewWebhookNotifier(secret string, allowPrivate bool) *WebhookNotifier {
+	client := &http.Client{Timeout: notifierTimeout}
+	if !allowPrivate {
+		client.Transport = publicOnlyTransport()
+	}
+	return &WebhookNotifier{
+		secret: secret,
+		client: client,
+	}
+}
+
+func (n *WebhookNotifier) Channel() string {
+	return "webhook"
+}
+
+func (n *WebhookNotifier) Address(contact model.UserContact) string {
+	return contact.WebhookURL
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, address string, reminder model.Reminder) error {
+	payload, err := json.Marshal(reminder)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, address, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, "reminder."+reminder.Kind)
+	if n.secret != "" {
+		req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(n.secret, time.Now(), payload))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/logging"
+	"github.com/lavatee/subs/internal/model"
+	"github.com/lavatee/subs/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	outboxBatchSize = 100
+	// outboxLease is how long a dispatcher keeps other instances out after
+	// renewing its lease. A batch stops publishing after half of it, so the
+	// lease cannot run out while events are in flight.
+	outboxLease = time.Minute
+)
+
+type OutboxService struct {
+	repo   *repository.Repository
+	logger *logrus.Logger
+	// id identifies this instance as the holder of the dispatcher lease.
+	id uuid.UUID
+}
+
+func NewOutboxService(repo *repository.Repository, logger *logrus.Logger) *OutboxService {
+	return &OutboxService{
+		repo:   repo,
+		logger: logger,
+		id:     uuid.New(),
+	}
+}
+
+// RunDispatcher delivers outbox events to sinks every interval until ctx is
+// cancelled, and drops dispatched events older than retention. Only the
+// instance holding the dispatcher lease dispatches. An event is marked
+// dispatched once every sink has accepted it; when a sink fails, later
+// events of the same subscription wait for the next round, so each
+// subscription's events arrive in order, at least once.
+func (s *OutboxService) RunDispatcher(ctx context.Context, interval, retention time.Duration, sinks []EventSink) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	defer s.releaseLease(ctx)
+	lastTrim := time.Time{}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Batches follow each other while they make progress; a batch
+			// that dispatches nothing, e.g. because a sink is down, waits
+			// for the next tick.
+			for ctx.Err() == nil {
+				dispatched, err := s.dispatchBatch(ctx, sinks)
+				if err != nil {
+					logging.FromContext(ctx, s.logger).Errorf("Failed to dispatch outbox events: %v", err)
+					break
+				}
+				if dispatched == 0 {
+					break
+				}
+			}
+			if retention > 0 && time.Since(lastTrim) > time.Hour {
+				lastTrim = time.Now()
+				s.trim(ctx, retention)
+			}
+		}
+	}
+}
+
+// dispatchBatch sends one batch and returns how many events were
+// dispatched. Events are read and marked in statements of their own, so no
+// transaction stays open while sinks are called.
+func (s *OutboxService) dispatchBatch(ctx context.Context, sinks []EventSink) (int, error) {
+	leased, err := s.repo.Outbox.AcquireDispatcherLease(ctx, s.id, outboxLease)
+	if err != nil || !leased {
+		return 0, err
+	}
+
+	events, err := s.repo.Outbox.GetPendingEvents(ctx, outboxBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	publishCtx, cancel := context.WithTimeout(ctx, outboxLease/2)
+	defer cancel()
+	blocked := make(map[string]bool)
+	dispatched := make([]int64, 0, len(events))
+	for _, event := range events {
+		if publishCtx.Err() != nil {
+			break
+		}
+		aggregate := event.AggregateID.String()
+		if blocked[aggregate] {
+			continue
+		}
+		if err := publishToSinks(publishCtx, sinks, event); err != nil {
+			logging.FromContext(ctx, s.logger).Warnf("Failed to dispatch outbox event %d (%s): %v", event.Sequence, event.Type, err)
+			blocked[aggregate] = true
+			continue
+		}
+		dispatched = append(dispatched, event.Sequence)
+	}
+	if err := s.repo.Outbox.MarkDispatched(ctx, dispatched); err != nil {
+		return 0, err
+	}
+	return len(dispatched), nil
+}
+
+// releaseLease lets another instance take over at once instead of waiting
+// for the lease to expire.
+func (s *OutboxService) releaseLease(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if err := s.repo.Outbox.ReleaseDispatcherLease(ctx, s.id); err != nil {
+		logging.FromContext(ctx, s.logger).Warnf("Failed to release outbox dispatcher lease: %v", err)
+	}
+}
+
+func publishToSinks(ctx context.Context, sinks []EventSink, event model.OutboxEvent) error {
+	for _, sink := range sinks {
+		if err := sink.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *OutboxService) trim(ctx context.Context, retention time.Duration) {
+	deleted, err := s.repo.Outbox.DeleteDispatchedBefore(ctx, time.Now().UTC().Add(-retention))
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to trim outbox: %v", err)
+		return
+	}
+	if deleted > 0 {
+		logging.FromContext(ctx, s.logger).Infof("Trimmed %d dispatched outbox events", deleted)
+	}
+}
+
+// recordEvent stores a subscription change in the outbox and schedules the
+// matching webhooks. repos must be transactional so that the event commits
+// or rolls back together with the change itself.
+func recordEvent(ctx context.Context, repos *repository.Repository, event model.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if err := repos.Outbox.AddEvent(ctx, event.ID, event.Subscription.ID, event.Type, payload); err != nil {
+		return err
+	}
+	_, err = repos.Webhooks.EnqueueDeliveries(ctx, event.Type, payload, "")
+	return err
+}
+
+// recordEvents is recordEvent for a batch of events of one type, written
+// with bulk statements.
+func recordEvents(ctx context.Context, repos *repository.Repository, eventType string, events []model.Event) error {
+	outboxEvents := make([]model.OutboxEvent, 0, len(events))
+	payloads := make([][]byte, 0, len(events))
+	for _, event := range events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		outboxEvents = append(outboxEvents, model.OutboxEvent{
+			EventID:     event.ID,
+			AggregateID: event.Subscription.ID,
+			Type:        eventType,
+			Payload:     payload,
+		})
+		payloads = append(payloads, payload)
+	}
+	if err := repos.Outbox.AddEvents(ctx, outboxEvents); err != nil {
+		return err
+	}
+	_, err := repos.Webhooks.EnqueueBatch(ctx, eventType, payloads)
+	return err
+}
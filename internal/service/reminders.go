@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/logging"
+	"github.com/lavatee/subs/internal/model"
+	"github.com/lavatee/subs/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+type RemindersService struct {
+	repo   *repository.Repository
+	logger *logrus.Logger
+	// allowPrivate lets contact webhook URLs point to loopback and private
+	// addresses, as for subscription webhooks.
+	allowPrivate bool
+}
+
+func NewRemindersService(repo *repository.Repository, logger *logrus.Logger, allowPrivate bool) *RemindersService {
+	return &RemindersService{
+		repo:         repo,
+		logger:       logger,
+		allowPrivate: allowPrivate,
+	}
+}
+
+func (s *RemindersService) GetContact(ctx context.Context, userID uuid.UUID) (model.UserContact, error) {
+	contact, err := s.repo.Reminders.GetContact(ctx, userID)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to get user contact from repository: %v", err)
+		return model.UserContact{}, err
+	}
+	return contact, nil
+}
+
+func (s *RemindersService) UpdateContact(ctx context.Context, userID uuid.UUID, req model.UpdateUserContactRequest) (model.UserContact, error) {
+	if req.WebhookURL != "" {
+		if err := checkWebhookURL(req.WebhookURL, s.allowPrivate); err != nil {
+			return model.UserContact{}, err
+		}
+	}
+	contact := model.UserContact{
+		UserID:     userID,
+		Email:      req.Email,
+		WebhookURL: req.WebhookURL,
+		UpdatedAt:  time.Now().UTC(),
+	}
+	if err := s.repo.Reminders.UpsertContact(ctx, contact); err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to update user contact in repository: %v", err)
+		return model.UserContact{}, err
+	}
+	return contact, nil
+}
+
+// RunScheduler sends renewal and expiry reminders every interval until ctx is
+// cancelled. A subscription renewing or ending within leadDays days gets one
+// reminder per lead time, through every notifier its user has an address
+// for; when the first scan after it is created already falls within a
+// shorter lead time, the longer ones are skipped.
+func (s *RemindersService) RunScheduler(ctx context.Context, interval time.Duration, leadDays []int, notifiers []Notifier) {
+	leadDays = slices.Clone(leadDays)
+	slices.Sort(leadDays)
+	if len(leadDays) == 0 || len(notifiers) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		s.sendDue(ctx, time.Now().UTC(), leadDays, notifiers)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *RemindersService) sendDue(ctx context.Context, now time.Time, leadDays []int, notifiers []Notifier) {
+	reminders, err := s.dueReminders(ctx, now, leadDays)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to get due reminders: %v", err)
+		return
+	}
+
+	contacts := make(map[uuid.UUID]*model.UserContact)
+	for _, reminder := range reminders {
+		userID := reminder.Subscription.UserID
+		contact, ok := contacts[userID]
+		if !ok {
+			found, err := s.repo.Reminders.GetContact(ctx, userID)
+			switch {
+			case err == nil:
+				contact = &found
+			case !errors.Is(err, model.ErrNotFound):
+				logging.FromContext(ctx, s.logger).Errorf("Failed to get contact of user %s: %v", userID, err)
+				continue
+			}
+			contacts[userID] = contact
+		}
+		if contact == nil {
+			continue
+		}
+		for _, notifier := range notifiers {
+			s.notify(ctx, notifier, *contact, reminder)
+		}
+	}
+}
+
+// dueReminders lists the reminders whose lead time has come at now.
+func (s *RemindersService) dueReminders(ctx context.Context, now time.Time, leadDays []int) ([]model.Reminder, error) {
+	horizon := now.AddDate(0, 0, leadDays[len(leadDays)-1])
+	var reminders []model.Reminder
+	add := func(kind string, due time.Time, sub model.Subscription) {
+		if lead := reminderLead(leadDays, due.Sub(now)); lead > 0 {
+			reminders = append(reminders, model.Reminder{Kind: kind, DueDate: due, LeadDays: lead, Subscription: sub})
+		}
+	}
+
+	// The end date is the last paid month; access ends when the next one
+	// begins. The window takes in the day after horizon so that an end
+	// falling right on it is found; add drops those beyond the leads.
+	ending, err := endingBetween(ctx, s.repo, now, horizon.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	for _, sub := range ending {
+		add(model.ReminderExpiry, sub.EndDate.AddDate(0, 1, 0), sub)
+	}
+
+	for renewal := nextMonth(now); !renewal.After(horizon); renewal = renewal.AddDate(0, 1, 0) {
+		renewing, err := s.repo.Subscriptions.GetRenewingOn(ctx, renewal)
+		if err != nil {
+			return nil, err
+		}
+		for _, sub := range renewing {
+			add(model.ReminderRenewal, renewal, sub)
+		}
+	}
+	return reminders, nil
+}
+
+func (s *RemindersService) notify(ctx context.Context, notifier Notifier, contact model.UserContact, reminder model.Reminder) {
+	address := notifier.Address(contact)
+	if address == "" {
+		return
+	}
+	channel := notifier.Channel()
+	claimed, err := s.repo.Reminders.ClaimReminder(ctx, reminder, channel)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to claim %s reminder for subscription %s: %v", reminder.Kind, reminder.Subscription.ID, err)
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	if err := notifier.Notify(ctx, address, reminder); err != nil {
+		logging.FromContext(ctx, s.logger).Warnf("Failed to send %s reminder for subscription %s by %s: %v", reminder.Kind, reminder.Subscription.ID, channel, err)
+		if err := s.repo.Reminders.ReleaseReminder(ctx, reminder, channel); err != nil {
+			logging.FromContext(ctx, s.logger).Errorf("Failed to release %s reminder for subscription %s: %v", reminder.Kind, reminder.Subscription.ID, err)
+		}
+		return
+	}
+	logging.FromContext(ctx, s.logger).Infof("Sent %s reminder for subscription %s by %s, %d days ahead", reminder.Kind, reminder.Subscription.ID, channel, reminder.LeadDays)
+}
+
+// reminderLead returns the shortest of the ascending leadDays that until
+// falls within, or 0 when until is not positive or exceeds them all.
+func reminderLead(leadDays []int, until time.Duration) int {
+	if until <= 0 {
+		return 0
+	}
+	for _, lead := range leadDays {
+		if until <= time.Duration(lead)*24*time.Hour {
+			return lead
+		}
+	}
+	return 0
+}
+
+// nextMonth returns the first day of the month after t.
+func nextMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/model"
+	"github.com/lavatee/subs/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+func TestReminderLead(t *testing.T) {
+	leads := []int{1, 3, 7}
+	day := 24 * time.Hour
+	tests := []struct {
+		until time.Duration
+		want  int
+	}{
+		{until: 8 * day, want: 0},
+		{until: 7 * day, want: 7},
+		{until: 5 * day, want: 7},
+		{until: 3 * day, want: 3},
+		{until: 2 * day, want: 3},
+		{until: time.Hour, want: 1},
+		{until: 0, want: 0},
+		{until: -time.Hour, want: 0},
+	}
+	for _, tt := range tests {
+		if got := reminderLead(leads, tt.until); got != tt.want {
+			t.Errorf("reminderLead(%s): want %d, got %d", tt.until, tt.want, got)
+		}
+	}
+}
+
+// fakeSubscriptionsRepo serves the reminder queries from subs.
+type fakeSubscriptionsRepo struct {
+	repository.Subscriptions
+	subs []model.Subscription
+}
+
+func (f *fakeSubscriptionsRepo) GetEndingBetween(ctx context.Context, from, to time.Time) ([]model.Subscription, error) {
+	var subs []model.Subscription
+	for _, sub := range f.subs {
+		if sub.EndDate != nil && !sub.EndDate.Before(from) && sub.EndDate.Before(to) {
+			subs = append(subs, sub)
+		}
+	}
+	return subs, nil
+}
+
+func (f *fakeSubscriptionsRepo) GetRenewingOn(ctx context.Context, renewal time.Time) ([]model.Subscription, error) {
+	return nil, nil
+}
+
+func TestDueRemindersExpiryAfterLastPaidMonth(t *testing.T) {
+	march := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+	april := march.AddDate(0, 1, 0)
+	endsInApril := model.Subscription{ID: uuid.New(), ServiceName: "Netflix", StartDate: march.AddDate(0, -6, 0), EndDate: &march}
+	endsInMay := model.Subscription{ID: uuid.New(), ServiceName: "Spotify", StartDate: march.AddDate(0, -6, 0), EndDate: &april}
+	repo := &repository.Repository{Subscriptions: &fakeSubscriptionsRepo{subs: []model.Subscription{endsInApril, endsInMay}}}
+	s := NewRemindersService(repo, logrus.New(), false)
+
+	now := time.Date(2025, time.March, 25, 0, 0, 0, 0, time.UTC)
+	reminders, err := s.dueReminders(context.Background(), now, []int{1, 3, 7})
+	if err != nil {
+		t.Fatalf("dueReminders: %v", err)
+	}
+	if len(reminders) != 1 {
+		t.Fatalf("want one reminder, got %+v", reminders)
+	}
+	got := reminders[0]
+	if got.Kind != model.ReminderExpiry || got.Subscription.ID != endsInApril.ID || !got.DueDate.Equal(april) || got.LeadDays != 7 {
+		t.Fatalf("got %s reminder for %s due %s, %d days ahead", got.Kind, got.Subscription.ServiceName, got.DueDate.Format(time.DateOnly), got.LeadDays)
+	}
+}
+
+func testReminder() model.Reminder {
+	return model.Reminder{
+		Kind:     model.ReminderRenewal,
+		DueDate:  time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC),
+		LeadDays: 3,
+		Subscription: model.Subscription{
+			ID:          uuid.New(),
+			ServiceName: "Netflix",
+			Price:       400,
+			UserID:      uuid.New(),
+		},
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	reminder := testReminder()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var got model.Reminder
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("body is not a reminder: %v", err)
+		}
+		if got.Subscription.ID != reminder.Subscription.ID || got.LeadDays != 3 {
+			t.Errorf("got reminder %+v", got)
+		}
+		if r.Header.Get(WebhookEventHeader) != "reminder.renewal" || !strings.HasPrefix(r.Header.Get(WebhookSignatureHeader), "t=") {
+			t.Errorf("unexpected headers %v", r.Header)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier("secret", true)
+	if got := notifier.Address(model.UserContact{WebhookURL: server.URL}); got != server.URL {
+		t.Fatalf("Address: got %q", got)
+	}
+	if err := notifier.Notify(context.Background(), server.URL, reminder); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+}
+
+func TestWebhookNotifierRefusesPrivateAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request reached a loopback server")
+	}))
+	defer server.Close()
+
+	if err := NewWebhookNotifier("", false).Notify(context.Background(), server.URL, testReminder()); err == nil {
+		t.Fatal("Notify: want error, got nil")
+	}
+}
+
+func TestUpdateContactChecksWebhookURL(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	s := NewRemindersService(&repository.Repository{}, logger, false)
+	for _, url := range []string{"http://169.254.169.254/latest/meta-data", "http://localhost:8080/hook", "ftp://example.com/hook"} {
+		_, err := s.UpdateContact(context.Background(), uuid.New(), model.UpdateUserContactRequest{WebhookURL: url})
+		if !errors.Is(err, model.ErrInvalidInput) {
+			t.Errorf("UpdateContact(%s): want ErrInvalidInput, got %v", url, err)
+		}
+	}
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	defer listener.Close()
+
+	received := make(chan string, 1)
+	go serveSMTP(listener, received)
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	var portNumber int
+	fmt.Sscan(port, &portNumber)
+	notifier := NewSMTPNotifier(SMTPConfig{Host: host, Port: portNumber, From: "reminders@example.com"})
+	if err := notifier.Notify(context.Background(), "user@example.com", testReminder()); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	message := <-received
+	for _, want := range []string{"To: user@example.com", "Subject: Your Netflix subscription renews soon", "renews on 2025-03-01 for 400"} {
+		if !strings.Contains(message, want) {
+			t.Errorf("message lacks %q:\n%s", want, message)
+		}
+	}
+}
+
+func TestReminderEmailEncodesSubject(t *testing.T) {
+	reminder := testReminder()
+	reminder.Subscription.ServiceName = "Netflix\r\nBcc: victim@example.com"
+	message := string(reminderEmail("reminders@example.com", "user@example.com", reminder))
+	headers, _, _ := strings.Cut(message, "\r\n\r\n")
+	for _, line := range strings.Split(headers, "\r\n") {
+		if strings.HasPrefix(line, "Bcc:") {
+			t.Fatalf("service name injected a header:\n%s", headers)
+		}
+	}
+}
+
+func TestSMTPNotifierHonoursContext(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	defer listener.Close()
+	// Accept the connection but never greet the client.
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			defer conn.Close()
+			io.Copy(io.Discard, conn)
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	var portNumber int
+	fmt.Sscan(port, &portNumber)
+	notifier := NewSMTPNotifier(SMTPConfig{Host: host, Port: portNumber, From: "reminders@example.com"})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := notifier.Notify(ctx, "user@example.com", testReminder()); err == nil {
+		t.Fatal("Notify: want error, got nil")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Notify took %s after its context expired", elapsed)
+	}
+}
+
+// serveSMTP accepts one connection and speaks just enough SMTP for
+// smtp.SendMail, sending the message data to received.
+func serveSMTP(listener net.Listener, received chan<- string) {
+	conn, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { fmt.Fprintf(conn, "%s\r\n", line) }
+
+	reply("220 localhost ESMTP")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		switch command := strings.ToUpper(strings.TrimSpace(line)); {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(command, "DATA"):
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(line)
+			}
+			received <- data.String()
+			reply("250 queued")
+		case strings.HasPrefix(command, "QUIT"):
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"io"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,20 +12,168 @@ import (
 )
 
 type Subscriptions interface {
-	CreateSubscription(ctx context.Context, request model.CreateSubscriptionRequest) (model.Subscription, error)
-	GetUserSubscriptions(ctx context.Context, userID uuid.UUID, serviceName string) ([]model.Subscription, error)
+	CreateSubscription(ctx context.Context, request model.CreateSubscriptionRequest) (model.Subscription, []model.BudgetWarning, error)
+	GetUserSubscriptions(ctx context.Context, userID uuid.UUID, serviceName string, includeArchived bool) ([]model.Subscription, error)
 	GetSubscription(ctx context.Context, id uuid.UUID) (model.Subscription, error)
-	UpdateSubscription(ctx context.Context, id uuid.UUID, request model.UpdateSubscriptionRequest) (model.Subscription, error)
+	UpdateSubscription(ctx context.Context, id uuid.UUID, request model.UpdateSubscriptionRequest) (model.Subscription, []model.BudgetWarning, error)
 	DeleteSubscription(ctx context.Context, id uuid.UUID) error
-	GetTotalCost(ctx context.Context, userID uuid.UUID, serviceName string, startDate, endDate time.Time) (int, error)
+	GetTotalCost(ctx context.Context, userID uuid.UUID, serviceName string, startDate, endDate time.Time, includeArchived bool) (int, error)
+	ExplainTotalCost(ctx context.Context, userID uuid.UUID, serviceName string, startDate, endDate time.Time, includeArchived bool) (int, model.TotalCostExplanation, error)
+}
+
+type MonthlySpend interface {
+	GetMonthlySpend(ctx context.Context, userID uuid.UUID, serviceName string, startDate, endDate time.Time) ([]model.MonthlySpend, error)
+	RunRebuilder(ctx context.Context, interval time.Duration)
+}
+
+type Archive interface {
+	ArchiveEnded(ctx context.Context, horizon time.Duration) (int, error)
+	RunArchiver(ctx context.Context, interval, horizon time.Duration)
+}
+
+type Webhooks interface {
+	CreateWebhook(ctx context.Context, req model.CreateWebhookRequest) (model.Webhook, error)
+	GetWebhooks(ctx context.Context) ([]model.Webhook, error)
+	GetWebhook(ctx context.Context, id uuid.UUID) (model.Webhook, error)
+	DeleteWebhook(ctx context.Context, id uuid.UUID) error
+	GetDeliveries(ctx context.Context, webhookID uuid.UUID) ([]model.WebhookDelivery, error)
+	Redeliver(ctx context.Context, webhookID, deliveryID uuid.UUID) (model.WebhookDelivery, error)
+	RunDispatcher(ctx context.Context, interval time.Duration)
+	RunLifecycleScanner(ctx context.Context, interval, lead time.Duration)
+}
+
+type Outbox interface {
+	RunDispatcher(ctx context.Context, interval, retention time.Duration, sinks []EventSink)
+}
+
+type Reminders interface {
+	GetContact(ctx context.Context, userID uuid.UUID) (model.UserContact, error)
+	UpdateContact(ctx context.Context, userID uuid.UUID, req model.UpdateUserContactRequest) (model.UserContact, error)
+	RunScheduler(ctx context.Context, interval time.Duration, leadDays []int, notifiers []Notifier)
+}
+
+type Budgets interface {
+	CreateBudget(ctx context.Context, req model.CreateBudgetRequest) (model.Budget, error)
+	GetBudgets(ctx context.Context, userID uuid.UUID) ([]model.Budget, error)
+	GetBudget(ctx context.Context, id uuid.UUID) (model.Budget, error)
+	UpdateBudget(ctx context.Context, id uuid.UUID, req model.UpdateBudgetRequest) (model.Budget, error)
+	DeleteBudget(ctx context.Context, id uuid.UUID) error
+	GetConsumption(ctx context.Context, id uuid.UUID, at time.Time) (model.BudgetConsumption, error)
+}
+
+type Events interface {
+	Subscribe(ctx context.Context, userID uuid.UUID, after *model.EventPosition) (*EventSubscription, error)
+	RunHub(ctx context.Context, interval time.Duration, bufferSize int)
+}
+
+type Changes interface {
+	GetChanges(ctx context.Context, userID uuid.UUID, since string) (model.SubscriptionChangesResponse, error)
+}
+
+type Import interface {
+	ImportSubscriptions(ctx context.Context, r io.Reader, opts ImportOptions) (model.ImportReport, error)
+}
+
+type Export interface {
+	ExportSubscriptions(ctx context.Context, w io.Writer, format string, userID uuid.UUID, serviceName string, includeArchived bool) error
+}
+
+type Statements interface {
+	AnalyzeStatement(ctx context.Context, userID uuid.UUID, r io.Reader, format string) (model.StatementAnalysisResponse, error)
+	GetProposals(ctx context.Context, userID uuid.UUID, status string) ([]model.SubscriptionProposal, error)
+	AcceptProposal(ctx context.Context, id uuid.UUID, req model.AcceptProposalRequest) (model.AcceptProposalResponse, error)
+	DismissProposal(ctx context.Context, id uuid.UUID) (model.SubscriptionProposal, error)
+}
+
+type Calendar interface {
+	IssueToken(ctx context.Context, userID uuid.UUID) (model.CalendarToken, error)
+	RevokeToken(ctx context.Context, userID uuid.UUID) error
+	GetCalendar(ctx context.Context, userID uuid.UUID, token string) ([]byte, error)
+}
+
+type Analytics interface {
+	GetTopServices(ctx context.Context, filter model.AnalyticsFilter, limit int) ([]model.ServiceSpend, error)
+	GetAveragePrices(ctx context.Context, filter model.AnalyticsFilter) ([]model.ServicePrice, error)
+	GetActiveSubscriptions(ctx context.Context, filter model.AnalyticsFilter) ([]model.ActiveSubscriptions, error)
+	GetChurn(ctx context.Context, filter model.AnalyticsFilter) ([]model.SubscriptionChurn, error)
+	GetUserSpendStats(ctx context.Context, filter model.AnalyticsFilter) (model.UserSpendStats, error)
+}
+
+type Comparison interface {
+	CompareSpend(ctx context.Context, req model.CompareSpendRequest) (model.SpendComparison, error)
+}
+
+type Duplicates interface {
+	GetDuplicates(ctx context.Context, userID uuid.UUID) (model.DuplicatesResponse, error)
+	MergeDuplicates(ctx context.Context, userID uuid.UUID, req model.MergeDuplicatesRequest) (model.MergeDuplicatesResponse, error)
+}
+
+type Metrics interface {
+	RefreshMetrics(ctx context.Context) error
+	RunCollector(ctx context.Context, interval time.Duration)
+}
+
+type Health interface {
+	Live() model.HealthResponse
+	Ready(ctx context.Context) (model.HealthResponse, bool)
+	StartShutdown()
+	RunWorker(name string, run func())
+	WaitWorkers(ctx context.Context) error
+}
+
+// Config holds service settings that shape request handling rather than
+// background work.
+type Config struct {
+	ChangesTokenTTL time.Duration
+	// CalendarHorizon is how many months ahead calendar feeds reach.
+	CalendarHorizon int
+	// MigrationVersion is the schema version readiness expects.
+	MigrationVersion uint
+	// WebhooksAllowPrivate lets webhooks and reminder webhooks target
+	// private addresses.
+	WebhooksAllowPrivate bool
 }
 
 type Service struct {
 	Subscriptions
+	MonthlySpend
+	Archive
+	Webhooks
+	Outbox
+	Reminders
+	Budgets
+	Events
+	Changes
+	Import
+	Export
+	Statements
+	Calendar
+	Analytics
+	Comparison
+	Duplicates
+	Metrics
+	Health
 }
 
-func NewService(repo *repository.Repository, logger *logrus.Logger) *Service {
+func NewService(repo *repository.Repository, logger *logrus.Logger, config Config) *Service {
 	return &Service{
-		Subscriptions: NewSubscriptionsService(repo, logger),
+		Subscriptions: newTracedSubscriptions(NewSubscriptionsService(repo, logger), tracer),
+		MonthlySpend:  NewMonthlySpendService(repo, logger),
+		Archive:       NewArchiveService(repo, logger),
+		Webhooks:      NewWebhooksService(repo, logger, config.WebhooksAllowPrivate),
+		Outbox:        NewOutboxService(repo, logger),
+		Reminders:     NewRemindersService(repo, logger, config.WebhooksAllowPrivate),
+		Budgets:       NewBudgetsService(repo, logger),
+		Events:        NewEventsService(repo, logger),
+		Changes:       NewChangesService(repo, logger, config.ChangesTokenTTL),
+		Import:        NewImportService(repo, logger),
+		Export:        NewExportService(repo, logger),
+		Statements:    NewStatementsService(repo, logger),
+		Calendar:      NewCalendarService(repo, logger, config.CalendarHorizon),
+		Analytics:     NewAnalyticsService(repo, logger),
+		Comparison:    NewComparisonService(repo, logger),
+		Duplicates:    NewDuplicatesService(repo, logger),
+		Metrics:       NewMetricsService(repo, logger),
+		Health:        NewHealthService(repo, logger, config.MigrationVersion),
 	}
 }
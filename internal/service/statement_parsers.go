@@ -0,0 +1,285 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lavatee/subs/internal/model"
+)
+
+const (
+	StatementCSV = "csv"
+	StatementQIF = "qif"
+	StatementOFX = "ofx"
+)
+
+// statementColumns lists the CSV header names recognised for each field,
+// lower-cased. Exports of most banks use one of them.
+var statementColumns = map[string][]string{
+	"date":        {"date", "transaction date", "posted date", "booking date", "posting date", "дата", "дата операции", "дата платежа"},
+	"description": {"description", "payee", "merchant", "name", "details", "memo", "описание", "описание операции", "назначение платежа", "контрагент"},
+	"amount":      {"amount", "sum", "сумма", "сумма операции", "сумма платежа"},
+}
+
+var statementDateLayouts = []string{
+	"2006-01-02",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	time.RFC3339,
+	"02.01.2006",
+	"02.01.2006 15:04:05",
+	"02.01.2006 15:04",
+	"01/02/2006",
+	"1/2/2006",
+	"01/02/06",
+	"1/2/06",
+	"01/02'06",
+	"1/2'06",
+	"20060102",
+}
+
+// parseStatement reads the transactions of a bank statement in format.
+// Rows that are not transactions, such as the summary and footer rows many
+// banks add, are skipped and reported.
+func parseStatement(r io.Reader, format string) ([]model.Transaction, []model.StatementRowError, error) {
+	switch format {
+	case StatementCSV:
+		return parseStatementCSV(r)
+	case StatementQIF:
+		return parseStatementQIF(r)
+	case StatementOFX:
+		return parseStatementOFX(r)
+	}
+	return nil, nil, fmt.Errorf("%w: unknown statement format %q, expected csv, qif or ofx", model.ErrInvalidInput, format)
+}
+
+// parseStatementCSV reads a CSV statement with a header row. The delimiter
+// may be a comma or a semicolon.
+func parseStatementCSV(r io.Reader) ([]model.Transaction, []model.StatementRowError, error) {
+	br := bufio.NewReader(r)
+	first, err := br.Peek(4096)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return nil, nil, err
+	}
+	firstLine, _, _ := bytes.Cut(first, []byte("\n"))
+
+	reader := csv.NewReader(br)
+	reader.FieldsPerRecord = -1
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil, fmt.Errorf("%w: statement is empty", model.ErrInvalidInput)
+		}
+		return nil, nil, fmt.Errorf("%w: %v", model.ErrInvalidInput, err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		for field, names := range statementColumns {
+			if _, found := columns[field]; found {
+				continue
+			}
+			for _, known := range names {
+				if name == known {
+					columns[field] = i
+				}
+			}
+		}
+	}
+	for _, field := range []string{"date", "description", "amount"} {
+		if _, ok := columns[field]; !ok {
+			return nil, nil, fmt.Errorf("%w: statement has no %s column", model.ErrInvalidInput, field)
+		}
+	}
+
+	var transactions []model.Transaction
+	skipped := make([]model.StatementRowError, 0)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", model.ErrInvalidInput, err)
+		}
+		line, _ := reader.FieldPos(0)
+		field := func(name string) string {
+			if i := columns[name]; i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		if field("date") == "" && field("amount") == "" {
+			continue
+		}
+		transaction, err := newTransaction(field("date"), field("description"), field("amount"))
+		if err != nil {
+			skipped = append(skipped, model.StatementRowError{Row: line, Error: err.Error()})
+			continue
+		}
+		transactions = append(transactions, transaction)
+	}
+	return transactions, skipped, nil
+}
+
+// parseStatementQIF reads a Quicken Interchange Format statement: one field
+// per line keyed by its first letter, records ended by "^".
+func parseStatementQIF(r io.Reader) ([]model.Transaction, []model.StatementRowError, error) {
+	scanner := bufio.NewScanner(r)
+	var transactions []model.Transaction
+	skipped := make([]model.StatementRowError, 0)
+	var date, payee, memo, amount string
+	line, start := 0, 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "!") {
+			continue
+		}
+		if start == 0 {
+			start = line
+		}
+		value := strings.TrimSpace(text[1:])
+		switch text[0] {
+		case 'D':
+			date = value
+		case 'T', 'U':
+			amount = value
+		case 'P':
+			payee = value
+		case 'M':
+			memo = value
+		case '^':
+			if payee == "" {
+				payee = memo
+			}
+			transaction, err := newTransaction(date, payee, amount)
+			if err != nil {
+				skipped = append(skipped, model.StatementRowError{Row: start, Error: err.Error()})
+			} else {
+				transactions = append(transactions, transaction)
+			}
+			date, payee, memo, amount, start = "", "", "", "", 0
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", model.ErrInvalidInput, err)
+	}
+	return transactions, skipped, nil
+}
+
+var ofxTag = regexp.MustCompile(`<(/?)([A-Za-z0-9.]+)>([^<\r\n]*)`)
+
+// parseStatementOFX reads the STMTTRN records of an OFX statement. Both the
+// SGML flavour, where leaf elements are not closed, and XML are accepted.
+func parseStatementOFX(r io.Reader) ([]model.Transaction, []model.StatementRowError, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var transactions []model.Transaction
+	skipped := make([]model.StatementRowError, 0)
+	var inTransaction bool
+	var fields map[string]string
+	var start int
+	for _, match := range ofxTag.FindAllSubmatchIndex(data, -1) {
+		closing := match[3] > match[2]
+		tag := strings.ToUpper(string(data[match[4]:match[5]]))
+		value := strings.TrimSpace(string(data[match[6]:match[7]]))
+		switch {
+		case tag == "STMTTRN" && !closing:
+			inTransaction, fields = true, make(map[string]string)
+			start = bytes.Count(data[:match[0]], []byte("\n")) + 1
+		case tag == "STMTTRN" && closing && inTransaction:
+			inTransaction = false
+			description := fields["NAME"]
+			if description == "" {
+				description = fields["MEMO"]
+			}
+			// Dates are YYYYMMDD optionally followed by a time and zone.
+			date := fields["DTPOSTED"]
+			if len(date) > 8 {
+				date = date[:8]
+			}
+			transaction, err := newTransaction(date, description, fields["TRNAMT"])
+			if err != nil {
+				skipped = append(skipped, model.StatementRowError{Row: start, Error: err.Error()})
+				continue
+			}
+			transactions = append(transactions, transaction)
+		case inTransaction && !closing && value != "":
+			fields[tag] = value
+		}
+	}
+	if len(transactions) == 0 && len(skipped) == 0 && !bytes.Contains(bytes.ToUpper(data), []byte("<OFX")) {
+		return nil, nil, fmt.Errorf("%w: not an OFX statement", model.ErrInvalidInput)
+	}
+	return transactions, skipped, nil
+}
+
+func newTransaction(date, description, amount string) (model.Transaction, error) {
+	parsedDate, err := parseStatementDate(date)
+	if err != nil {
+		return model.Transaction{}, err
+	}
+	parsedAmount, err := parseStatementAmount(amount)
+	if err != nil {
+		return model.Transaction{}, err
+	}
+	return model.Transaction{
+		Date:        parsedDate,
+		Description: description,
+		Amount:      parsedAmount,
+	}, nil
+}
+
+func parseStatementDate(value string) (time.Time, error) {
+	for _, layout := range statementDateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: unrecognised date %q", model.ErrInvalidInput, value)
+}
+
+// parseStatementAmount accepts amounts such as "-399.00", "-1 299,00",
+// "1,299.00" and "(399.00)". A trailing currency is ignored.
+func parseStatementAmount(value string) (float64, error) {
+	cleaned := strings.Map(func(r rune) rune {
+		switch {
+		case r >= '0' && r <= '9', r == '-', r == '+', r == '.', r == ',':
+			return r
+		case r == '(':
+			return '-'
+		}
+		return -1
+	}, value)
+	// The last separator is the decimal one; any earlier ones group digits.
+	if i := strings.LastIndexAny(cleaned, ".,"); i >= 0 {
+		integer := strings.NewReplacer(".", "", ",", "").Replace(cleaned[:i])
+		fraction := cleaned[i+1:]
+		if len(fraction) == 3 {
+			// Statements carry at most two decimals, so "1,299" groups digits.
+			integer, fraction = integer+fraction, ""
+		}
+		cleaned = integer + "." + fraction
+	}
+	amount, err := strconv.ParseFloat(strings.TrimSuffix(cleaned, "."), 64)
+	if err != nil || cleaned == "" {
+		return 0, fmt.Errorf("%w: unrecognised amount %q", model.ErrInvalidInput, value)
+	}
+	return amount, nil
+}
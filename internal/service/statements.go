@@ -0,0 +1,332 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/logging"
+	"github.com/lavatee/subs/internal/model"
+	"github.com/lavatee/subs/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// minRecurringCharges is how many charges make a pattern worth proposing.
+	minRecurringCharges = 3
+	// Charges of one subscription are this many days apart.
+	monthlyMinDays = 26
+	monthlyMaxDays = 35
+	// minProposalConfidence is the share of monthly intervals a group of
+	// charges needs to be proposed.
+	minProposalConfidence = 0.75
+	// amountTolerance groups charges whose amounts differ by up to 10%, which
+	// absorbs currency conversion and rounding.
+	amountTolerance = 0.1
+	// lapsedAfter marks a subscription as ended when the statement goes on
+	// this long past its last charge.
+	lapsedAfter = 45 * 24 * time.Hour
+)
+
+// merchantNoise are description words that say nothing about the merchant.
+var merchantNoise = map[string]bool{
+	"www": true, "com": true, "net": true, "org": true, "ru": true, "io": true, "tv": true,
+	"inc": true, "ltd": true, "llc": true, "ooo": true, "ооо": true, "ип": true,
+	"pos": true, "card": true, "payment": true, "purchase": true, "recurring": true,
+	"оплата": true, "покупка": true, "списание": true, "карта": true,
+}
+
+type StatementsService struct {
+	repo   *repository.Repository
+	logger *logrus.Logger
+}
+
+func NewStatementsService(repo *repository.Repository, logger *logrus.Logger) *StatementsService {
+	return &StatementsService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// AnalyzeStatement finds recurring charges in a bank statement and stores
+// them as pending proposals. Charges matching a subscription the user already
+// has, and proposals the user has accepted or dismissed before, are skipped.
+func (s *StatementsService) AnalyzeStatement(ctx context.Context, userID uuid.UUID, r io.Reader, format string) (model.StatementAnalysisResponse, error) {
+	transactions, skipped, err := parseStatement(r, format)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Warnf("Invalid bank statement: %v", err)
+		return model.StatementAnalysisResponse{}, err
+	}
+	if len(skipped) > 0 {
+		logging.FromContext(ctx, s.logger).Infof("Skipped %d unreadable bank statement rows", len(skipped))
+	}
+
+	subscriptions, err := s.repo.Subscriptions.GetUserSubscriptions(ctx, userID, "", false)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to get subscriptions from repository: %v", err)
+		return model.StatementAnalysisResponse{}, err
+	}
+	known := make(map[string]bool, len(subscriptions))
+	for _, sub := range subscriptions {
+		known[merchantKey(sub.ServiceName)] = true
+	}
+
+	response := model.StatementAnalysisResponse{
+		Transactions: len(transactions),
+		Proposals:    []model.SubscriptionProposal{},
+		Skipped:      skipped,
+	}
+	now := time.Now().UTC()
+	for _, proposal := range detectRecurring(transactions) {
+		if known[proposal.Merchant] {
+			continue
+		}
+		proposal.ID = uuid.New()
+		proposal.UserID = userID
+		proposal.CreatedAt = now
+		stored, ok, err := s.repo.Proposals.UpsertProposal(ctx, proposal)
+		if err != nil {
+			logging.FromContext(ctx, s.logger).Errorf("Failed to store subscription proposal: %v", err)
+			return model.StatementAnalysisResponse{}, err
+		}
+		if ok {
+			response.Proposals = append(response.Proposals, stored)
+		}
+	}
+	return response, nil
+}
+
+func (s *StatementsService) GetProposals(ctx context.Context, userID uuid.UUID, status string) ([]model.SubscriptionProposal, error) {
+	switch status {
+	case "", model.ProposalPending, model.ProposalAccepted, model.ProposalDismissed:
+	default:
+		return nil, fmt.Errorf("%w: unknown proposal status %q", model.ErrInvalidInput, status)
+	}
+	proposals, err := s.repo.Proposals.GetProposals(ctx, userID, status)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to get proposals from repository: %v", err)
+		return nil, err
+	}
+	return proposals, nil
+}
+
+// AcceptProposal creates the proposed subscription, with the values of req
+// taking precedence, and marks the proposal accepted.
+func (s *StatementsService) AcceptProposal(ctx context.Context, id uuid.UUID, req model.AcceptProposalRequest) (model.AcceptProposalResponse, error) {
+	var response model.AcceptProposalResponse
+	err := s.repo.WithinTx(ctx, func(repos *repository.Repository) error {
+		proposal, err := repos.Proposals.GetProposal(ctx, id)
+		if err != nil {
+			logging.FromContext(ctx, s.logger).Errorf("Failed to get proposal: %v", err)
+			return err
+		}
+		if proposal.Status != model.ProposalPending {
+			return fmt.Errorf("proposal %s is already %s: %w", id, proposal.Status, model.ErrConflict)
+		}
+
+		subscription, err := newSubscription(proposalRequest(proposal, req))
+		if err != nil {
+			logging.FromContext(ctx, s.logger).Warnf("Invalid subscription: %v", err)
+			return err
+		}
+		warnings, err := createSubscription(ctx, repos, s.logger, subscription)
+		if err != nil {
+			return err
+		}
+		if err := repos.Proposals.ResolveProposal(ctx, id, model.ProposalAccepted, &subscription.ID); err != nil {
+			logging.FromContext(ctx, s.logger).Errorf("Failed to accept proposal: %v", err)
+			return err
+		}
+
+		proposal.Status = model.ProposalAccepted
+		proposal.SubscriptionID = &subscription.ID
+		response = model.AcceptProposalResponse{
+			Proposal:     proposal,
+			Subscription: subscription,
+			Warnings:     warnings,
+		}
+		return nil
+	})
+	if err != nil {
+		return model.AcceptProposalResponse{}, err
+	}
+	return response, nil
+}
+
+func (s *StatementsService) DismissProposal(ctx context.Context, id uuid.UUID) (model.SubscriptionProposal, error) {
+	proposal, err := s.repo.Proposals.GetProposal(ctx, id)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to get proposal: %v", err)
+		return model.SubscriptionProposal{}, err
+	}
+	if err := s.repo.Proposals.ResolveProposal(ctx, id, model.ProposalDismissed, nil); err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to dismiss proposal: %v", err)
+		return model.SubscriptionProposal{}, err
+	}
+	proposal.Status = model.ProposalDismissed
+	return proposal, nil
+}
+
+func proposalRequest(proposal model.SubscriptionProposal, req model.AcceptProposalRequest) model.CreateSubscriptionRequest {
+	create := model.CreateSubscriptionRequest{
+		ServiceName: proposal.ServiceName,
+		Price:       proposal.Price,
+		UserID:      proposal.UserID,
+		StartDate:   proposal.StartDate.Format("01-2006"),
+	}
+	if proposal.EndDate != nil {
+		create.EndDate = proposal.EndDate.Format("01-2006")
+	}
+	if req.ServiceName != "" {
+		create.ServiceName = req.ServiceName
+	}
+	if req.Price != 0 {
+		create.Price = req.Price
+	}
+	if req.StartDate != "" {
+		create.StartDate = req.StartDate
+	}
+	if req.EndDate != nil {
+		create.EndDate = *req.EndDate
+	}
+	return create
+}
+
+// detectRecurring groups the charges of a statement by merchant and amount
+// and proposes the groups that repeat monthly.
+func detectRecurring(transactions []model.Transaction) []model.SubscriptionProposal {
+	var statementEnd time.Time
+	debits := 0
+	for _, transaction := range transactions {
+		if transaction.Date.After(statementEnd) {
+			statementEnd = transaction.Date
+		}
+		if transaction.Amount < 0 {
+			debits++
+		}
+	}
+
+	// Statements that only list spending may leave charges unsigned.
+	byMerchant := make(map[string][]model.Transaction)
+	for _, transaction := range transactions {
+		if debits > 0 && transaction.Amount >= 0 {
+			continue
+		}
+		key := merchantKey(transaction.Description)
+		if key == "" {
+			continue
+		}
+		transaction.Amount = math.Abs(transaction.Amount)
+		byMerchant[key] = append(byMerchant[key], transaction)
+	}
+
+	var proposals []model.SubscriptionProposal
+	for merchant, charges := range byMerchant {
+		for _, group := range groupByAmount(charges) {
+			proposal, ok := recurringProposal(group, statementEnd)
+			if !ok {
+				continue
+			}
+			proposal.Merchant = merchant
+			proposal.ServiceName = merchantName(merchant)
+			proposals = append(proposals, proposal)
+		}
+	}
+	sort.Slice(proposals, func(i, j int) bool {
+		if proposals[i].ServiceName != proposals[j].ServiceName {
+			return proposals[i].ServiceName < proposals[j].ServiceName
+		}
+		return proposals[i].Price < proposals[j].Price
+	})
+	return proposals
+}
+
+// groupByAmount splits charges into groups of similar amounts.
+func groupByAmount(charges []model.Transaction) [][]model.Transaction {
+	sort.Slice(charges, func(i, j int) bool { return charges[i].Amount < charges[j].Amount })
+	var groups [][]model.Transaction
+	for _, charge := range charges {
+		last := len(groups) - 1
+		if last >= 0 && charge.Amount <= groups[last][0].Amount*(1+amountTolerance) {
+			groups[last] = append(groups[last], charge)
+			continue
+		}
+		groups = append(groups, []model.Transaction{charge})
+	}
+	return groups
+}
+
+// recurringProposal proposes a subscription for charges of one merchant and
+// amount when they repeat monthly.
+func recurringProposal(charges []model.Transaction, statementEnd time.Time) (model.SubscriptionProposal, bool) {
+	if len(charges) < minRecurringCharges {
+		return model.SubscriptionProposal{}, false
+	}
+	sort.Slice(charges, func(i, j int) bool { return charges[i].Date.Before(charges[j].Date) })
+
+	monthly := 0
+	for i := 1; i < len(charges); i++ {
+		days := charges[i].Date.Sub(charges[i-1].Date).Hours() / 24
+		if days >= monthlyMinDays && days <= monthlyMaxDays {
+			monthly++
+		}
+	}
+	confidence := float64(monthly) / float64(len(charges)-1)
+	if confidence < minProposalConfidence {
+		return model.SubscriptionProposal{}, false
+	}
+
+	first, last := charges[0], charges[len(charges)-1]
+	proposal := model.SubscriptionProposal{
+		Price:          int(math.Round(last.Amount)),
+		StartDate:      monthStart(first.Date),
+		LastChargeDate: last.Date,
+		Charges:        len(charges),
+		Confidence:     math.Round(confidence*100) / 100,
+	}
+	if statementEnd.Sub(last.Date) > lapsedAfter {
+		endDate := monthStart(last.Date)
+		proposal.EndDate = &endDate
+	}
+	return proposal, proposal.Price > 0
+}
+
+// merchantKey normalizes a transaction description or service name to the
+// words that identify the merchant: "NETFLIX.COM 866-579" and "Netflix"
+// both become "netflix".
+func merchantKey(description string) string {
+	words := strings.FieldsFunc(strings.ToLower(description), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	key := make([]string, 0, 3)
+	for _, word := range words {
+		if len([]rune(word)) < 2 || merchantNoise[word] {
+			continue
+		}
+		if key = append(key, word); len(key) == 3 {
+			break
+		}
+	}
+	return strings.Join(key, " ")
+}
+
+// merchantName turns a merchant key into a service name.
+func merchantName(merchant string) string {
+	words := strings.Fields(merchant)
+	for i, word := range words {
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = string(runes)
+	}
+	return strings.Join(words, " ")
+}
+
+func monthStart(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
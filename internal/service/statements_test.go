@@ -0,0 +1,186 @@
+package service
+
+import (
+	"bufio"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lavatee/subs/internal/model"
+)
+
+func TestParseStatementCSV(t *testing.T) {
+	input := strings.Join([]string{
+		"\ufeffДата операции;Описание;Сумма операции;Валюта",
+		"05.07.2025;NETFLIX.COM 866-579;-1 299,00;RUB",
+		"06.07.2025;Зарплата;150 000,00;RUB",
+		";;;",
+		"Итого за период;;148 701,00;RUB",
+	}, "\n")
+	transactions, skipped, err := parseStatement(strings.NewReader(input), StatementCSV)
+	if err != nil {
+		t.Fatalf("parseStatement: %v", err)
+	}
+	if len(transactions) != 2 {
+		t.Fatalf("want 2 transactions, got %+v", transactions)
+	}
+	if len(skipped) != 1 || skipped[0].Row != 5 {
+		t.Fatalf("want the summary row on line 5 skipped, got %+v", skipped)
+	}
+	netflix := transactions[0]
+	if !netflix.Date.Equal(time.Date(2025, 7, 5, 0, 0, 0, 0, time.UTC)) || netflix.Amount != -1299 || netflix.Description != "NETFLIX.COM 866-579" {
+		t.Fatalf("unexpected transaction %+v", netflix)
+	}
+	if transactions[1].Amount != 150000 {
+		t.Fatalf("unexpected amount %v", transactions[1].Amount)
+	}
+
+	if _, _, err := parseStatement(strings.NewReader("when,what\n"), StatementCSV); !errors.Is(err, model.ErrInvalidInput) {
+		t.Fatalf("statement without known columns: want ErrInvalidInput, got %v", err)
+	}
+}
+
+func TestParseStatementQIF(t *testing.T) {
+	input := `!Type:Bank
+D07/05/2025
+T-399.00
+PSpotify AB
+^
+D07/20'25
+T-12.50
+MCoffee
+^
+DTotal
+T-411.50
+^
+`
+	transactions, skipped, err := parseStatement(strings.NewReader(input), StatementQIF)
+	if err != nil {
+		t.Fatalf("parseStatement: %v", err)
+	}
+	if len(skipped) != 1 || skipped[0].Row != 10 {
+		t.Fatalf("want the record on line 10 skipped, got %+v", skipped)
+	}
+	if len(transactions) != 2 || transactions[0].Description != "Spotify AB" || transactions[0].Amount != -399 {
+		t.Fatalf("unexpected transactions %+v", transactions)
+	}
+	if transactions[1].Description != "Coffee" || transactions[1].Date.Day() != 20 {
+		t.Fatalf("memo should stand in for a missing payee: %+v", transactions[1])
+	}
+
+	long := "D07/05/2025\nP" + strings.Repeat("x", bufio.MaxScanTokenSize) + "\n^\n"
+	if _, _, err := parseStatement(strings.NewReader(long), StatementQIF); !errors.Is(err, model.ErrInvalidInput) {
+		t.Fatalf("oversized line: want ErrInvalidInput, got %v", err)
+	}
+}
+
+func TestParseStatementOFX(t *testing.T) {
+	input := `OFXHEADER:100
+DATA:OFXSGML
+
+<OFX><BANKMSGSRSV1><STMTTRNRS><STMTRS><BANKTRANLIST>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20250705120000[+3:MSK]
+<TRNAMT>-299.00
+<FITID>1
+<NAME>YANDEX*PLUS
+</STMTTRN>
+<STMTTRN><TRNTYPE>DEBIT</TRNTYPE><DTPOSTED>20250805</DTPOSTED><TRNAMT>-299.00</TRNAMT><FITID>2</FITID><MEMO>YANDEX*PLUS</MEMO></STMTTRN>
+</BANKTRANLIST></STMTRS></STMTTRNRS></BANKMSGSRSV1></OFX>`
+	transactions, _, err := parseStatement(strings.NewReader(input), StatementOFX)
+	if err != nil {
+		t.Fatalf("parseStatement: %v", err)
+	}
+	if len(transactions) != 2 {
+		t.Fatalf("want 2 transactions, got %+v", transactions)
+	}
+	for _, transaction := range transactions {
+		if transaction.Description != "YANDEX*PLUS" || transaction.Amount != -299 {
+			t.Fatalf("unexpected transaction %+v", transaction)
+		}
+	}
+	if transactions[1].Date.Month() != time.August {
+		t.Fatalf("unexpected date %v", transactions[1].Date)
+	}
+
+	if _, _, err := parseStatement(strings.NewReader("hello"), StatementOFX); !errors.Is(err, model.ErrInvalidInput) {
+		t.Fatalf("non-OFX input: want ErrInvalidInput, got %v", err)
+	}
+}
+
+func TestParseStatementAmount(t *testing.T) {
+	tests := map[string]float64{
+		"-399.00":    -399,
+		"-1 299,00":  -1299,
+		"1,299.50":   1299.5,
+		"1.299,50 ₽": 1299.5,
+		"-1,299":     -1299,
+		"(45.10)":    -45.1,
+		"+12":        12,
+		"1 000,5":    1000.5,
+	}
+	for input, want := range tests {
+		got, err := parseStatementAmount(input)
+		if err != nil || got != want {
+			t.Errorf("parseStatementAmount(%q) = %v, %v; want %v", input, got, err, want)
+		}
+	}
+	if _, err := parseStatementAmount("abc"); !errors.Is(err, model.ErrInvalidInput) {
+		t.Errorf("parseStatementAmount(abc): want ErrInvalidInput, got %v", err)
+	}
+}
+
+func TestDetectRecurring(t *testing.T) {
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2025, month, d, 0, 0, 0, 0, time.UTC)
+	}
+	var transactions []model.Transaction
+	for month := time.January; month <= time.June; month++ {
+		transactions = append(transactions,
+			model.Transaction{Date: day(month, 5), Description: "NETFLIX.COM 866-579", Amount: -799},
+			model.Transaction{Date: day(month, 12+int(month)), Description: "Grocery Store", Amount: -float64(1000 + 300*int(month))},
+			model.Transaction{Date: day(month, 25), Description: "Salary", Amount: 100000},
+		)
+	}
+	// Cancelled after three months.
+	for month := time.January; month <= time.March; month++ {
+		transactions = append(transactions, model.Transaction{Date: day(month, 20), Description: "Spotify P1234", Amount: -169.99})
+	}
+	// Regular amount, irregular dates.
+	for _, date := range []time.Time{day(1, 1), day(1, 9), day(3, 30), day(4, 2)} {
+		transactions = append(transactions, model.Transaction{Date: date, Description: "Taxi", Amount: -500})
+	}
+
+	proposals := detectRecurring(transactions)
+	if len(proposals) != 2 {
+		t.Fatalf("want Netflix and Spotify, got %+v", proposals)
+	}
+
+	netflix, spotify := proposals[0], proposals[1]
+	if netflix.ServiceName != "Netflix" || netflix.Merchant != "netflix" || netflix.Price != 799 || netflix.Charges != 6 || netflix.Confidence != 1 {
+		t.Fatalf("unexpected Netflix proposal %+v", netflix)
+	}
+	if !netflix.StartDate.Equal(day(1, 1)) || netflix.EndDate != nil {
+		t.Fatalf("Netflix should be ongoing since January: %+v", netflix)
+	}
+	if spotify.ServiceName != "Spotify" || spotify.Price != 170 || spotify.EndDate == nil || !spotify.EndDate.Equal(day(3, 1)) {
+		t.Fatalf("Spotify should have ended in March: %+v", spotify)
+	}
+}
+
+func TestMerchantKey(t *testing.T) {
+	tests := map[string]string{
+		"NETFLIX.COM 866-579-7172": "netflix",
+		"Netflix":                  "netflix",
+		"Оплата YANDEX*PLUS":       "yandex plus",
+		"Yandex Plus":              "yandex plus",
+		"POS 12345":                "",
+	}
+	for input, want := range tests {
+		if got := merchantKey(input); got != want {
+			t.Errorf("merchantKey(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
@@ -2,9 +2,12 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/logging"
 	"github.com/lavatee/subs/internal/model"
 	"github.com/lavatee/subs/internal/repository"
 	"github.com/sirupsen/logrus"
@@ -22,45 +25,55 @@ func NewSubscriptionsService(repo *repository.Repository, logger *logrus.Logger)
 	}
 }
 
-func (s *SubscriptionsService) CreateSubscription(ctx context.Context, req model.CreateSubscriptionRequest) (model.Subscription, error) {
-	startDate, err := time.Parse("01-2006", req.StartDate)
+func (s *SubscriptionsService) CreateSubscription(ctx context.Context, req model.CreateSubscriptionRequest) (model.Subscription, []model.BudgetWarning, error) {
+	subscription, err := newSubscription(req)
 	if err != nil {
-		s.logger.Warnf("Invalid start date format: %v", err)
-		return model.Subscription{}, err
+		logging.FromContext(ctx, s.logger).Warnf("Invalid subscription: %v", err)
+		return model.Subscription{}, nil, err
 	}
 
-	var endDate *time.Time
-	if req.EndDate != "" {
-		parsedEndDate, err := time.Parse("01-2006", req.EndDate)
-		if err != nil {
-			s.logger.Warnf("Invalid end date format: %v", err)
-			return model.Subscription{}, err
-		}
-		endDate = &parsedEndDate
+	var warnings []model.BudgetWarning
+	err = s.repo.WithinTx(ctx, func(repos *repository.Repository) error {
+		warnings, err = createSubscription(ctx, repos, s.logger, subscription)
+		return err
+	})
+	if err != nil {
+		return model.Subscription{}, nil, err
 	}
 
-	subscription := model.Subscription{
-		ID:          uuid.New(),
-		ServiceName: req.ServiceName,
-		Price:       req.Price,
-		UserID:      req.UserID,
-		StartDate:   startDate,
-		EndDate:     endDate,
-		CreatedAt:   time.Now(),
-	}
+	return subscription, warnings, nil
+}
 
-	if err := s.repo.Subscriptions.CreateSubscription(ctx, subscription); err != nil {
-		s.logger.Errorf("Failed to create subscription in repository: %v", err)
-		return model.Subscription{}, err
+// createSubscription stores subscription with its event and checks the
+// user's budgets. It must be called inside WithinTx.
+func createSubscription(ctx context.Context, repos *repository.Repository, logger *logrus.Logger, subscription model.Subscription) ([]model.BudgetWarning, error) {
+	log := logging.FromContext(ctx, logger)
+	now := time.Now().UTC()
+	budgets, err := userBudgetsConsumption(ctx, repos, subscription.UserID, now)
+	if err != nil {
+		log.Errorf("Failed to get budget consumption: %v", err)
+		return nil, err
 	}
-
-	return subscription, nil
+	if err := repos.Subscriptions.CreateSubscription(ctx, subscription); err != nil {
+		log.Errorf("Failed to create subscription in repository: %v", err)
+		return nil, err
+	}
+	if err := recordEvent(ctx, repos, model.NewSubscriptionEvent(model.EventSubscriptionCreated, subscription)); err != nil {
+		log.Errorf("Failed to record subscription event: %v", err)
+		return nil, err
+	}
+	warnings, err := checkBudgets(ctx, repos, budgets, subscription, now)
+	if err != nil {
+		log.Errorf("Failed to check budgets: %v", err)
+		return nil, err
+	}
+	return warnings, nil
 }
 
-func (s *SubscriptionsService) GetUserSubscriptions(ctx context.Context, userID uuid.UUID, serviceName string) ([]model.Subscription, error) {
-	subscriptions, err := s.repo.Subscriptions.GetUserSubscriptions(ctx, userID, serviceName)
+func (s *SubscriptionsService) GetUserSubscriptions(ctx context.Context, userID uuid.UUID, serviceName string, includeArchived bool) ([]model.Subscription, error) {
+	subscriptions, err := s.repo.Subscriptions.GetUserSubscriptions(ctx, userID, serviceName, includeArchived)
 	if err != nil {
-		s.logger.Errorf("Failed to get subscriptions from repository: %v", err)
+		logging.FromContext(ctx, s.logger).Errorf("Failed to get subscriptions from repository: %v", err)
 		return nil, err
 	}
 
@@ -70,73 +83,220 @@ func (s *SubscriptionsService) GetUserSubscriptions(ctx context.Context, userID
 func (s *SubscriptionsService) GetSubscription(ctx context.Context, id uuid.UUID) (model.Subscription, error) {
 	subscription, err := s.repo.Subscriptions.GetSubscription(ctx, id)
 	if err != nil {
-		s.logger.Errorf("Failed to get subscription from repository: %v", err)
+		logging.FromContext(ctx, s.logger).Errorf("Failed to get subscription from repository: %v", err)
 		return model.Subscription{}, err
 	}
 
 	return subscription, nil
 }
 
-func (s *SubscriptionsService) UpdateSubscription(ctx context.Context, id uuid.UUID, req model.UpdateSubscriptionRequest) (model.Subscription, error) {
-	existing, err := s.repo.Subscriptions.GetSubscription(ctx, id)
-	if err != nil {
-		s.logger.Errorf("Failed to get existing subscription for update: %v", err)
-		return model.Subscription{}, err
-	}
+func (s *SubscriptionsService) UpdateSubscription(ctx context.Context, id uuid.UUID, req model.UpdateSubscriptionRequest) (model.Subscription, []model.BudgetWarning, error) {
+	var updated model.Subscription
+	var warnings []model.BudgetWarning
+	err := s.repo.WithinTx(ctx, func(repos *repository.Repository) error {
+		existing, err := repos.Subscriptions.GetSubscription(ctx, id)
+		if err != nil {
+			logging.FromContext(ctx, s.logger).Errorf("Failed to get existing subscription for update: %v", err)
+			return err
+		}
+		now := time.Now().UTC()
+		budgets, err := userBudgetsConsumption(ctx, repos, existing.UserID, now)
+		if err != nil {
+			logging.FromContext(ctx, s.logger).Errorf("Failed to get budget consumption: %v", err)
+			return err
+		}
 
-	if req.ServiceName != "" {
-		existing.ServiceName = req.ServiceName
-	}
+		if req.ServiceName != "" {
+			existing.ServiceName = req.ServiceName
+		}
 
-	if req.Price != 0 {
-		existing.Price = req.Price
-	}
+		if req.Price != 0 {
+			existing.Price = req.Price
+		}
 
-	if req.StartDate != "" {
-		startDate, err := time.Parse("01-2006", req.StartDate)
+		if req.StartDate != "" {
+			startDate, err := time.Parse("01-2006", req.StartDate)
+			if err != nil {
+				logging.FromContext(ctx, s.logger).Warnf("Invalid start date format: %v", err)
+				return fmt.Errorf("%w: invalid start date format: %v", model.ErrInvalidInput, err)
+			}
+			existing.StartDate = startDate
+		}
+
+		if req.EndDate != nil {
+			if *req.EndDate == "" {
+				existing.EndDate = nil
+			} else {
+				endDate, err := time.Parse("01-2006", *req.EndDate)
+				if err != nil {
+					logging.FromContext(ctx, s.logger).Warnf("Invalid end date format: %v", err)
+					return fmt.Errorf("%w: invalid end date format: %v", model.ErrInvalidInput, err)
+				}
+				existing.EndDate = &endDate
+			}
+		}
+		if err := checkSubscriptionDates(existing.StartDate, existing.EndDate); err != nil {
+			logging.FromContext(ctx, s.logger).Warnf("Invalid subscription dates: %v", err)
+			return err
+		}
+
+		if err := repos.Subscriptions.UpdateSubscription(ctx, existing); err != nil {
+			logging.FromContext(ctx, s.logger).Errorf("Failed to update subscription in repository: %v", err)
+			return err
+		}
+		if err := recordEvent(ctx, repos, model.NewSubscriptionEvent(model.EventSubscriptionUpdated, existing)); err != nil {
+			logging.FromContext(ctx, s.logger).Errorf("Failed to record subscription event: %v", err)
+			return err
+		}
+		warnings, err = checkBudgets(ctx, repos, budgets, existing, now)
 		if err != nil {
-			s.logger.Warnf("Invalid start date format: %v", err)
-			return model.Subscription{}, err
+			logging.FromContext(ctx, s.logger).Errorf("Failed to check budgets: %v", err)
+			return err
 		}
-		existing.StartDate = startDate
+
+		updated = existing
+		return nil
+	}, repository.WithIsolation(sql.LevelRepeatableRead))
+	if err != nil {
+		return model.Subscription{}, nil, err
 	}
 
-	if req.EndDate != nil {
-		if *req.EndDate == "" {
-			existing.EndDate = nil
-		} else {
-			endDate, err := time.Parse("01-2006", *req.EndDate)
-			if err != nil {
-				s.logger.Warnf("Invalid end date format: %v", err)
-				return model.Subscription{}, err
-			}
-			existing.EndDate = &endDate
+	return updated, warnings, nil
+}
+
+func (s *SubscriptionsService) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
+	return s.repo.WithinTx(ctx, func(repos *repository.Repository) error {
+		subscription, err := repos.Subscriptions.GetSubscription(ctx, id)
+		if err != nil {
+			logging.FromContext(ctx, s.logger).Errorf("Failed to get subscription for delete: %v", err)
+			return err
+		}
+		if err := repos.Subscriptions.DeleteSubscription(ctx, id); err != nil {
+			logging.FromContext(ctx, s.logger).Errorf("Failed to delete subscription from repository: %v", err)
+			return err
 		}
+		if err := recordEvent(ctx, repos, model.NewSubscriptionEvent(model.EventSubscriptionDeleted, subscription)); err != nil {
+			logging.FromContext(ctx, s.logger).Errorf("Failed to record subscription event: %v", err)
+			return err
+		}
+		return nil
+	})
+}
+
+func (s *SubscriptionsService) GetTotalCost(ctx context.Context, userID uuid.UUID, serviceName string, startDate, endDate time.Time, includeArchived bool) (int, error) {
+	total, err := s.repo.Subscriptions.GetTotalCost(ctx, userID, serviceName, startDate, endDate, includeArchived)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to calculate total cost in repository: %v", err)
+		return 0, err
 	}
 
-	if err := s.repo.Subscriptions.UpdateSubscription(ctx, existing); err != nil {
-		s.logger.Errorf("Failed to update subscription in repository: %v", err)
-		return model.Subscription{}, err
+	return total, nil
+}
+
+// ExplainTotalCost returns the total cost, as GetTotalCost computes it,
+// together with the subscriptions it adds up. Both are read from one
+// snapshot, so the explanation flags a mismatch only when the monthly spend
+// ledger has drifted from the subscriptions.
+func (s *SubscriptionsService) ExplainTotalCost(ctx context.Context, userID uuid.UUID, serviceName string, startDate, endDate time.Time, includeArchived bool) (int, model.TotalCostExplanation, error) {
+	var total int
+	var explanation model.TotalCostExplanation
+	err := s.repo.WithinTx(ctx, func(repos *repository.Repository) error {
+		var err error
+		total, err = repos.Subscriptions.GetTotalCost(ctx, userID, serviceName, startDate, endDate, includeArchived)
+		if err != nil {
+			logging.FromContext(ctx, s.logger).Errorf("Failed to calculate total cost in repository: %v", err)
+			return err
+		}
+		subscriptions, err := repos.Subscriptions.GetTotalCostSubscriptions(ctx, userID, serviceName, startDate, endDate, includeArchived)
+		if err != nil {
+			logging.FromContext(ctx, s.logger).Errorf("Failed to get total cost subscriptions in repository: %v", err)
+			return err
+		}
+		explanation = explainTotalCost(subscriptions, startDate, endDate, time.Now().UTC())
+		return nil
+	}, repository.WithIsolation(sql.LevelRepeatableRead), repository.ReadOnly())
+	if err != nil {
+		return 0, model.TotalCostExplanation{}, err
 	}
 
-	return existing, nil
+	if explanation.ItemsTotal != total {
+		explanation.Mismatch = true
+		logging.FromContext(ctx, s.logger).Warnf("Total cost %d does not match its subscriptions, which add up to %d", total, explanation.ItemsTotal)
+	}
+	return total, explanation, nil
 }
 
-func (s *SubscriptionsService) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
-	if err := s.repo.Subscriptions.DeleteSubscription(ctx, id); err != nil {
-		s.logger.Errorf("Failed to delete subscription from repository: %v", err)
-		return err
+func explainTotalCost(subscriptions []model.Subscription, startDate, endDate, now time.Time) model.TotalCostExplanation {
+	explanation := model.TotalCostExplanation{
+		Rule:  model.TotalCostRule,
+		Items: make([]model.TotalCostItem, 0, len(subscriptions)),
 	}
+	for _, sub := range subscriptions {
+		first := sub.StartDate
+		if startDate.After(first) {
+			first = monthStart(startDate)
+		}
+		last := monthStart(now)
+		if sub.EndDate != nil {
+			last = *sub.EndDate
+		}
+		if !endDate.IsZero() && (sub.EndDate == nil || endDate.Before(last)) {
+			last = monthStart(endDate)
+		}
+		// Subscriptions starting after the current month in an open window.
+		if last.Before(first) {
+			last = first
+		}
 
-	return nil
+		explanation.Items = append(explanation.Items, model.TotalCostItem{
+			Subscription: sub,
+			FirstMonth:   first,
+			LastMonth:    last,
+			ActiveMonths: monthsBetween(first, last) + 1,
+			Price:        sub.Price,
+			Subtotal:     sub.Price,
+		})
+		explanation.ItemsTotal += sub.Price
+	}
+	return explanation
 }
 
-func (s *SubscriptionsService) GetTotalCost(ctx context.Context, userID uuid.UUID, serviceName string, startDate, endDate time.Time) (int, error) {
-	total, err := s.repo.Subscriptions.GetTotalCost(ctx, userID, serviceName, startDate, endDate)
+// newSubscription builds a new subscription from a create request.
+func newSubscription(req model.CreateSubscriptionRequest) (model.Subscription, error) {
+	startDate, err := time.Parse("01-2006", req.StartDate)
 	if err != nil {
-		s.logger.Errorf("Failed to calculate total cost in repository: %v", err)
-		return 0, err
+		return model.Subscription{}, fmt.Errorf("%w: invalid start date format: %v", model.ErrInvalidInput, err)
 	}
 
-	return total, nil
+	var endDate *time.Time
+	if req.EndDate != "" {
+		parsedEndDate, err := time.Parse("01-2006", req.EndDate)
+		if err != nil {
+			return model.Subscription{}, fmt.Errorf("%w: invalid end date format: %v", model.ErrInvalidInput, err)
+		}
+		endDate = &parsedEndDate
+	}
+	if err := checkSubscriptionDates(startDate, endDate); err != nil {
+		return model.Subscription{}, err
+	}
+
+	return model.Subscription{
+		ID:          uuid.New(),
+		ServiceName: req.ServiceName,
+		Price:       req.Price,
+		UserID:      req.UserID,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		CreatedAt:   time.Now(),
+	}, nil
+}
+
+// checkSubscriptionDates rejects subscriptions that end before they start.
+// End dates are inclusive, so ending in the start month is one paid month.
+func checkSubscriptionDates(startDate time.Time, endDate *time.Time) error {
+	if endDate != nil && endDate.Before(startDate) {
+		return fmt.Errorf("%w: end date %s is before start date %s", model.ErrInvalidInput,
+			endDate.Format("01-2006"), startDate.Format("01-2006"))
+	}
+	return nil
 }
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/model"
+)
+
+func TestExplainTotalCost(t *testing.T) {
+	now := time.Date(2025, 7, 15, 0, 0, 0, 0, time.UTC)
+	month := func(year int, m time.Month) time.Time {
+		return time.Date(year, m, 1, 0, 0, 0, 0, time.UTC)
+	}
+	march := month(2025, time.March)
+	subs := []model.Subscription{
+		{ID: uuid.New(), ServiceName: "Netflix", Price: 100, StartDate: month(2024, time.November), EndDate: &march},
+		{ID: uuid.New(), ServiceName: "Spotify", Price: 20, StartDate: month(2025, time.February)},
+		{ID: uuid.New(), ServiceName: "Okko", Price: 5, StartDate: month(2025, time.September)},
+	}
+
+	tests := []struct {
+		name       string
+		subs       []model.Subscription
+		start, end time.Time
+		wantTotal  int
+		want       string
+	}{
+		{
+			name:      "bounded window",
+			subs:      subs[:2],
+			start:     month(2025, time.January),
+			end:       month(2025, time.April),
+			wantTotal: 120,
+			want:      "[Netflix 2025-01..2025-03 3x100=100 Spotify 2025-02..2025-04 3x20=20]",
+		},
+		{
+			name:      "open window runs to the current month",
+			subs:      subs,
+			wantTotal: 125,
+			want:      "[Netflix 2024-11..2025-03 5x100=100 Spotify 2025-02..2025-07 6x20=20 Okko 2025-09..2025-09 1x5=5]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			explanation := explainTotalCost(tt.subs, tt.start, tt.end, now)
+			if explanation.ItemsTotal != tt.wantTotal || explanation.Rule != model.TotalCostRule {
+				t.Fatalf("unexpected total %d, rule %q", explanation.ItemsTotal, explanation.Rule)
+			}
+			var got []string
+			for _, item := range explanation.Items {
+				got = append(got, fmt.Sprintf("%s %s..%s %dx%d=%d", item.Subscription.ServiceName,
+					item.FirstMonth.Format("2006-01"), item.LastMonth.Format("2006-01"), item.ActiveMonths, item.Price, item.Subtotal))
+			}
+			if fmt.Sprint(got) != tt.want {
+				t.Fatalf("got %v\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSubscriptionRejectsEndBeforeStart(t *testing.T) {
+	req := model.CreateSubscriptionRequest{
+		ServiceName: "Netflix",
+		Price:       400,
+		UserID:      uuid.New(),
+		StartDate:   "07-2025",
+		EndDate:     "06-2025",
+	}
+	if _, err := newSubscription(req); !errors.Is(err, model.ErrInvalidInput) {
+		t.Fatalf("want ErrInvalidInput, got %v", err)
+	}
+
+	req.EndDate = "07-2025"
+	if _, err := newSubscription(req); err != nil {
+		t.Fatalf("ending in the start month: %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/model"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/lavatee/subs/internal/service")
+
+// tracedSubscriptions wraps every Subscriptions call in a span, so that a
+// request trace separates the time spent in the service from the queries
+// beneath it.
+type tracedSubscriptions struct {
+	next   Subscriptions
+	tracer trace.Tracer
+}
+
+func newTracedSubscriptions(next Subscriptions, tracer trace.Tracer) *tracedSubscriptions {
+	return &tracedSubscriptions{
+		next:   next,
+		tracer: tracer,
+	}
+}
+
+// start starts the span of method. The returned function ends it, marking it
+// failed when err is not nil.
+func (s *tracedSubscriptions) start(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, func(err error)) {
+	ctx, span := s.tracer.Start(ctx, "SubscriptionsService."+method, trace.WithAttributes(attrs...))
+	return ctx, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+func (s *tracedSubscriptions) CreateSubscription(ctx context.Context, request model.CreateSubscriptionRequest) (_ model.Subscription, _ []model.BudgetWarning, err error) {
+	ctx, end := s.start(ctx, "CreateSubscription", attribute.String("subs.service_name", request.ServiceName))
+	defer func() { end(err) }()
+	return s.next.CreateSubscription(ctx, request)
+}
+
+func (s *tracedSubscriptions) GetUserSubscriptions(ctx context.Context, userID uuid.UUID, serviceName string, includeArchived bool) (_ []model.Subscription, err error) {
+	ctx, end := s.start(ctx, "GetUserSubscriptions", attribute.String("subs.user_id", userID.String()))
+	defer func() { end(err) }()
+	return s.next.GetUserSubscriptions(ctx, userID, serviceName, includeArchived)
+}
+
+func (s *tracedSubscriptions) GetSubscription(ctx context.Context, id uuid.UUID) (_ model.Subscription, err error) {
+	ctx, end := s.start(ctx, "GetSubscription", attribute.String("subs.subscription_id", id.String()))
+	defer func() { end(err) }()
+	return s.next.GetSubscription(ctx, id)
+}
+
+func (s *tracedSubscriptions) UpdateSubscription(ctx context.Context, id uuid.UUID, request model.UpdateSubscriptionRequest) (_ model.Subscription, _ []model.BudgetWarning, err error) {
+	ctx, end := s.start(ctx, "UpdateSubscription", attribute.String("subs.subscription_id", id.String()))
+	defer func() { end(err) }()
+	return s.next.UpdateSubscription(ctx, id, request)
+}
+
+func (s *tracedSubscriptions) DeleteSubscription(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, end := s.start(ctx, "DeleteSubscription", attribute.String("subs.subscription_id", id.String()))
+	defer func() { end(err) }()
+	return s.next.DeleteSubscription(ctx, id)
+}
+
+func (s *tracedSubscriptions) GetTotalCost(ctx context.Context, userID uuid.UUID, serviceName string, startDate, endDate time.Time, includeArchived bool) (_ int, err error) {
+	ctx, end := s.start(ctx, "GetTotalCost", attribute.String("subs.start_date", startDate.Format(time.DateOnly)), attribute.String("subs.end_date", endDate.Format(time.DateOnly)))
+	defer func() { end(err) }()
+	return s.next.GetTotalCost(ctx, userID, serviceName, startDate, endDate, includeArchived)
+}
+
+func (s *tracedSubscriptions) ExplainTotalCost(ctx context.Context, userID uuid.UUID, serviceName string, startDate, endDate time.Time, includeArchived bool) (_ int, _ model.TotalCostExplanation, err error) {
+	ctx, end := s.start(ctx, "ExplainTotalCost", attribute.String("subs.start_date", startDate.Format(time.DateOnly)), attribute.String("subs.end_date", endDate.Format(time.DateOnly)))
+	defer func() { end(err) }()
+	return s.next.ExplainTotalCost(ctx, userID, serviceName, startDate, endDate, includeArchived)
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/model"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// spanSubscriptions answers GetSubscription with the span found in ctx.
+type spanSubscriptions struct {
+	Subscriptions
+	span trace.SpanContext
+	err  error
+}
+
+func (s *spanSubscriptions) GetSubscription(ctx context.Context, id uuid.UUID) (model.Subscription, error) {
+	s.span = trace.SpanContextFromContext(ctx)
+	return model.Subscription{ID: id}, s.err
+}
+
+func TestTracedSubscriptionsNestsSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := provider.Tracer("test")
+	next := &spanSubscriptions{}
+	subs := newTracedSubscriptions(next, tracer)
+
+	ctx, parent := tracer.Start(context.Background(), "request")
+	if _, err := subs.GetSubscription(ctx, uuid.New()); err != nil {
+		t.Fatalf("GetSubscription: %v", err)
+	}
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("spans: got %d, want 2", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "SubscriptionsService.GetSubscription" {
+		t.Fatalf("name: got %s", span.Name())
+	}
+	if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Fatal("service span is not a child of the request span")
+	}
+	if next.span.SpanID() != span.SpanContext().SpanID() {
+		t.Fatal("the wrapped service did not get the service span")
+	}
+	if span.Status().Code == codes.Error {
+		t.Fatal("successful call marked as failed")
+	}
+
+	next.err = model.ErrNotFound
+	if _, err := subs.GetSubscription(context.Background(), uuid.New()); err != model.ErrNotFound {
+		t.Fatalf("GetSubscription: got %v", err)
+	}
+	if spans := recorder.Ended(); spans[len(spans)-1].Status().Code != codes.Error {
+		t.Fatal("failed call not marked as failed")
+	}
+}
@@ -0,0 +1,406 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lavatee/subs/internal/logging"
+	"github.com/lavatee/subs/internal/model"
+	"github.com/lavatee/subs/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	webhookMaxAttempts   = 8
+	webhookRetryBase     = 30 * time.Second
+	webhookRetryMax      = 6 * time.Hour
+	webhookTimeout       = 10 * time.Second
+	webhookBatchSize     = 50
+	webhookLease         = time.Minute
+	webhookDeliveryLimit = 100
+
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+)
+
+type WebhooksService struct {
+	repo   *repository.Repository
+	logger *logrus.Logger
+	client *http.Client
+	// allowPrivate lets webhooks target loopback and private addresses,
+	// which is only safe where the API is not exposed, e.g. in development.
+	allowPrivate bool
+}
+
+func NewWebhooksService(repo *repository.Repository, logger *logrus.Logger, allowPrivate bool) *WebhooksService {
+	client := &http.Client{Timeout: webhookTimeout}
+	if !allowPrivate {
+		client.Transport = publicOnlyTransport()
+	}
+	return &WebhooksService{
+		repo:         repo,
+		logger:       logger,
+		client:       client,
+		allowPrivate: allowPrivate,
+	}
+}
+
+func (s *WebhooksService) CreateWebhook(ctx context.Context, req model.CreateWebhookRequest) (model.Webhook, error) {
+	if err := checkWebhookURL(req.URL, s.allowPrivate); err != nil {
+		return model.Webhook{}, err
+	}
+	for _, event := range req.Events {
+		if !slices.Contains(model.WebhookEvents, event) {
+			return model.Webhook{}, fmt.Errorf("%w: unknown event %q", model.ErrInvalidInput, event)
+		}
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return model.Webhook{}, err
+	}
+
+	hook := model.Webhook{
+		ID:        uuid.New(),
+		URL:       req.URL,
+		Events:    req.Events,
+		Secret:    hex.EncodeToString(secret),
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.repo.Webhooks.CreateWebhook(ctx, hook); err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to create webhook in repository: %v", err)
+		return model.Webhook{}, err
+	}
+
+	return hook, nil
+}
+
+func (s *WebhooksService) GetWebhooks(ctx context.Context) ([]model.Webhook, error) {
+	hooks, err := s.repo.Webhooks.GetWebhooks(ctx)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to get webhooks from repository: %v", err)
+		return nil, err
+	}
+	for i := range hooks {
+		hooks[i].Secret = ""
+	}
+	return hooks, nil
+}
+
+func (s *WebhooksService) GetWebhook(ctx context.Context, id uuid.UUID) (model.Webhook, error) {
+	hook, err := s.repo.Webhooks.GetWebhook(ctx, id)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to get webhook from repository: %v", err)
+		return model.Webhook{}, err
+	}
+	hook.Secret = ""
+	return hook, nil
+}
+
+func (s *WebhooksService) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
+	if err := s.repo.Webhooks.DeleteWebhook(ctx, id); err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to delete webhook from repository: %v", err)
+		return err
+	}
+	return nil
+}
+
+func (s *WebhooksService) GetDeliveries(ctx context.Context, webhookID uuid.UUID) ([]model.WebhookDelivery, error) {
+	if _, err := s.repo.Webhooks.GetWebhook(ctx, webhookID); err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to get webhook from repository: %v", err)
+		return nil, err
+	}
+	deliveries, err := s.repo.Webhooks.GetDeliveries(ctx, webhookID, webhookDeliveryLimit)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to get webhook deliveries from repository: %v", err)
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (s *WebhooksService) Redeliver(ctx context.Context, webhookID, deliveryID uuid.UUID) (model.WebhookDelivery, error) {
+	delivery, err := s.repo.Webhooks.RedeliverDelivery(ctx, webhookID, deliveryID)
+	if err != nil {
+		logging.FromContext(ctx, s.logger).Errorf("Failed to schedule webhook redelivery: %v", err)
+		return model.WebhookDelivery{}, err
+	}
+	return delivery, nil
+}
+
+// RunDispatcher sends due deliveries every interval until ctx is cancelled.
+func (s *WebhooksService) RunDispatcher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.dispatchDue(ctx)
+		}
+	}
+}
+
+func (s *WebhooksService) dispatchDue(ctx context.Context) {
+	for {
+		deliveries, err := s.repo.Webhooks.ClaimDueDeliveries(ctx, webhookBatchSize, webhookLease)
+		if err != nil {
+			logging.FromContext(ctx, s.logger).Errorf("Failed to claim webhook deliveries: %v", err)
+			return
+		}
+
+		var wg sync.WaitGroup
+		for _, delivery := range deliveries {
+			wg.Add(1)
+			go func(delivery model.PendingDelivery) {
+				defer wg.Done()
+				attempt := s.deliver(ctx, delivery)
+				if err := s.repo.Webhooks.RecordDeliveryAttempt(ctx, attempt); err != nil {
+					logging.FromContext(ctx, s.logger).Errorf("Failed to record webhook delivery %s: %v", delivery.ID, err)
+				}
+			}(delivery)
+		}
+		wg.Wait()
+
+		if len(deliveries) < webhookBatchSize || ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// deliver sends one delivery and returns it updated with the outcome.
+func (s *WebhooksService) deliver(ctx context.Context, delivery model.PendingDelivery) model.WebhookDelivery {
+	attempt := delivery.WebhookDelivery
+	attempt.Attempts++
+
+	status, err := s.send(ctx, delivery)
+	if status != 0 {
+		attempt.ResponseStatus = &status
+	}
+	now := time.Now().UTC()
+	if err == nil {
+		attempt.Status = model.DeliveryDelivered
+		attempt.LastError = nil
+		attempt.DeliveredAt = &now
+		logging.FromContext(ctx, s.logger).Infof("Webhook delivery %s (%s) to %s succeeded with status %d", delivery.ID, delivery.Event, delivery.URL, status)
+		return attempt
+	}
+
+	message := err.Error()
+	attempt.LastError = &message
+	if attempt.Attempts >= webhookMaxAttempts {
+		attempt.Status = model.DeliveryFailed
+		logging.FromContext(ctx, s.logger).Warnf("Webhook delivery %s (%s) to %s failed permanently after %d attempts: %v", delivery.ID, delivery.Event, delivery.URL, attempt.Attempts, err)
+		return attempt
+	}
+	attempt.NextAttemptAt = now.Add(webhookBackoff(attempt.Attempts))
+	logging.FromContext(ctx, s.logger).Warnf("Webhook delivery %s (%s) to %s failed, attempt %d, retrying at %s: %v", delivery.ID, delivery.Event, delivery.URL, attempt.Attempts, attempt.NextAttemptAt.Format(time.RFC3339), err)
+	return attempt
+}
+
+func (s *WebhooksService) send(ctx context.Context, delivery model.PendingDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, delivery.Event)
+	req.Header.Set(WebhookDeliveryHeader, delivery.ID.String())
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(delivery.Secret, time.Now(), delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// checkWebhookURL accepts absolute http and https URLs. Unless allowPrivate
+// is set, hosts given as IP addresses must be public; host names are checked
+// when the dispatcher dials them, since they may resolve differently later.
+func checkWebhookURL(raw string, allowPrivate bool) error {
+	target, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("%w: invalid webhook URL: %v", model.ErrInvalidInput, err)
+	}
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return fmt.Errorf("%w: webhook URL must be http or https", model.ErrInvalidInput)
+	}
+	if target.Hostname() == "" {
+		return fmt.Errorf("%w: webhook URL must have a host", model.ErrInvalidInput)
+	}
+	if target.User != nil {
+		return fmt.Errorf("%w: webhook URL must not carry credentials", model.ErrInvalidInput)
+	}
+	if allowPrivate {
+		return nil
+	}
+	if strings.EqualFold(target.Hostname(), "localhost") {
+		return fmt.Errorf("%w: webhook URL must not point to localhost", model.ErrInvalidInput)
+	}
+	if ip := net.ParseIP(target.Hostname()); ip != nil && !isPublicIP(ip) {
+		return fmt.Errorf("%w: webhook URL must not point to a private address", model.ErrInvalidInput)
+	}
+	return nil
+}
+
+// publicOnlyTransport refuses connections to non-public addresses, so that
+// webhooks cannot reach internal services through names resolving to them
+// or through redirects. Proxies are not used, as they would be dialed instead.
+func publicOnlyTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout: webhookTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return fmt.Errorf("webhook address %s is not public", host)
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport
+}
+
+func isPublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsUnspecified() &&
+		!ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsMulticast()
+}
+
+// SignWebhookPayload returns the signature header value for a delivery:
+// "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<payload>">". Receivers
+// recompute the HMAC with the webhook secret and reject stale timestamps.
+func SignWebhookPayload(secret string, at time.Time, payload []byte) string {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func webhookBackoff(attempts int) time.Duration {
+	delay := webhookRetryBase << (attempts - 1)
+	if delay > webhookRetryMax || delay <= 0 {
+		return webhookRetryMax
+	}
+	return delay
+}
+
+// RunLifecycleScanner emits subscription.ending_soon for subscriptions ending
+// within lead and subscription.ended for those that have ended, every
+// interval until ctx is cancelled. Each event is sent once per subscription
+// and end date.
+func (s *WebhooksService) RunLifecycleScanner(ctx context.Context, interval, lead time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		s.scanLifecycle(ctx, lead)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// scanLifecycle emits every event due since the previous scan, so nothing is
+// lost while the scanner is down. The first scan starts from now rather than
+// replaying history.
+func (s *WebhooksService) scanLifecycle(ctx context.Context, lead time.Duration) {
+	now := time.Now().UTC()
+	windows := []struct {
+		event string
+		to    time.Time
+	}{
+		{event: model.EventSubscriptionEndingSoon, to: now.Add(lead)},
+		{event: model.EventSubscriptionEnded, to: now},
+	}
+	for _, window := range windows {
+		err := s.repo.WithinTx(ctx, func(repos *repository.Repository) error {
+			from, err := repos.Webhooks.GetScannedUntil(ctx, window.event)
+			if err != nil {
+				return err
+			}
+			if from.IsZero() {
+				from = now
+			}
+			if !from.Before(window.to) {
+				return nil
+			}
+			subs, err := endingBetween(ctx, repos, from, window.to)
+			if err != nil {
+				return err
+			}
+			for _, sub := range subs {
+				dedupKey := fmt.Sprintf("%s:%s:%d", window.event, sub.ID, sub.EndDate.Unix())
+				if err := enqueueWebhooks(ctx, repos, model.NewSubscriptionEvent(window.event, sub), dedupKey); err != nil {
+					return fmt.Errorf("subscription %s: %w", sub.ID, err)
+				}
+			}
+			return repos.Webhooks.SetScannedUntil(ctx, window.event, window.to)
+		})
+		if err != nil {
+			logging.FromContext(ctx, s.logger).Errorf("Failed to scan %s webhooks: %v", window.event, err)
+		}
+	}
+}
+
+// endingBetween returns the subscriptions that end in [from, to). The end
+// date is the last paid month, so a subscription ends when the month after
+// it begins.
+func endingBetween(ctx context.Context, repos *repository.Repository, from, to time.Time) ([]model.Subscription, error) {
+	candidates, err := repos.Subscriptions.GetEndingBetween(ctx, monthStart(from).AddDate(0, -1, 0), to)
+	if err != nil {
+		return nil, err
+	}
+	var subs []model.Subscription
+	for _, sub := range candidates {
+		if ends := sub.EndDate.AddDate(0, 1, 0); !ends.Before(from) && ends.Before(to) {
+			subs = append(subs, sub)
+		}
+	}
+	return subs, nil
+}
+
+// enqueueWebhooks schedules event for every interested webhook. Called with
+// transactional repositories, deliveries are committed together with the
+// change that caused them.
+func enqueueWebhooks(ctx context.Context, repos *repository.Repository, event model.Event, dedupKey string) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = repos.Webhooks.EnqueueDeliveries(ctx, event.Type, payload, dedupKey)
+	return err
+}
@@ -0,0 +1,38 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestInitRejectsUnknownExporter(t *testing.T) {
+	if _, err := Init(context.Background(), Config{Exporter: "jaeger"}); err == nil {
+		t.Fatal("expected an error for an unknown exporter")
+	}
+}
+
+func TestInitPropagatesTraceContext(t *testing.T) {
+	shutdown, err := Init(context.Background(), Config{Exporter: ExporterStdout, SampleRatio: 1})
+	if err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	defer shutdown(context.Background())
+
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), propagation.HeaderCarrier(header))
+	ctx, span := otel.Tracer("test").Start(ctx, "child")
+	defer span.End()
+
+	if got := trace.SpanContextFromContext(ctx).TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("trace id: got %s", got)
+	}
+	if !span.SpanContext().IsSampled() {
+		t.Fatal("a sampled parent must keep the child sampled")
+	}
+}
@@ -0,0 +1,7 @@
+-- One calendar feed token per user. Only its SHA-256 is stored, so a leaked
+-- database does not expose working feed URLs.
+CREATE TABLE calendar_tokens (
+    user_id UUID PRIMARY KEY,
+    token_hash TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,3 @@
+ALTER TABLE subscriptions_archive DROP CONSTRAINT subscriptions_archive_end_after_start;
+
+ALTER TABLE subscriptions DROP CONSTRAINT subscriptions_end_after_start;
@@ -0,0 +1,12 @@
+-- Rows ending before they start would subtract their price from the
+-- monthly_spend ledger. Existing ones are taken to be single-month
+-- subscriptions; the trigger moves their ledger entries along.
+UPDATE subscriptions SET end_date = start_date WHERE end_date < start_date;
+
+UPDATE subscriptions_archive SET end_date = start_date WHERE end_date < start_date;
+
+ALTER TABLE subscriptions
+ADD CONSTRAINT subscriptions_end_after_start CHECK (end_date IS NULL OR end_date >= start_date);
+
+ALTER TABLE subscriptions_archive
+ADD CONSTRAINT subscriptions_archive_end_after_start CHECK (end_date >= start_date);
@@ -0,0 +1,10 @@
+-- Deliveries are scheduled from Go in UTC and claimed against now(), which
+-- only agree for TIMESTAMP columns on a database running in UTC. Existing
+-- values are taken to be UTC.
+ALTER TABLE webhooks
+ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE 'UTC';
+
+ALTER TABLE webhook_deliveries
+ALTER COLUMN next_attempt_at TYPE TIMESTAMPTZ USING next_attempt_at AT TIME ZONE 'UTC',
+ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE 'UTC',
+ALTER COLUMN delivered_at TYPE TIMESTAMPTZ USING delivered_at AT TIME ZONE 'UTC';
@@ -0,0 +1,6 @@
+-- webhook_scans records up to when the lifecycle scanner has emitted each
+-- scheduled event, so that a scanner that was down catches up on restart.
+CREATE TABLE webhook_scans (
+    event TEXT PRIMARY KEY,
+    scanned_until TIMESTAMPTZ NOT NULL
+);
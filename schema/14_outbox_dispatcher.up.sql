@@ -0,0 +1,8 @@
+-- outbox_dispatcher holds the lease of the one instance dispatching the
+-- outbox. Sinks are called outside any transaction, so the lease, not a
+-- transaction-scoped lock, keeps other instances out meanwhile.
+CREATE TABLE outbox_dispatcher (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    holder UUID NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,18 @@
+-- outbox_trimmed holds the position of the last event trimmed from the
+-- outbox, so that readers resuming from before it know they missed events.
+CREATE TABLE outbox_trimmed (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    txid XID8 NOT NULL,
+    sequence BIGINT NOT NULL
+);
+
+-- Events trimmed so far are unknown; count everything before the oldest
+-- remaining event, or before now if none remain, as trimmed.
+INSERT INTO outbox_trimmed (txid, sequence)
+SELECT txid, sequence FROM (
+    SELECT txid, id - 1 AS sequence FROM outbox
+    UNION ALL
+    SELECT pg_current_xact_id(), 0
+) AS positions
+ORDER BY txid, sequence
+LIMIT 1;
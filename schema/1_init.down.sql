@@ -1 +1 @@
-DROP TABLE subscriptions;
\ No newline at end of file
+DROP TABLE subscriptions;
@@ -0,0 +1,7 @@
+DROP TRIGGER subscriptions_monthly_spend ON subscriptions;
+
+DROP FUNCTION monthly_spend_sync();
+
+DROP FUNCTION monthly_spend_apply(UUID, TEXT, TIMESTAMP, TIMESTAMP, BIGINT);
+
+DROP TABLE monthly_spend;
@@ -0,0 +1,60 @@
+-- monthly_spend is a ledger of subscription prices bucketed by month:
+-- started_amount sums the prices of subscriptions starting in that month,
+-- ended_amount the prices of subscriptions whose end_date falls in it.
+-- Running sums over it answer total and per-month spend queries without
+-- scanning subscriptions.
+CREATE TABLE monthly_spend (
+    user_id UUID NOT NULL,
+    service_name TEXT NOT NULL,
+    month TIMESTAMP NOT NULL,
+    started_amount BIGINT NOT NULL DEFAULT 0,
+    ended_amount BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (user_id, service_name, month)
+);
+
+CREATE INDEX idx_monthly_spend_service_month ON monthly_spend(service_name, month);
+
+CREATE FUNCTION monthly_spend_apply(p_user_id UUID, p_service_name TEXT, p_start_date TIMESTAMP, p_end_date TIMESTAMP, p_price BIGINT)
+RETURNS void AS $$
+BEGIN
+    INSERT INTO monthly_spend (user_id, service_name, month, started_amount)
+    VALUES (p_user_id, p_service_name, date_trunc('month', p_start_date), p_price)
+    ON CONFLICT (user_id, service_name, month)
+    DO UPDATE SET started_amount = monthly_spend.started_amount + EXCLUDED.started_amount;
+
+    IF p_end_date IS NOT NULL THEN
+        INSERT INTO monthly_spend (user_id, service_name, month, ended_amount)
+        VALUES (p_user_id, p_service_name, date_trunc('month', p_end_date), p_price)
+        ON CONFLICT (user_id, service_name, month)
+        DO UPDATE SET ended_amount = monthly_spend.ended_amount + EXCLUDED.ended_amount;
+    END IF;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION monthly_spend_sync() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        PERFORM monthly_spend_apply(OLD.user_id, OLD.service_name, OLD.start_date, OLD.end_date, -OLD.price);
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        PERFORM monthly_spend_apply(NEW.user_id, NEW.service_name, NEW.start_date, NEW.end_date, NEW.price);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER subscriptions_monthly_spend
+AFTER INSERT OR UPDATE OR DELETE ON subscriptions
+FOR EACH ROW EXECUTE FUNCTION monthly_spend_sync();
+
+INSERT INTO monthly_spend (user_id, service_name, month, started_amount, ended_amount)
+SELECT user_id, service_name, month, SUM(started_amount), SUM(ended_amount)
+FROM (
+    SELECT user_id, service_name, date_trunc('month', start_date) AS month, price AS started_amount, 0 AS ended_amount
+    FROM subscriptions
+    UNION ALL
+    SELECT user_id, service_name, date_trunc('month', end_date), 0, price
+    FROM subscriptions
+    WHERE end_date IS NOT NULL
+) AS ledger
+GROUP BY user_id, service_name, month;
@@ -0,0 +1,8 @@
+INSERT INTO subscriptions (id, service_name, price, user_id, start_date, end_date, created_at)
+SELECT id, service_name, price, user_id, start_date, end_date, created_at
+FROM subscriptions_archive
+ON CONFLICT (id) DO NOTHING;
+
+DROP INDEX idx_subscriptions_end_date;
+
+DROP TABLE subscriptions_archive;
@@ -0,0 +1,18 @@
+CREATE TABLE subscriptions_archive (
+    id UUID PRIMARY KEY,
+    service_name TEXT NOT NULL,
+    price INTEGER NOT NULL CHECK (price > 0),
+    user_id UUID NOT NULL,
+    start_date TIMESTAMP NOT NULL,
+    end_date TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    archived_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_subscriptions_archive_user_id ON subscriptions_archive(user_id);
+
+CREATE INDEX idx_subscriptions_archive_service_name ON subscriptions_archive(service_name);
+
+CREATE INDEX idx_subscriptions_archive_dates ON subscriptions_archive(start_date, end_date);
+
+CREATE INDEX idx_subscriptions_end_date ON subscriptions(end_date) WHERE end_date IS NOT NULL;
@@ -0,0 +1,16 @@
+CREATE TABLE outbox (
+    id BIGSERIAL PRIMARY KEY,
+    event_id UUID NOT NULL UNIQUE,
+    aggregate_id UUID NOT NULL,
+    event_type TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    -- Writing transaction. Readers only consume rows older than every
+    -- transaction still in flight, so ids are never observed out of order.
+    txid XID8 NOT NULL DEFAULT pg_current_xact_id(),
+    dispatched_at TIMESTAMP
+);
+
+CREATE INDEX idx_outbox_pending ON outbox(id) WHERE dispatched_at IS NULL;
+
+CREATE INDEX idx_outbox_created_at ON outbox(created_at);
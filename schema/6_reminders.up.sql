@@ -0,0 +1,17 @@
+CREATE TABLE user_contacts (
+    user_id UUID PRIMARY KEY,
+    email TEXT NOT NULL DEFAULT '',
+    webhook_url TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- One row per reminder sent, so that each fires once per channel.
+CREATE TABLE reminders_sent (
+    subscription_id UUID NOT NULL,
+    kind TEXT NOT NULL,
+    due_date DATE NOT NULL,
+    lead_days INTEGER NOT NULL,
+    channel TEXT NOT NULL,
+    sent_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (subscription_id, kind, due_date, lead_days, channel)
+);
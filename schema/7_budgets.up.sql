@@ -0,0 +1,11 @@
+CREATE TABLE budgets (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    -- Empty when the budget covers all of the user's services.
+    service_name TEXT NOT NULL DEFAULT '',
+    period TEXT NOT NULL CHECK (period IN ('monthly', 'yearly')),
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_budgets_user_id ON budgets(user_id);
@@ -0,0 +1,5 @@
+-- Readers that follow the outbox with a cursor order events by (txid, id).
+-- Ids alone are assigned at insert time and can commit out of order, but no
+-- event can appear before the last one read once its transaction is older
+-- than every transaction still in flight.
+CREATE INDEX idx_outbox_position ON outbox(txid, id);
@@ -0,0 +1,18 @@
+CREATE TABLE subscription_proposals (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    -- Normalized merchant the charges were grouped by; a statement uploaded
+    -- again refreshes the proposal instead of adding a new one.
+    merchant TEXT NOT NULL,
+    service_name TEXT NOT NULL,
+    price INTEGER NOT NULL CHECK (price > 0),
+    start_date DATE NOT NULL,
+    end_date DATE,
+    last_charge_date DATE NOT NULL,
+    charges INTEGER NOT NULL,
+    confidence DOUBLE PRECISION NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'dismissed')),
+    subscription_id UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, merchant, price)
+);
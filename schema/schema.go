@@ -0,0 +1,11 @@
+// Package schema embeds the database migrations, so that the binary carries
+// them wherever it runs.
+package schema
+
+import "embed"
+
+// FS holds the golang-migrate files: <version>_<name>.up.sql and
+// <version>_<name>.down.sql.
+//
+//go:embed *.sql
+var FS embed.FS
@@ -1,6 +1,8 @@
 package subs
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -10,16 +12,30 @@ type Server struct {
 	httpServer *http.Server
 }
 
-func (s *Server) Run(port string, handler http.Handler) error {
-	s.httpServer = &http.Server{
-		Addr:         fmt.Sprintf(":%s", port),
-		Handler:      handler,
-		ReadTimeout:  10 * time.Second,
-		WriteTimeout: 10 * time.Second,
+// NewServer builds a server for handler on port. It is built before Run so
+// that Shutdown may be called from another goroutine at any time.
+func NewServer(port string, handler http.Handler) *Server {
+	return &Server{
+		httpServer: &http.Server{
+			Addr:         fmt.Sprintf(":%s", port),
+			Handler:      handler,
+			ReadTimeout:  10 * time.Second,
+			WriteTimeout: 10 * time.Second,
+		},
 	}
+}
+
+// Run serves until Shutdown, after which it returns http.ErrServerClosed,
+// also when Shutdown came first.
+func (s *Server) Run() error {
 	return s.httpServer.ListenAndServe()
 }
 
-func (s *Server) Shutdown() error {
-	return s.httpServer.Close()
+// Shutdown stops accepting connections and waits for in-flight requests to
+// finish. When ctx is done first, the remaining connections are closed.
+func (s *Server) Shutdown(ctx context.Context) error {
+	if err := s.httpServer.Shutdown(ctx); err != nil {
+		return errors.Join(err, s.httpServer.Close())
+	}
+	return nil
 }
@@ -0,0 +1,18 @@
+package subs
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestShutdownBeforeRun(t *testing.T) {
+	server := NewServer("0", http.NotFoundHandler())
+	if err := server.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if err := server.Run(); !errors.Is(err, http.ErrServerClosed) {
+		t.Fatalf("Run after Shutdown: want http.ErrServerClosed, got %v", err)
+	}
+}